@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/catalog"
+)
+
+const catalogUsage = `Usage:
+  stackup catalog list
+  stackup catalog show <recipe>
+
+Recipes are read from the directories in ` + catalog.PathEnv + ` and the user's
+stackup/catalog config directory before the built-in catalog.`
+
+// runCatalog dispatches the `stackup catalog` subcommands
+func runCatalog(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("subcommand required\n%s", catalogUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		if len(args) != 1 {
+			return fmt.Errorf("unexpected arguments: %v\n%s", args[1:], catalogUsage)
+		}
+		return runCatalogList()
+	case "show":
+		if len(args) != 2 {
+			return fmt.Errorf("exactly one recipe name required\n%s", catalogUsage)
+		}
+		return runCatalogShow(args[1])
+	default:
+		return fmt.Errorf("unknown catalog subcommand: %s\n%s", args[0], catalogUsage)
+	}
+}
+
+// runCatalogList prints every recipe with where it was read from
+func runCatalogList() error {
+	entries, err := catalog.Default().List()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Catalog %s\n\n", catalog.Version)
+	for _, entry := range entries {
+		source := ""
+		if !strings.HasPrefix(entry.File, "catalog@") {
+			source = " (" + entry.File + ")"
+		}
+		fmt.Printf("  %-12s %-8s %s%s\n", entry.Name, entry.Version, entry.Description, source)
+	}
+	fmt.Println("\nUse a recipe in a config with `- use: <name>`; fields set next to it override the recipe.")
+	return nil
+}
+
+// runCatalogShow prints a recipe as written, after checking it
+func runCatalogShow(name string) error {
+	c := catalog.Default()
+	if _, err := c.Tool(name); err != nil {
+		return err
+	}
+
+	data, file, err := c.Recipe(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("# %s\n", file)
+	_, err = os.Stdout.Write(data)
+	return err
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+const configUsage = `Usage:
+  stackup config render [--format yaml|json|toml] [--to yaml|json|toml] -f <config>...
+  stackup config convert [--format yaml|json|toml] --to yaml|json|toml [-o <output>] <config>`
+
+// runConfig dispatches the `stackup config` subcommands
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("subcommand required\n%s", configUsage)
+	}
+
+	switch args[0] {
+	case "render":
+		return runConfigRender(args[1:])
+	case "convert":
+		return runConfigConvert(args[1:])
+	default:
+		return fmt.Errorf("unknown config subcommand: %s\n%s", args[0], configUsage)
+	}
+}
+
+// runConfigRender prints the configuration after includes and overlays are merged
+func runConfigRender(args []string) error {
+	flags := flag.NewFlagSet("config render", flag.ContinueOnError)
+	var files stringList
+	flags.Var(&files, "f", "config file to load; repeat to merge several files in order")
+	formatName := flags.String("format", "", "format of the config files: yaml, json or toml (default: by extension)")
+	toName := flags.String("to", "yaml", "output format: yaml, json or toml")
+	pin := flags.String("sha256", "", "expected sha256 of the config file")
+	vars := varMap{}
+	flags.Var(vars, "var", "set a config variable as key=value; repeatable")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := config.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	to, err := config.ParseFormat(*toName)
+	if err != nil {
+		return err
+	}
+
+	configPaths := append(files, flags.Args()...)
+	if len(configPaths) == 0 {
+		return fmt.Errorf("config file required\n%s", configUsage)
+	}
+
+	cfg, err := config.Load(remoteLoadOptions(format, *pin, vars), configPaths...)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return config.RenderAs(os.Stdout, cfg, to)
+}
+
+// runConfigConvert translates a single config file to another format,
+// keeping includes and templates as written
+func runConfigConvert(args []string) error {
+	flags := flag.NewFlagSet("config convert", flag.ContinueOnError)
+	formatName := flags.String("format", "", "format of the input file: yaml, json or toml (default: by extension)")
+	toName := flags.String("to", "", "output format: yaml, json or toml (default: by -o extension)")
+	output := flags.String("o", "", "file to write instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("exactly one config file required\n%s", configUsage)
+	}
+	path := flags.Arg(0)
+
+	from, err := config.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	to, err := config.ParseFormat(*toName)
+	if err != nil {
+		return err
+	}
+	if to == config.FormatAuto {
+		if *output == "" {
+			return fmt.Errorf("output format required: pass --to or -o\n%s", configUsage)
+		}
+		to = config.FormatFromPath(*output)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := config.Convert(&buf, data, path, from, to); err != nil {
+		return fmt.Errorf("failed to convert config: %w", err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*output, buf.Bytes(), 0644)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/export"
+)
+
+const exportUsage = `Usage:
+  stackup export --format sh|dockerfile|ansible|devcontainer [--os linux|macos|windows] [--distro ubuntu:24.04] [--arch amd64] [-o <output>] <config> | -f <a> [-f <b>...]
+
+The commands are those stackup install would run on the target platform,
+not on this machine. dockerfile and devcontainer need a linux target, sh
+a linux or macos one.`
+
+// runExport writes the install plan of a config for a target platform as
+// a script, Dockerfile, playbook or dev container
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	var files stringList
+	flags.Var(&files, "f", "config file to load; repeat to merge several files in order")
+	formatName := flags.String("format", "", "output format: sh, dockerfile, ansible or devcontainer")
+	configFormat := flags.String("config-format", "", "format of the config files: yaml, json or toml (default: by extension)")
+	osName := flags.String("os", "linux", "target OS: linux, macos or windows")
+	distro := flags.String("distro", "", "target Linux distribution as id or id:version (default: ubuntu)")
+	arch := flags.String("arch", "amd64", "target architecture")
+	image := flags.String("image", "", "container base image (default: the distribution's official image)")
+	output := flags.String("o", "-", "file to write, or - for stdout")
+	force := flags.Bool("force", false, "overwrite an existing file")
+	pin := flags.String("sha256", "", "expected sha256 of the config file")
+	vars := varMap{}
+	flags.Var(vars, "var", "set a config variable as key=value; repeatable")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *formatName == "" {
+		return fmt.Errorf("--format required\n%s", exportUsage)
+	}
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	inputFormat, err := config.ParseFormat(*configFormat)
+	if err != nil {
+		return err
+	}
+
+	configPaths := append(files, flags.Args()...)
+	if len(configPaths) == 0 {
+		return fmt.Errorf("config file required\n%s", exportUsage)
+	}
+
+	target, err := export.NewTarget(*osName, *distro, *arch)
+	if err != nil {
+		return err
+	}
+	if *image != "" {
+		target.Image = *image
+	}
+
+	if *output != "-" && !*force {
+		if _, err := os.Stat(*output); err == nil {
+			return fmt.Errorf("%s already exists; pass --force to overwrite it", *output)
+		}
+	}
+
+	cfg, err := config.Load(remoteLoadOptions(inputFormat, *pin, vars), configPaths...)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := config.Validate(cfg); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	plan, err := export.Build(cfg, target)
+	if err != nil {
+		return fmt.Errorf("cannot export for %s:\n%w", target, err)
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, plan, format); err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	mode := os.FileMode(0644)
+	if format == export.FormatShell {
+		mode = 0755
+	}
+	return os.WriteFile(*output, buf.Bytes(), mode)
+}
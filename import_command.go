@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/araldhafeeri/stackup/internal/catalog"
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/importer"
+)
+
+const importUsage = `Usage:
+  stackup import [--from brewfile|winget|choco|apt|tool-versions] [--profile dev] [-o stackup.yaml] [--force] <manifest>...
+
+The format of each manifest is taken from its name (Brewfile, *.json,
+packages.config, .tool-versions) unless --from is given. apt lists, as
+written by apt-mark showmanual, always need --from apt.`
+
+// runImport converts package manifests of other tools into one config
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	from := flags.String("from", "", "format of the manifests (default: by file name)")
+	profile := flags.String("profile", "dev", "profile name")
+	output := flags.String("o", "stackup.yaml", "file to write, or - for stdout; .json and .toml convert the config")
+	force := flags.Bool("force", false, "overwrite an existing file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("manifest required\n%s", importUsage)
+	}
+
+	if *output != "-" && !*force {
+		if _, err := os.Stat(*output); err == nil {
+			return fmt.Errorf("%s already exists; pass --force to overwrite it", *output)
+		}
+	}
+
+	var pkgs []importer.Package
+	var notes []string
+	for _, path := range flags.Args() {
+		kind, err := importer.DetectKind(path)
+		if *from != "" {
+			kind, err = importer.ParseKind(*from)
+		}
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		filePkgs, fileNotes, err := importer.Parse(kind, data, path)
+		if err != nil {
+			return err
+		}
+		pkgs = append(pkgs, filePkgs...)
+		notes = append(notes, fileNotes...)
+	}
+
+	c := catalog.Default()
+	result, err := importer.Import(c, *profile, flags.Args(), pkgs, notes)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := result.Write(&buf, c); err != nil {
+		return err
+	}
+
+	out := buf.Bytes()
+	if format := config.FormatFromPath(*output); *output != "-" && format != config.FormatYAML {
+		var converted bytes.Buffer
+		if err := config.Convert(&converted, out, *output, config.FormatYAML, format); err != nil {
+			return fmt.Errorf("failed to convert config: %w", err)
+		}
+		out = converted.Bytes()
+	}
+
+	if *output == "-" {
+		_, err = os.Stdout.Write(out)
+	} else {
+		err = os.WriteFile(*output, out, 0644)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Imported %d tools, %d from the catalog; %d TODO items to review\n", result.Tools, result.Recipes, result.TODO)
+	return nil
+}
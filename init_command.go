@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/araldhafeeri/stackup/internal/wizard"
+)
+
+const initUsage = `Usage:
+  stackup init [-o stackup.yaml] [--force]
+  stackup init --non-interactive --tools git,node [--platforms linux,macos] [--profile dev] [--verify=false] [--update-path=false]`
+
+// runInit generates a starter config from the built-in catalog. Anything not
+// given as a flag is asked for, unless --non-interactive is set, in which
+// case the defaults are used.
+func runInit(args []string) error {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	tools := flags.String("tools", "", "comma-separated catalog tools to include")
+	platforms := flags.String("platforms", "", "comma-separated platforms to target: linux, macos, windows (default: all)")
+	profile := flags.String("profile", "", "profile name (default: dev)")
+	verify := flags.Bool("verify", true, "verify tools after installing")
+	updatePath := flags.Bool("update-path", true, "add installed tools to PATH")
+	nonInteractive := flags.Bool("non-interactive", false, "do not prompt; use defaults for anything not given")
+	output := flags.String("o", "stackup.yaml", "file to write, or - for stdout")
+	force := flags.Bool("force", false, "overwrite an existing file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v\n%s", flags.Args(), initUsage)
+	}
+
+	if *output != "-" && !*force {
+		if _, err := os.Stat(*output); err == nil {
+			return fmt.Errorf("%s already exists; pass --force to overwrite it", *output)
+		}
+	}
+
+	opts := wizard.Options{Profile: *profile}
+	var err error
+	if *tools != "" {
+		if opts.Tools, err = wizard.ParseTools(*tools); err != nil {
+			return err
+		}
+	}
+	if *platforms != "" {
+		if opts.Platforms, err = wizard.ParsePlatforms(*platforms); err != nil {
+			return err
+		}
+	}
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "verify":
+			opts.Verify = verify
+		case "update-path":
+			opts.UpdatePath = updatePath
+		}
+	})
+
+	if *nonInteractive {
+		if len(opts.Tools) == 0 {
+			return fmt.Errorf("--tools is required with --non-interactive\n%s", initUsage)
+		}
+	} else {
+		// Questions go to stderr so the config can be written to stdout
+		if opts, err = wizard.Ask(os.Stdin, os.Stderr, opts); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := wizard.Build(opts)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := wizard.Write(&buf, cfg); err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s with %d tools. Run `stackup install %s` to set them up.\n", *output, len(cfg.Tools), *output)
+	return nil
+}
@@ -0,0 +1,43 @@
+package backend
+
+import "strings"
+
+// apk manages Alpine Linux packages. apk never prompts, so it has no
+// non-interactive flags.
+type apk struct{ base }
+
+func newAPK(r Runner, opts Options) Backend { return &apk{base{r, opts}} }
+
+func (b *apk) Name() string { return "apk" }
+
+func (b *apk) Detect(osName string) bool {
+	return osName == "linux" && b.runner.LookPath("apk")
+}
+
+func (b *apk) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion reads apk list, which prints name-version-release
+// followed by the architecture
+func (b *apk) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("apk", "list", "--installed", pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	version, ok := strings.CutPrefix(field(out, 0), pkg.Name+"-")
+	if !ok {
+		return "", ErrNotInstalled
+	}
+	return version, nil
+}
+
+func (b *apk) Install(pkg Package) error { return b.sudo("apk", "add", pkg.Name) }
+
+func (b *apk) Upgrade(pkg Package) error { return b.sudo("apk", "upgrade", pkg.Name) }
+
+func (b *apk) Uninstall(pkg Package) error { return b.sudo("apk", "del", pkg.Name) }
+
+func (b *apk) RefreshIndex() error { return b.sudo("apk", "update") }
+
+// AddRepository is not supported: repositories are lines of
+// /etc/apk/repositories
+func (b *apk) AddRepository(Repository) error { return ErrUnsupported }
@@ -0,0 +1,127 @@
+package backend
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// apt manages Debian and Ubuntu packages
+type apt struct{ base }
+
+func newAPT(r Runner, opts Options) Backend { return &apt{base{r, opts}} }
+
+func (b *apt) Name() string { return "apt" }
+
+func (b *apt) Detect(osName string) bool {
+	return osName == "linux" && b.runner.LookPath("apt-get")
+}
+
+func (b *apt) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+func (b *apt) InstalledVersion(pkg Package) (string, error) {
+	// Removed packages keep a status entry, so check it is installed
+	out, err := b.query("dpkg-query", "-W", "-f=${Status}\t${Version}", pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	status, version, _ := strings.Cut(out, "\t")
+	if !strings.HasSuffix(status, " installed") {
+		return "", ErrNotInstalled
+	}
+	return strings.TrimSpace(version), nil
+}
+
+func (b *apt) Install(pkg Package) error { return b.InstallAll([]Package{pkg}) }
+
+func (b *apt) InstallAll(pkgs []Package) error {
+	return b.aptGet(args([]string{"install"}, b.unless("-y"), names(pkgs))...)
+}
+
+func (b *apt) Upgrade(pkg Package) error {
+	return b.aptGet(args([]string{"install", "--only-upgrade"}, b.unless("-y"), []string{pkg.Name})...)
+}
+
+func (b *apt) Uninstall(pkg Package) error {
+	return b.aptGet(args([]string{"remove"}, b.unless("-y"), []string{pkg.Name})...)
+}
+
+func (b *apt) RefreshIndex() error { return b.aptGet("update") }
+
+// aptGet runs apt-get as root. Unattended, debconf is told not to ask
+// either, or package configuration questions would wait for an answer.
+func (b *apt) aptGet(args ...string) error {
+	if b.opts.Interactive {
+		return b.sudo("apt-get", args...)
+	}
+	return b.sudo("env", append([]string{"DEBIAN_FRONTEND=noninteractive", "apt-get"}, args...)...)
+}
+
+// Where apt keeps its package lists, sources and repository keys
+var (
+	aptLists    = "/var/lib/apt/lists"
+	aptSources  = "/etc/apt/sources.list.d"
+	aptKeyrings = "/etc/apt/keyrings"
+)
+
+// IndexUpdated returns when the newest package list was downloaded. Images
+// often delete the lists, which leaves none, and lists older than a source
+// lack its packages, so both count as never refreshed.
+func (b *apt) IndexUpdated() (time.Time, error) {
+	lists, err := newestFile(aptLists)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sources, err := newestFile(aptSources)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if sources.After(lists) {
+		return time.Time{}, nil
+	}
+	return lists, nil
+}
+
+// AddRepository adds a PPA with add-apt-repository, and other repositories
+// as a deb822 source signed by their pinned key
+func (b *apt) AddRepository(repo Repository) error {
+	if strings.HasPrefix(repo.URL, "ppa:") {
+		return b.sudo("add-apt-repository", args(b.unless("-y"), []string{repo.URL})...)
+	}
+	if len(repo.Suites) == 0 {
+		return fmt.Errorf("apt repository %s has no suites", repo.Name)
+	}
+
+	source := managedHeader + "Types: deb\nURIs: " + repo.URL + "\nSuites: " + strings.Join(repo.Suites, " ") + "\n"
+	components := repo.Components
+	if len(components) == 0 && !strings.HasSuffix(repo.Suites[0], "/") {
+		components = []string{"main"}
+	}
+	if len(components) > 0 {
+		source += "Components: " + strings.Join(components, " ") + "\n"
+	}
+
+	if repo.Key != "" {
+		key, err := signingKey(repo)
+		if err != nil {
+			return err
+		}
+		keyring := aptKeyring(repo, isArmored(key))
+		if err := b.writeFile(keyring, key); err != nil {
+			return err
+		}
+		source += "Signed-By: " + keyring + "\n"
+	}
+
+	return b.writeFile(filepath.Join(aptSources, repo.Name+".sources"), []byte(source))
+}
+
+// aptKeyring returns where a repository's key is kept; apt tells armored
+// keys from binary ones by their extension
+func aptKeyring(repo Repository, armored bool) string {
+	if armored {
+		return filepath.Join(aptKeyrings, repo.Name+".asc")
+	}
+	return filepath.Join(aptKeyrings, repo.Name+".gpg")
+}
@@ -0,0 +1,125 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// asdf installs language runtimes with plugins and sets their version in
+// the home directory's .tool-versions. Packages are plugin names such as
+// nodejs; the version may be a prefix.
+type asdf struct{ base }
+
+func newAsdf(r Runner, opts Options) Backend { return &asdf{base{r, opts}} }
+
+func (b *asdf) Name() string { return "asdf" }
+
+func (b *asdf) Detect(string) bool { return b.runner.LookPath("asdf") }
+
+func (b *asdf) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion reads the current version from asdf current, whose
+// Installed column is false when the version is set but missing
+func (b *asdf) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("asdf", "current", pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != pkg.Name {
+			continue
+		}
+		if fields[len(fields)-1] == "false" || fields[1] == "______" {
+			return "", ErrNotInstalled
+		}
+		return fields[1], nil
+	}
+	return "", ErrNotInstalled
+}
+
+// Install adds the plugin when missing, installs the newest version
+// matching the requested one and sets it in the home directory
+func (b *asdf) Install(pkg Package) error {
+	plugins, err := b.query("asdf", "plugin", "list")
+	if err != nil && !errors.Is(err, ErrNotInstalled) {
+		return err
+	}
+	if !containsLine(plugins, pkg.Name) {
+		if err := b.runner.Run("asdf", "plugin", "add", pkg.Name); err != nil {
+			return err
+		}
+	}
+
+	version, err := b.resolve(pkg)
+	if err != nil {
+		return err
+	}
+	if err := b.runner.Run("asdf", "install", pkg.Name, version); err != nil {
+		return err
+	}
+	return b.runner.Run("asdf", "set", "--home", pkg.Name, version)
+}
+
+// Upgrade installs and sets the newest version matching the requested one
+func (b *asdf) Upgrade(pkg Package) error { return b.Install(pkg) }
+
+// Uninstall removes the current version
+func (b *asdf) Uninstall(pkg Package) error {
+	version, err := b.InstalledVersion(pkg)
+	if err != nil {
+		return err
+	}
+	return b.runner.Run("asdf", "uninstall", pkg.Name, version)
+}
+
+// RefreshIndex does nothing: plugins list versions when they resolve one
+func (b *asdf) RefreshIndex() error { return nil }
+
+// AddRepository adds a plugin from a repository URL
+func (b *asdf) AddRepository(repo Repository) error {
+	return b.runner.Run("asdf", "plugin", "add", repo.Name, repo.URL)
+}
+
+// ShimDir returns the shims directory in asdf's data directory
+func (b *asdf) ShimDir() string {
+	if dir := os.Getenv("ASDF_DATA_DIR"); dir != "" {
+		return filepath.Join(dir, "shims")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".asdf", "shims")
+}
+
+// resolve returns the exact version to install: a full version as is, the
+// newest matching a prefix such as 20, or the latest
+func (b *asdf) resolve(pkg Package) (string, error) {
+	if strings.Count(pkg.Version, ".") >= 2 {
+		return pkg.Version, nil
+	}
+	query := []string{"latest", pkg.Name}
+	if pkg.Version != "" {
+		query = append(query, pkg.Version)
+	}
+	out, err := b.runner.Output("asdf", query...)
+	if err != nil {
+		return "", err
+	}
+	version := strings.TrimSpace(string(out))
+	if version == "" {
+		return "", fmt.Errorf("asdf has no %s version matching %q", pkg.Name, latest(pkg.Version))
+	}
+	return version, nil
+}
+
+// containsLine reports whether out has a line equal to s
+func containsLine(out, s string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == s {
+			return true
+		}
+	}
+	return false
+}
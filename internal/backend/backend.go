@@ -0,0 +1,261 @@
+// Package backend drives package managers. Each manager is a Backend in a
+// registry, so detection, installs, status queries and removal share one
+// implementation per manager and adding a manager touches one file.
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Backend installs and queries packages with one package manager
+type Backend interface {
+	// Name is the manager's key in package_names, e.g. apt
+	Name() string
+
+	// Detect reports whether the manager is available on a system running
+	// osName
+	Detect(osName string) bool
+
+	IsInstalled(pkg Package) (bool, error)
+
+	// InstalledVersion returns the installed version of a package, or
+	// ErrNotInstalled
+	InstalledVersion(pkg Package) (string, error)
+
+	Install(pkg Package) error
+	Upgrade(pkg Package) error
+	Uninstall(pkg Package) error
+
+	// RefreshIndex updates the manager's package index. Managers without
+	// a local index do nothing.
+	RefreshIndex() error
+
+	// AddRepository makes the packages of a third-party repository
+	// available, or returns ErrUnsupported
+	AddRepository(repo Repository) error
+}
+
+// Package is a package and the options it is installed with. Backends
+// ignore the options of other managers.
+type Package struct {
+	Name string
+
+	// Version pins the version language managers install; system managers
+	// install the version of their repositories
+	Version string
+
+	// Classic installs a snap without confinement; Channel tracks a snap
+	// channel such as latest/edge
+	Classic bool
+	Channel string
+
+	// Remote is the flatpak remote to install from; User installs into
+	// the user's installation instead of the system-wide one
+	Remote string
+	User   bool
+}
+
+// Batcher is implemented by managers that install several packages in one
+// transaction, resolving dependencies and asking for confirmation once
+type Batcher interface {
+	InstallAll(pkgs []Package) error
+}
+
+// Indexed is implemented by managers that can tell when their package index
+// was last refreshed, so a fresh index is not refreshed again. The time is
+// zero when the index was never refreshed.
+type Indexed interface {
+	IndexUpdated() (time.Time, error)
+}
+
+// Throttled is implemented by managers whose mirrors ask clients not to
+// refresh more often than an interval. An index refreshed within it counts
+// as fresh, however short the configured max age.
+type Throttled interface {
+	MinRefreshInterval() time.Duration
+}
+
+// Shimmed is implemented by version managers, which run the runtimes they
+// install through shims. The shims directory may not be in PATH until the
+// user's shell is restarted.
+type Shimmed interface {
+	ShimDir() string
+}
+
+// Repository is a third-party package source
+type Repository struct {
+	Name string
+	URL  string
+
+	// Suites and Components select the parts of an apt repository
+	Suites     []string
+	Components []string
+
+	// Key is the URL of the repository's signing key, which must have the
+	// pinned Fingerprint
+	Key         string
+	Fingerprint string
+}
+
+// Options configure how backends run their manager
+type Options struct {
+	// Interactive lets the manager prompt for confirmation instead of
+	// passing its assume-yes flags
+	Interactive bool
+
+	// AsRoot runs privileged commands directly instead of through sudo,
+	// which minimal containers lack
+	AsRoot bool
+}
+
+var (
+	// ErrNotInstalled indicates a package is not installed
+	ErrNotInstalled = errors.New("package not installed")
+
+	// ErrUnsupported indicates a manager cannot perform an operation
+	ErrUnsupported = errors.New("not supported by this package manager")
+)
+
+// Runner runs the commands of backends. Tests replace it with a fake.
+type Runner interface {
+	// Run runs a command attached to the terminal
+	Run(name string, args ...string) error
+
+	// Output runs a command and returns its standard output
+	Output(name string, args ...string) ([]byte, error)
+
+	// LookPath reports whether a command is in PATH
+	LookPath(name string) bool
+}
+
+// ExitError is returned by runners when a command exits non-zero. Queries
+// use it to tell a package that is not installed from a manager that
+// cannot run.
+type ExitError struct {
+	Command string
+	Code    int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s exited with status %d", e.Command, e.Code)
+}
+
+// isExit reports whether err is a command exiting non-zero
+func isExit(err error) bool {
+	var exit *ExitError
+	return errors.As(err, &exit)
+}
+
+// base holds what every backend needs
+type base struct {
+	runner Runner
+	opts   Options
+}
+
+// unless returns flags when the backend runs non-interactively
+func (b base) unless(flags ...string) []string {
+	if b.opts.Interactive {
+		return nil
+	}
+	return flags
+}
+
+// when returns flags when the backend runs interactively
+func (b base) when(flags ...string) []string {
+	if b.opts.Interactive {
+		return flags
+	}
+	return nil
+}
+
+// sudo runs a command as root
+func (b base) sudo(name string, args ...string) error {
+	if b.opts.AsRoot {
+		return b.runner.Run(name, args...)
+	}
+	return b.runner.Run("sudo", append([]string{name}, args...)...)
+}
+
+// query runs a status query, reporting a non-zero exit as ErrNotInstalled
+func (b base) query(name string, args ...string) (string, error) {
+	out, err := b.runner.Output(name, args...)
+	if isExit(err) {
+		return "", ErrNotInstalled
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// isInstalled derives IsInstalled from InstalledVersion
+func isInstalled(b Backend, pkg Package) (bool, error) {
+	_, err := b.InstalledVersion(pkg)
+	if errors.Is(err, ErrNotInstalled) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// newestFile returns the modification time of the newest file in dir, or
+// zero when it has none. Lock files are touched by other commands, so they
+// do not count.
+func newestFile(dir string) (time.Time, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var newest time.Time
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || entry.Name() == "lock" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest, nil
+}
+
+// modTime returns the modification time of a file, or zero when it does not
+// exist
+func modTime(path string) (time.Time, error) {
+	info, err := os.Stat(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// names returns the names of packages
+func names(pkgs []Package) []string {
+	out := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		out[i] = pkg.Name
+	}
+	return out
+}
+
+// args joins argument lists
+func args(parts ...[]string) []string {
+	var out []string
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
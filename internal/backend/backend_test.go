@@ -0,0 +1,133 @@
+package backend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+// fakeRunner records commands instead of running them. Queries print the
+// configured output; commands without one exit with status 1. Temporary
+// files are recorded as $TMP.
+type fakeRunner struct {
+	paths   map[string]bool
+	outputs map[string]string // command line to output
+	fail    map[string]error  // command line to error
+	calls   []string
+}
+
+// tempFile matches the temporary files repository files are written from
+var tempFile = regexp.MustCompile(regexp.QuoteMeta(filepath.Join(os.TempDir(), "stackup-")) + `[0-9]+`)
+
+func (f *fakeRunner) Run(name string, args ...string) error {
+	line := tempFile.ReplaceAllString(strings.Join(append([]string{name}, args...), " "), "$$TMP")
+	f.calls = append(f.calls, line)
+	return f.fail[line]
+}
+
+func (f *fakeRunner) Output(name string, args ...string) ([]byte, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	f.calls = append(f.calls, line)
+	if err := f.fail[line]; err != nil {
+		return nil, err
+	}
+	out, ok := f.outputs[line]
+	if !ok {
+		return nil, &ExitError{Command: line, Code: 1}
+	}
+	return []byte(out), nil
+}
+
+func (f *fakeRunner) LookPath(name string) bool {
+	return f.paths[name]
+}
+
+func TestNamesMatchDomain(t *testing.T) {
+	if got, want := strings.Join(Names(), ","), strings.Join(domain.PackageManagers, ","); got != want {
+		t.Errorf("Names() = %s, want domain.PackageManagers %s", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range Names() {
+		b, err := New(name, &fakeRunner{}, Options{})
+		if err != nil {
+			t.Fatalf("New(%q) error: %v", name, err)
+		}
+		if b.Name() != name {
+			t.Errorf("New(%q).Name() = %q", name, b.Name())
+		}
+	}
+
+	if _, err := New("portage", &fakeRunner{}, Options{}); err == nil || err.Error() != "unsupported package manager: portage" {
+		t.Errorf("New(portage) error = %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	saved := append([]registration(nil), registry...)
+	defer func() { registry = saved }()
+
+	Register("fake", newBrew)
+	Register("apt", newDNF)
+
+	names := Names()
+	if names[0] != "apt" || names[len(names)-1] != "fake" {
+		t.Errorf("Names() = %v, want apt kept first and fake appended", names)
+	}
+	b, _ := New("apt", &fakeRunner{}, Options{})
+	if b.Name() != "dnf" {
+		t.Errorf("Register did not replace apt: got %s", b.Name())
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		os    string
+		paths []string
+		want  string
+	}{
+		{"linux", []string{"apt-get", "brew"}, "apt,brew"},
+		{"linux", []string{"pacman", "dnf"}, "dnf,pacman"},
+		{"linux", []string{"yum", "dnf"}, "dnf,yum"},
+		{"linux", []string{"nix", "brew", "zypper"}, "zypper,brew,nix"},
+		{"linux", []string{"apk"}, "apk"},
+		{"linux", []string{"flatpak", "snap", "apt-get"}, "apt,snap,flatpak"},
+		{"darwin", []string{"snap", "flatpak"}, ""},
+		{"linux", []string{"npm", "cargo", "go", "pipx", "gem"}, ""},
+		{"windows", []string{"winget", "npm"}, "winget"},
+		{"linux", []string{"xbps-install", "emerge"}, "xbps,emerge"},
+		{"darwin", []string{"nix", "brew"}, "brew,nix"},
+		{"linux", []string{"winget"}, ""},
+		{"darwin", []string{"brew", "apt-get"}, "brew"},
+		{"windows", []string{"choco", "winget"}, "winget,choco"},
+	}
+
+	for _, tt := range tests {
+		runner := &fakeRunner{paths: map[string]bool{}}
+		for _, path := range tt.paths {
+			runner.paths[path] = true
+		}
+		if got := strings.Join(Detect(tt.os, runner), ","); got != tt.want {
+			t.Errorf("Detect(%s, %v) = %q, want %q", tt.os, tt.paths, got, tt.want)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	broken := errors.New("exec: not found")
+	runner := &fakeRunner{fail: map[string]error{"pacman -Q git": broken}}
+	b, _ := New("pacman", runner, Options{})
+
+	if _, err := b.InstalledVersion(Package{Name: "git"}); !errors.Is(err, broken) {
+		t.Errorf("InstalledVersion() error = %v, want the runner's error", err)
+	}
+	if installed, err := b.IsInstalled(Package{Name: "git"}); installed || !errors.Is(err, broken) {
+		t.Errorf("IsInstalled() = %v, %v, want false and the runner's error", installed, err)
+	}
+}
@@ -0,0 +1,545 @@
+package backend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		manager     string
+		interactive bool
+		want        []string // install, upgrade, uninstall, refresh, add repository
+	}{
+		{"apt", false, []string{
+			"sudo env DEBIAN_FRONTEND=noninteractive apt-get install -y git",
+			"sudo env DEBIAN_FRONTEND=noninteractive apt-get install --only-upgrade -y git",
+			"sudo env DEBIAN_FRONTEND=noninteractive apt-get remove -y git",
+			"sudo env DEBIAN_FRONTEND=noninteractive apt-get update",
+			"sudo add-apt-repository -y ppa:git-core/ppa",
+		}},
+		{"apt", true, []string{
+			"sudo apt-get install git",
+			"sudo apt-get install --only-upgrade git",
+			"sudo apt-get remove git",
+			"sudo apt-get update",
+			"sudo add-apt-repository ppa:git-core/ppa",
+		}},
+		{"dnf", false, []string{
+			"sudo dnf install -y git",
+			"sudo dnf upgrade -y git",
+			"sudo dnf remove -y git",
+			"sudo dnf makecache",
+			"sudo install -D -m 0644 $TMP /etc/yum.repos.d/git-core.repo",
+		}},
+		{"pacman", false, []string{
+			"sudo pacman -S --noconfirm git",
+			"sudo pacman -S --noconfirm git",
+			"sudo pacman -R --noconfirm git",
+			"sudo pacman -Sy",
+			"",
+		}},
+		{"yum", false, []string{
+			"sudo yum install -y git",
+			"sudo yum update -y git",
+			"sudo yum remove -y git",
+			"sudo yum makecache",
+			"sudo install -D -m 0644 $TMP /etc/yum.repos.d/git-core.repo",
+		}},
+		{"zypper", false, []string{
+			"sudo zypper --non-interactive install git",
+			"sudo zypper --non-interactive update git",
+			"sudo zypper --non-interactive remove git",
+			"sudo zypper --non-interactive refresh",
+			"sudo install -D -m 0644 $TMP /etc/zypp/repos.d/git-core.repo",
+		}},
+		{"zypper", true, []string{
+			"sudo zypper install git",
+			"sudo zypper update git",
+			"sudo zypper remove git",
+			"sudo zypper refresh",
+			"sudo install -D -m 0644 $TMP /etc/zypp/repos.d/git-core.repo",
+		}},
+		{"apk", false, []string{
+			"sudo apk add git",
+			"sudo apk upgrade git",
+			"sudo apk del git",
+			"sudo apk update",
+			"",
+		}},
+		{"xbps", false, []string{
+			"sudo xbps-install -y git",
+			"sudo xbps-install -u -y git",
+			"sudo xbps-remove -y git",
+			"sudo xbps-install -S",
+			"",
+		}},
+		{"emerge", false, []string{
+			"sudo emerge --noreplace git",
+			"sudo emerge --update git",
+			"sudo emerge --depclean git",
+			"sudo emerge --sync",
+			"sudo eselect repository add git-core git ppa:git-core/ppa",
+		}},
+		{"emerge", true, []string{
+			"sudo emerge --ask --noreplace git",
+			"sudo emerge --ask --update git",
+			"sudo emerge --ask --depclean git",
+			"sudo emerge --sync",
+			"sudo eselect repository add git-core git ppa:git-core/ppa",
+		}},
+		{"nix", false, []string{
+			"nix --extra-experimental-features nix-command flakes profile install nixpkgs#git",
+			"nix --extra-experimental-features nix-command flakes profile upgrade git",
+			"nix --extra-experimental-features nix-command flakes profile remove git",
+			"",
+			"nix --extra-experimental-features nix-command flakes registry add git-core ppa:git-core/ppa",
+		}},
+		{"snap", false, []string{
+			"sudo snap install git",
+			"sudo snap refresh git",
+			"sudo snap remove git",
+			"",
+			"",
+		}},
+		{"flatpak", false, []string{
+			"sudo flatpak remote-add --system --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo; sudo flatpak install --system -y flathub git",
+			"sudo flatpak update --system -y git",
+			"sudo flatpak uninstall --system -y git",
+			"",
+			"sudo flatpak remote-add --system --if-not-exists git-core ppa:git-core/ppa",
+		}},
+		{"flatpak", true, []string{
+			"sudo flatpak remote-add --system --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo; sudo flatpak install --system flathub git",
+			"sudo flatpak update --system git",
+			"sudo flatpak uninstall --system git",
+			"",
+			"sudo flatpak remote-add --system --if-not-exists git-core ppa:git-core/ppa",
+		}},
+		{"brew", true, []string{
+			"brew install git",
+			"brew upgrade git",
+			"brew uninstall git",
+			"brew update",
+			"brew tap git-core ppa:git-core/ppa",
+		}},
+		{"winget", false, []string{
+			"winget install -e --id git --accept-package-agreements --accept-source-agreements",
+			"winget upgrade -e --id git --accept-package-agreements --accept-source-agreements",
+			"winget uninstall -e --id git --accept-source-agreements",
+			"winget source update",
+			"winget source add --name git-core --arg ppa:git-core/ppa --accept-source-agreements",
+		}},
+		{"winget", true, []string{
+			"winget install -e --id git",
+			"winget upgrade -e --id git",
+			"winget uninstall -e --id git",
+			"winget source update",
+			"winget source add --name git-core --arg ppa:git-core/ppa",
+		}},
+		{"pipx", false, []string{
+			"pipx install git",
+			"pipx upgrade git",
+			"pipx uninstall git",
+			"",
+			"",
+		}},
+		{"npm", false, []string{
+			"npm install -g git",
+			"npm install -g git@latest",
+			"npm uninstall -g git",
+			"",
+			"",
+		}},
+		{"cargo", false, []string{
+			"cargo install git",
+			"cargo install git",
+			"cargo uninstall git",
+			"",
+			"",
+		}},
+		{"go", false, []string{
+			"go install git@latest",
+			"go install git@latest",
+			"",
+			"",
+			"",
+		}},
+		{"gem", false, []string{
+			"gem install git --no-document",
+			"gem update git --no-document",
+			"gem uninstall git --all --executables",
+			"",
+			"gem sources --add ppa:git-core/ppa",
+		}},
+		{"gem", true, []string{
+			"gem install git --no-document",
+			"gem update git --no-document",
+			"gem uninstall git",
+			"",
+			"gem sources --add ppa:git-core/ppa",
+		}},
+		{"mise", false, []string{
+			"mise use --global --yes git@latest",
+			"mise upgrade --yes git",
+			"mise unuse --global --yes git",
+			"",
+			"mise plugins install --yes git-core ppa:git-core/ppa",
+		}},
+		{"mise", true, []string{
+			"mise use --global git@latest",
+			"mise upgrade git",
+			"mise unuse --global git",
+			"",
+			"mise plugins install git-core ppa:git-core/ppa",
+		}},
+		{"choco", false, []string{
+			"choco install git -y",
+			"choco upgrade git -y",
+			"choco uninstall git -y",
+			"",
+			"choco source add --name=git-core --source=ppa:git-core/ppa",
+		}},
+	}
+
+	for _, tt := range tests {
+		runner := &fakeRunner{}
+		b, err := New(tt.manager, runner, Options{Interactive: tt.interactive})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ops := []func() error{
+			func() error { return b.Install(Package{Name: "git"}) },
+			func() error { return b.Upgrade(Package{Name: "git"}) },
+			func() error { return b.Uninstall(Package{Name: "git"}) },
+			b.RefreshIndex,
+			func() error { return b.AddRepository(Repository{Name: "git-core", URL: "ppa:git-core/ppa"}) },
+		}
+		for i, op := range ops {
+			runner.calls = nil
+			err := op()
+			if tt.want[i] == "" {
+				if len(runner.calls) != 0 || err != nil && !errors.Is(err, ErrUnsupported) {
+					t.Errorf("%s operation %d ran %v, %v; want nothing", tt.manager, i, runner.calls, err)
+				}
+				continue
+			}
+			if err != nil || strings.Join(runner.calls, "; ") != tt.want[i] {
+				t.Errorf("%s (interactive %v) operation %d ran %q, %v; want %q", tt.manager, tt.interactive, i, runner.calls, err, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestInstalledVersion(t *testing.T) {
+	tests := []struct {
+		manager string
+		pkg     string
+		query   string
+		output  string
+		want    string // empty when not installed
+	}{
+		{"apt", "git", "dpkg-query -W -f=${Status}\t${Version} git", "install ok installed\t1:2.43.0-1ubuntu7\n", "1:2.43.0-1ubuntu7"},
+		{"apt", "git", "dpkg-query -W -f=${Status}\t${Version} git", "deinstall ok config-files\t1:2.43.0-1ubuntu7", ""},
+		{"dnf", "git", "rpm -q --qf %{VERSION}-%{RELEASE} git", "2.46.0-1.fc40", "2.46.0-1.fc40"},
+		{"pacman", "git", "pacman -Q git", "git 2.46.0-1\n", "2.46.0-1"},
+		{"brew", "node", "brew list --versions node", "node 20.11.0 22.3.0\n", "20.11.0"},
+		{"brew", "node", "brew list --versions node", "", ""},
+		{"winget", "Git.Git", "winget list -e --id Git.Git --accept-source-agreements",
+			"Name Id      Version  Source\n-----------------------------\nGit  Git.Git 2.46.0   winget\n", "2.46.0"},
+		{"winget", "Microsoft.VisualStudioCode", "winget list -e --id Microsoft.VisualStudioCode --accept-source-agreements",
+			"Name                         Id                         Version\n-----\nMicrosoft Visual Studio Code microsoft.visualstudiocode 1.92.0\n", "1.92.0"},
+		{"yum", "git", "rpm -q --qf %{VERSION}-%{RELEASE} git", "1.8.3.1-25.el7_9", "1.8.3.1-25.el7_9"},
+		{"zypper", "git", "rpm -q --qf %{VERSION}-%{RELEASE} git", "2.45.2-1.1", "2.45.2-1.1"},
+		{"apk", "git", "apk list --installed git", "git-2.45.2-r0 x86_64 {git} (GPL-2.0-only) [installed]\n", "2.45.2-r0"},
+		{"apk", "git", "apk list --installed git", "", ""},
+		{"xbps", "git", "xbps-query -p pkgver git", "git-2.46.0_1\n", "2.46.0_1"},
+		{"emerge", "dev-vcs/git", "portageq best_version / dev-vcs/git", "dev-vcs/git-2.45.2\n", "2.45.2"},
+		{"emerge", "dev-vcs/git", "portageq best_version / dev-vcs/git", "\n", ""},
+		{"nix", "ripgrep", "nix --extra-experimental-features nix-command flakes profile list --json",
+			`{"elements":{"ripgrep":{"attrPath":"legacyPackages.x86_64-linux.ripgrep","storePaths":["/nix/store/0123abcd-ripgrep-14.1.0"]}},"version":3}`, "14.1.0"},
+		{"nix", "nixpkgs#ripgrep", "nix --extra-experimental-features nix-command flakes profile list --json",
+			`{"elements":[{"attrPath":"legacyPackages.x86_64-linux.ripgrep","storePaths":["/nix/store/0123abcd-ripgrep-13.0.0"]}],"version":2}`, "13.0.0"},
+		{"nix", "git", "nix --extra-experimental-features nix-command flakes profile list --json", `{"elements":{},"version":3}`, ""},
+		{"snap", "code", "snap list code",
+			"Name  Version      Rev  Tracking       Publisher  Notes\ncode  f1f2e3d4     165  latest/stable  vscode**   classic\n", "f1f2e3d4"},
+		{"flatpak", "com.slack.Slack", "flatpak list --system --columns=application,version",
+			"org.gnome.Platform\t\ncom.slack.Slack\t4.39.95\n", "4.39.95"},
+		{"flatpak", "com.slack.Slack", "flatpak list --system --columns=application,version", "org.gnome.Platform\t\n", ""},
+		{"pipx", "black", "pipx list --json",
+			`{"pipx_spec_version":"0.1","venvs":{"black":{"metadata":{"main_package":{"package":"black","package_version":"24.4.2"}}}}}`, "24.4.2"},
+		{"pipx", "ruff", "pipx list --json", `{"pipx_spec_version":"0.1","venvs":{}}`, ""},
+		{"npm", "@angular/cli", "npm ls -g --json --depth=0 @angular/cli",
+			`{"name":"lib","dependencies":{"@angular/cli":{"version":"17.3.8","overridden":false}}}`, "17.3.8"},
+		{"cargo", "ripgrep", "cargo install --list", "bat v0.24.0:\n    bat\nripgrep v14.1.0:\n    rg\n", "14.1.0"},
+		{"cargo", "rg", "cargo install --list", "ripgrep v14.1.0:\n    rg\n", ""},
+		{"gem", "rails", "gem list --local --exact rails", "rails (7.1.3, 7.0.8)\n", "7.1.3"},
+		{"gem", "json", "gem list --local --exact json", "json (default: 2.7.1)\n", "2.7.1"},
+		{"gem", "rails", "gem list --local --exact rails", "\n", ""},
+		{"mise", "node", "mise ls --installed --json node",
+			`[{"version":"18.20.3","active":false},{"version":"20.14.0","active":true}]`, "20.14.0"},
+		{"mise", "node", "mise ls --installed --json node", `[{"version":"18.20.3"},{"version":"20.14.0"}]`, "20.14.0"},
+		{"mise", "node", "mise ls --installed --json node", `[]`, ""},
+		{"asdf", "nodejs", "asdf current nodejs",
+			"Name    Version  Source                 Installed\nnodejs  20.14.0  /home/dev/.tool-versions true\n", "20.14.0"},
+		{"asdf", "nodejs", "asdf current nodejs",
+			"Name    Version  Source                 Installed\nnodejs  22.2.0   /home/dev/.tool-versions false\n", ""},
+		{"asdf", "nodejs", "asdf current nodejs", "nodejs          20.14.0         /home/dev/.tool-versions\n", "20.14.0"},
+		{"choco", "git", "choco list --exact git --limit-output", "git|2.46.0\n", "2.46.0"},
+		{"choco", "git", "choco list --exact git --limit-output", "", ""},
+	}
+
+	for _, tt := range tests {
+		runner := &fakeRunner{outputs: map[string]string{tt.query: tt.output}}
+		b, _ := New(tt.manager, runner, Options{})
+
+		version, err := b.InstalledVersion(Package{Name: tt.pkg})
+		if tt.want == "" {
+			if !errors.Is(err, ErrNotInstalled) {
+				t.Errorf("%s InstalledVersion(%s) = %q, %v; want ErrNotInstalled", tt.manager, tt.pkg, version, err)
+			}
+		} else if err != nil || version != tt.want {
+			t.Errorf("%s InstalledVersion(%s) = %q, %v; want %q", tt.manager, tt.pkg, version, err, tt.want)
+		}
+
+		installed, err := b.IsInstalled(Package{Name: tt.pkg})
+		if err != nil || installed != (tt.want != "") {
+			t.Errorf("%s IsInstalled(%s) = %v, %v", tt.manager, tt.pkg, installed, err)
+		}
+	}
+
+	// A query that exits non-zero means the package is missing
+	for _, name := range Names() {
+		b, _ := New(name, &fakeRunner{}, Options{})
+		if installed, err := b.IsInstalled(Package{Name: "missing"}); installed || err != nil {
+			t.Errorf("%s IsInstalled(missing) = %v, %v; want false, nil", name, installed, err)
+		}
+	}
+}
+
+func TestAsRoot(t *testing.T) {
+	runner := &fakeRunner{}
+	b, _ := New("apk", runner, Options{AsRoot: true})
+	if err := b.Install(Package{Name: "git"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(runner.calls, "; "); got != "apk add git" {
+		t.Errorf("Install() ran %q, want apk add git without sudo", got)
+	}
+}
+
+func TestPackageOptions(t *testing.T) {
+	tests := []struct {
+		manager string
+		pkg     Package
+		want    string
+	}{
+		{"snap", Package{Name: "code", Classic: true}, "sudo snap install code --classic"},
+		{"snap", Package{Name: "node", Classic: true, Channel: "20/stable"}, "sudo snap install node --classic --channel=20/stable"},
+		{"flatpak", Package{Name: "com.slack.Slack", User: true},
+			"flatpak remote-add --user --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo; flatpak install --user -y flathub com.slack.Slack"},
+		{"flatpak", Package{Name: "org.gnome.Builder", Remote: "gnome-nightly"}, "sudo flatpak install --system -y gnome-nightly org.gnome.Builder"},
+		{"apt", Package{Name: "git", Version: "2.43", Classic: true, User: true}, "sudo env DEBIAN_FRONTEND=noninteractive apt-get install -y git"},
+		{"pipx", Package{Name: "black", Version: "24.4.2"}, "pipx install black==24.4.2"},
+		{"npm", Package{Name: "@angular/cli", Version: "17"}, "npm install -g @angular/cli@17"},
+		{"cargo", Package{Name: "ripgrep", Version: "14.1.0"}, "cargo install ripgrep --version 14.1.0"},
+		{"go", Package{Name: "golang.org/x/tools/gopls", Version: "0.15.3"}, "go install golang.org/x/tools/gopls@v0.15.3"},
+		{"go", Package{Name: "golang.org/x/tools/gopls", Version: "master"}, "go install golang.org/x/tools/gopls@master"},
+		{"gem", Package{Name: "rails", Version: "7.1.3"}, "gem install rails --no-document --version 7.1.3"},
+		{"mise", Package{Name: "node", Version: "20"}, "mise use --global --yes node@20"},
+	}
+
+	for _, tt := range tests {
+		runner := &fakeRunner{}
+		b, _ := New(tt.manager, runner, Options{})
+		if err := b.Install(tt.pkg); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(runner.calls, "; "); got != tt.want {
+			t.Errorf("%s Install(%+v) ran %q, want %q", tt.manager, tt.pkg, got, tt.want)
+		}
+	}
+}
+
+func TestGoInstalledVersion(t *testing.T) {
+	tests := []struct {
+		pkg    string
+		env    string
+		binary string
+	}{
+		{"golang.org/x/tools/gopls", "\n/home/dev/go\n\n", "/home/dev/go/bin/gopls"},
+		{"github.com/go-delve/delve/cmd/dlv", "/opt/bin\n/home/dev/go\n\n", "/opt/bin/dlv"},
+		{"github.com/golang-migrate/migrate/v4", "\n/home/dev/go\n\n", "/home/dev/go/bin/migrate"},
+	}
+
+	for _, tt := range tests {
+		runner := &fakeRunner{outputs: map[string]string{
+			"go env GOBIN GOPATH GOEXE": tt.env,
+			"go version -m " + tt.binary: tt.binary + ": go1.22.4\n\tpath\t" + tt.pkg +
+				"\n\tmod\tgolang.org/x/tools/gopls\tv0.15.3\th1:abc=\n",
+		}}
+		b, _ := New("go", runner, Options{})
+		if version, err := b.InstalledVersion(Package{Name: tt.pkg}); err != nil || version != "0.15.3" {
+			t.Errorf("InstalledVersion(%s) = %q, %v; want 0.15.3 read from %s", tt.pkg, version, err, tt.binary)
+		}
+	}
+}
+
+func TestInstallAll(t *testing.T) {
+	tests := []struct {
+		manager string
+		want    string
+	}{
+		{"apt", "sudo env DEBIAN_FRONTEND=noninteractive apt-get install -y git curl jq"},
+		{"dnf", "sudo dnf install -y git curl jq"},
+		{"pacman", "sudo pacman -S --noconfirm git curl jq"},
+		{"brew", "brew install git curl jq"},
+		{"choco", "choco install git curl jq -y"},
+	}
+
+	pkgs := []Package{{Name: "git"}, {Name: "curl"}, {Name: "jq"}}
+	for _, tt := range tests {
+		runner := &fakeRunner{}
+		b, _ := New(tt.manager, runner, Options{})
+		batcher, ok := b.(Batcher)
+		if !ok {
+			t.Errorf("%s does not implement Batcher", tt.manager)
+			continue
+		}
+		if err := batcher.InstallAll(pkgs); err != nil || strings.Join(runner.calls, "; ") != tt.want {
+			t.Errorf("%s InstallAll() ran %q, %v; want %q", tt.manager, runner.calls, err, tt.want)
+		}
+	}
+}
+
+func TestAsdf(t *testing.T) {
+	tests := []struct {
+		version string
+		outputs map[string]string
+		want    string
+	}{
+		{"20", map[string]string{"asdf latest nodejs 20": "20.14.0\n"},
+			"asdf plugin list; asdf plugin add nodejs; asdf latest nodejs 20; asdf install nodejs 20.14.0; asdf set --home nodejs 20.14.0"},
+		{"", map[string]string{"asdf plugin list": "golang\nnodejs\n", "asdf latest nodejs": "22.2.0\n"},
+			"asdf plugin list; asdf latest nodejs; asdf install nodejs 22.2.0; asdf set --home nodejs 22.2.0"},
+		{"20.14.0", map[string]string{"asdf plugin list": "nodejs\n"},
+			"asdf plugin list; asdf install nodejs 20.14.0; asdf set --home nodejs 20.14.0"},
+	}
+
+	for _, tt := range tests {
+		runner := &fakeRunner{outputs: tt.outputs}
+		b, _ := New("asdf", runner, Options{})
+		if err := b.Install(Package{Name: "nodejs", Version: tt.version}); err != nil {
+			t.Errorf("Install(%q) error: %v", tt.version, err)
+		}
+		if got := strings.Join(runner.calls, "; "); got != tt.want {
+			t.Errorf("Install(%q) ran %q, want %q", tt.version, got, tt.want)
+		}
+	}
+
+	runner := &fakeRunner{outputs: map[string]string{"asdf plugin list": "nodejs\n", "asdf latest nodejs 99": "\n"}}
+	b, _ := New("asdf", runner, Options{})
+	if err := b.Install(Package{Name: "nodejs", Version: "99"}); err == nil || !strings.Contains(err.Error(), `no nodejs version matching "99"`) {
+		t.Errorf("Install(99) error = %v", err)
+	}
+}
+
+func TestShimDir(t *testing.T) {
+	t.Setenv("MISE_DATA_DIR", "/data/mise")
+	t.Setenv("ASDF_DATA_DIR", "/data/asdf")
+
+	for name, want := range map[string]string{"mise": "/data/mise/shims", "asdf": "/data/asdf/shims"} {
+		b, _ := New(name, &fakeRunner{}, Options{})
+		shimmed, ok := b.(Shimmed)
+		if !ok {
+			t.Fatalf("%s does not implement Shimmed", name)
+		}
+		if got := shimmed.ShimDir(); got != filepath.FromSlash(want) {
+			t.Errorf("%s ShimDir() = %q, want %q", name, got, want)
+		}
+	}
+	b, _ := New("npm", &fakeRunner{}, Options{})
+	if _, ok := b.(Shimmed); ok {
+		t.Error("npm implements Shimmed")
+	}
+}
+
+func TestIndexUpdated(t *testing.T) {
+	lists, sources := t.TempDir(), t.TempDir()
+	defer func(lists, sources string) { aptLists, aptSources = lists, sources }(aptLists, aptSources)
+	aptLists, aptSources = lists, sources
+
+	b, _ := New("apt", &fakeRunner{}, Options{})
+	indexed := b.(Indexed)
+	if updated, err := indexed.IndexUpdated(); err != nil || !updated.IsZero() {
+		t.Errorf("IndexUpdated() with no lists = %v, %v; want zero", updated, err)
+	}
+
+	older, newer := time.Now().Add(-3*time.Hour), time.Now().Add(-time.Hour)
+	for name, at := range map[string]time.Time{"deb.debian.org_InRelease": older, "deb.debian.org_Packages": newer, "lock": time.Now()} {
+		path := filepath.Join(lists, name)
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Partial downloads are a directory, which does not count
+	if err := os.Mkdir(filepath.Join(lists, "partial"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := indexed.IndexUpdated(); err != nil || !updated.Equal(newer) {
+		t.Errorf("IndexUpdated() = %v, %v; want %v", updated, err, newer)
+	}
+
+	// A source added since the lists were downloaded makes them stale
+	if err := os.WriteFile(filepath.Join(sources, "docker.sources"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := indexed.IndexUpdated(); err != nil || !updated.IsZero() {
+		t.Errorf("IndexUpdated() after adding a source = %v, %v; want zero", updated, err)
+	}
+
+	aptLists = filepath.Join(lists, "missing")
+	if updated, err := indexed.IndexUpdated(); err != nil || !updated.IsZero() {
+		t.Errorf("IndexUpdated() without lists = %v, %v; want zero", updated, err)
+	}
+
+	for _, name := range []string{"dnf", "winget", "cargo"} {
+		b, _ := New(name, &fakeRunner{}, Options{})
+		if _, ok := b.(Indexed); ok {
+			t.Errorf("%s implements Indexed", name)
+		}
+	}
+}
+
+func TestEmergeIndexUpdated(t *testing.T) {
+	defer func(timestamp string) { emergeTimestamp = timestamp }(emergeTimestamp)
+	emergeTimestamp = filepath.Join(t.TempDir(), "timestamp.chk")
+
+	b, _ := New("emerge", &fakeRunner{}, Options{})
+	if updated, err := b.(Indexed).IndexUpdated(); err != nil || !updated.IsZero() {
+		t.Errorf("IndexUpdated() never synced = %v, %v; want zero", updated, err)
+	}
+
+	synced := time.Now().Add(-5 * time.Hour)
+	if err := os.WriteFile(emergeTimestamp, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(emergeTimestamp, synced, synced); err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := b.(Indexed).IndexUpdated(); err != nil || !updated.Equal(synced) {
+		t.Errorf("IndexUpdated() = %v, %v; want %v", updated, err, synced)
+	}
+
+	if interval := b.(Throttled).MinRefreshInterval(); interval != 24*time.Hour {
+		t.Errorf("MinRefreshInterval() = %v, want 24h", interval)
+	}
+}
@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// brew manages Homebrew formulae and casks
+type brew struct{ base }
+
+func newBrew(r Runner, opts Options) Backend { return &brew{base{r, opts}} }
+
+func (b *brew) Name() string { return "brew" }
+
+func (b *brew) Detect(osName string) bool {
+	return (osName == "darwin" || osName == "linux") && b.runner.LookPath("brew")
+}
+
+func (b *brew) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+func (b *brew) InstalledVersion(pkg Package) (string, error) {
+	// brew list --versions prints the name and each installed version
+	out, err := b.query("brew", "list", "--versions", pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	if version := field(out, 1); version != "" {
+		return version, nil
+	}
+	return "", ErrNotInstalled
+}
+
+// Install needs no confirmation flags: Homebrew does not prompt
+func (b *brew) Install(pkg Package) error { return b.InstallAll([]Package{pkg}) }
+
+// InstallAll installs formulae and casks together; brew tells them apart
+func (b *brew) InstallAll(pkgs []Package) error {
+	return b.runner.Run("brew", append([]string{"install"}, names(pkgs)...)...)
+}
+
+func (b *brew) Upgrade(pkg Package) error { return b.runner.Run("brew", "upgrade", pkg.Name) }
+
+func (b *brew) Uninstall(pkg Package) error { return b.runner.Run("brew", "uninstall", pkg.Name) }
+
+func (b *brew) RefreshIndex() error { return b.runner.Run("brew", "update") }
+
+// IndexUpdated returns when brew update last fetched Homebrew's repository
+func (b *brew) IndexUpdated() (time.Time, error) {
+	out, err := b.runner.Output("brew", "--repository")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return modTime(filepath.Join(strings.TrimSpace(string(out)), ".git", "FETCH_HEAD"))
+}
+
+// AddRepository taps a repository; the URL may be empty for taps on GitHub
+func (b *brew) AddRepository(repo Repository) error {
+	if repo.URL == "" {
+		return b.runner.Run("brew", "tap", repo.Name)
+	}
+	return b.runner.Run("brew", "tap", repo.Name, repo.URL)
+}
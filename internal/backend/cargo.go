@@ -0,0 +1,56 @@
+package backend
+
+import "strings"
+
+// cargo installs Rust binaries from crates.io, as cargo install
+type cargo struct{ base }
+
+func newCargo(r Runner, opts Options) Backend { return &cargo{base{r, opts}} }
+
+func (b *cargo) Name() string { return "cargo" }
+
+func (b *cargo) Detect(string) bool { return b.runner.LookPath("cargo") }
+
+func (b *cargo) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion finds the crate in cargo install --list, which prints
+// a "name vversion:" line for each crate followed by its binaries
+func (b *cargo) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("cargo", "install", "--list")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == pkg.Name && !strings.HasPrefix(line, " ") {
+			return strings.TrimPrefix(strings.TrimSuffix(fields[1], ":"), "v"), nil
+		}
+	}
+	return "", ErrNotInstalled
+}
+
+// Install builds and installs the crate, at its pinned version if any.
+// cargo does not prompt.
+func (b *cargo) Install(pkg Package) error {
+	return b.runner.Run("cargo", cargoArgs(pkg)...)
+}
+
+// Upgrade installs the crate again, which replaces an older version
+func (b *cargo) Upgrade(pkg Package) error {
+	return b.runner.Run("cargo", cargoArgs(pkg)...)
+}
+
+func (b *cargo) Uninstall(pkg Package) error { return b.runner.Run("cargo", "uninstall", pkg.Name) }
+
+// RefreshIndex does nothing: cargo updates the index when it installs
+func (b *cargo) RefreshIndex() error { return nil }
+
+// AddRepository is not supported: registries are cargo configuration
+func (b *cargo) AddRepository(Repository) error { return ErrUnsupported }
+
+func cargoArgs(pkg Package) []string {
+	if pkg.Version == "" {
+		return []string{"install", pkg.Name}
+	}
+	return []string{"install", pkg.Name, "--version", pkg.Version}
+}
@@ -0,0 +1,53 @@
+package backend
+
+import "strings"
+
+// choco manages Windows packages with Chocolatey
+type choco struct{ base }
+
+func newChoco(r Runner, opts Options) Backend { return &choco{base{r, opts}} }
+
+func (b *choco) Name() string { return "choco" }
+
+func (b *choco) Detect(osName string) bool {
+	return osName == "windows" && b.runner.LookPath("choco")
+}
+
+func (b *choco) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion reads choco list, which lists local packages as
+// name|version since Chocolatey 2
+func (b *choco) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("choco", "list", "--exact", pkg.Name, "--limit-output")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		name, version, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if ok && strings.EqualFold(name, pkg.Name) {
+			return version, nil
+		}
+	}
+	return "", ErrNotInstalled
+}
+
+func (b *choco) Install(pkg Package) error { return b.InstallAll([]Package{pkg}) }
+
+func (b *choco) InstallAll(pkgs []Package) error {
+	return b.runner.Run("choco", args([]string{"install"}, names(pkgs), b.unless("-y"))...)
+}
+
+func (b *choco) Upgrade(pkg Package) error {
+	return b.runner.Run("choco", args([]string{"upgrade", pkg.Name}, b.unless("-y"))...)
+}
+
+func (b *choco) Uninstall(pkg Package) error {
+	return b.runner.Run("choco", args([]string{"uninstall", pkg.Name}, b.unless("-y"))...)
+}
+
+// RefreshIndex does nothing: Chocolatey queries its sources on every install
+func (b *choco) RefreshIndex() error { return nil }
+
+func (b *choco) AddRepository(repo Repository) error {
+	return b.runner.Run("choco", "source", "add", "--name="+repo.Name, "--source="+repo.URL)
+}
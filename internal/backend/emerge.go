@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"strings"
+	"time"
+)
+
+// emerge manages Gentoo packages. Packages are best named with their
+// category, e.g. dev-vcs/git.
+type emerge struct{ base }
+
+func newEmerge(r Runner, opts Options) Backend { return &emerge{base{r, opts}} }
+
+func (b *emerge) Name() string { return "emerge" }
+
+func (b *emerge) Detect(osName string) bool {
+	return osName == "linux" && b.runner.LookPath("emerge")
+}
+
+func (b *emerge) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion asks portage for the best installed version, printed as
+// category/name-version
+func (b *emerge) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("portageq", "best_version", "/", pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	name := pkg.Name[strings.LastIndex(pkg.Name, "/")+1:]
+	atom := strings.TrimSpace(out)
+	version, ok := strings.CutPrefix(atom[strings.LastIndex(atom, "/")+1:], name+"-")
+	if !ok {
+		return "", ErrNotInstalled
+	}
+	return version, nil
+}
+
+// Install merges a package; emerge only asks for confirmation with --ask
+func (b *emerge) Install(pkg Package) error {
+	return b.sudo("emerge", args(b.when("--ask"), []string{"--noreplace", pkg.Name})...)
+}
+
+func (b *emerge) Upgrade(pkg Package) error {
+	return b.sudo("emerge", args(b.when("--ask"), []string{"--update", pkg.Name})...)
+}
+
+// Uninstall removes a package unless something else depends on it
+func (b *emerge) Uninstall(pkg Package) error {
+	return b.sudo("emerge", args(b.when("--ask"), []string{"--depclean", pkg.Name})...)
+}
+
+func (b *emerge) RefreshIndex() error { return b.sudo("emerge", "--sync") }
+
+// emergeTimestamp is synced along with the Gentoo repository, so its
+// modification time is when the repository was last refreshed
+var emergeTimestamp = "/var/db/repos/gentoo/metadata/timestamp.chk"
+
+func (b *emerge) IndexUpdated() (time.Time, error) { return modTime(emergeTimestamp) }
+
+// MinRefreshInterval is a day: Gentoo's rsync mirrors ban hosts that sync
+// more often
+func (b *emerge) MinRefreshInterval() time.Duration { return 24 * time.Hour }
+
+// AddRepository adds an overlay from a git URL, or enables a listed one
+func (b *emerge) AddRepository(repo Repository) error {
+	if repo.URL == "" {
+		return b.sudo("eselect", "repository", "enable", repo.Name)
+	}
+	return b.sudo("eselect", "repository", "add", repo.Name, "git", repo.URL)
+}
@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ExecRunner runs commands on the host
+type ExecRunner struct{}
+
+// Run runs a command with the terminal attached, so managers can prompt
+func (ExecRunner) Run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	return exitError(name, args, cmd.Run())
+}
+
+// Output runs a command and captures its standard output
+func (ExecRunner) Output(name string, args ...string) ([]byte, error) {
+	out, err := exec.Command(name, args...).Output()
+	return out, exitError(name, args, err)
+}
+
+// LookPath reports whether a command is in PATH
+func (ExecRunner) LookPath(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// exitError converts a non-zero exit into an ExitError
+func exitError(name string, args []string, err error) error {
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return &ExitError{Command: strings.Join(append([]string{name}, args...), " "), Code: exit.ExitCode()}
+	}
+	return err
+}
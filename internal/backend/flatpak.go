@@ -0,0 +1,89 @@
+package backend
+
+import "strings"
+
+// flathub is the remote flatpaks are installed from by default. It is
+// added when missing, as distributions do not all configure it.
+const (
+	flathub    = "flathub"
+	flathubURL = "https://dl.flathub.org/repo/flathub.flatpakrepo"
+)
+
+// flatpak manages flatpak applications. Packages are application IDs
+// such as com.slack.Slack, installed system-wide unless Package.User is
+// set.
+type flatpak struct{ base }
+
+func newFlatpak(r Runner, opts Options) Backend { return &flatpak{base{r, opts}} }
+
+func (b *flatpak) Name() string { return "flatpak" }
+
+func (b *flatpak) Detect(osName string) bool {
+	return osName == "linux" && b.runner.LookPath("flatpak")
+}
+
+func (b *flatpak) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion finds the application in the installation of its
+// scope; applications without a version report ""
+func (b *flatpak) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("flatpak", "list", scope(pkg), "--columns=application,version")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		id, version, _ := strings.Cut(line, "\t")
+		if id == pkg.Name {
+			return strings.TrimSpace(version), nil
+		}
+	}
+	return "", ErrNotInstalled
+}
+
+// Install installs an application from its remote, adding Flathub first
+// when it is the remote
+func (b *flatpak) Install(pkg Package) error {
+	remote := pkg.Remote
+	if remote == "" {
+		remote = flathub
+	}
+	if remote == flathub {
+		if err := b.run(pkg, "remote-add", scope(pkg), "--if-not-exists", flathub, flathubURL); err != nil {
+			return err
+		}
+	}
+	return b.run(pkg, args([]string{"install", scope(pkg)}, b.unless("-y"), []string{remote, pkg.Name})...)
+}
+
+func (b *flatpak) Upgrade(pkg Package) error {
+	return b.run(pkg, args([]string{"update", scope(pkg)}, b.unless("-y"), []string{pkg.Name})...)
+}
+
+func (b *flatpak) Uninstall(pkg Package) error {
+	return b.run(pkg, args([]string{"uninstall", scope(pkg)}, b.unless("-y"), []string{pkg.Name})...)
+}
+
+// RefreshIndex does nothing: flatpak fetches remote metadata when it
+// installs
+func (b *flatpak) RefreshIndex() error { return nil }
+
+// AddRepository adds a system-wide remote from a .flatpakrepo URL
+func (b *flatpak) AddRepository(repo Repository) error {
+	return b.sudo("flatpak", "remote-add", "--system", "--if-not-exists", repo.Name, repo.URL)
+}
+
+// run runs flatpak, as root only for system-wide installs
+func (b *flatpak) run(pkg Package, args ...string) error {
+	if pkg.User {
+		return b.runner.Run("flatpak", args...)
+	}
+	return b.sudo("flatpak", args...)
+}
+
+// scope returns the flag selecting a package's installation
+func scope(pkg Package) string {
+	if pkg.User {
+		return "--user"
+	}
+	return "--system"
+}
@@ -0,0 +1,67 @@
+package backend
+
+import "strings"
+
+// gem installs Ruby gems
+type gem struct{ base }
+
+func newGem(r Runner, opts Options) Backend { return &gem{base{r, opts}} }
+
+func (b *gem) Name() string { return "gem" }
+
+func (b *gem) Detect(string) bool { return b.runner.LookPath("gem") }
+
+func (b *gem) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion reads the newest local version from gem list, which
+// prints "name (version, older...)" and nothing for missing gems
+func (b *gem) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("gem", "list", "--local", "--exact", pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		name, versions, ok := strings.Cut(strings.TrimSpace(line), " (")
+		if !ok || name != pkg.Name {
+			continue
+		}
+		version, _, _ := strings.Cut(strings.TrimSuffix(versions, ")"), ",")
+		return strings.TrimPrefix(version, "default: "), nil
+	}
+	return "", ErrNotInstalled
+}
+
+// Install installs the gem, at its pinned version if any, without
+// generating documentation
+func (b *gem) Install(pkg Package) error {
+	return b.runner.Run("gem", args([]string{"install", pkg.Name, "--no-document"}, gemVersion(pkg))...)
+}
+
+// Upgrade updates the gem, or installs its pinned version
+func (b *gem) Upgrade(pkg Package) error {
+	if pkg.Version != "" {
+		return b.Install(pkg)
+	}
+	return b.runner.Run("gem", "update", pkg.Name, "--no-document")
+}
+
+// Uninstall removes every version of the gem and its executables, which gem
+// otherwise asks about
+func (b *gem) Uninstall(pkg Package) error {
+	return b.runner.Run("gem", args([]string{"uninstall", pkg.Name}, b.unless("--all", "--executables"))...)
+}
+
+// RefreshIndex does nothing: gem queries its sources when it installs
+func (b *gem) RefreshIndex() error { return nil }
+
+// AddRepository adds a gem source
+func (b *gem) AddRepository(repo Repository) error {
+	return b.runner.Run("gem", "sources", "--add", repo.URL)
+}
+
+func gemVersion(pkg Package) []string {
+	if pkg.Version == "" {
+		return nil
+	}
+	return []string{"--version", pkg.Version}
+}
@@ -0,0 +1,91 @@
+package backend
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// goInstall installs Go commands, as go install. Packages are package
+// paths such as golang.org/x/tools/gopls.
+type goInstall struct{ base }
+
+func newGo(r Runner, opts Options) Backend { return &goInstall{base{r, opts}} }
+
+func (b *goInstall) Name() string { return "go" }
+
+func (b *goInstall) Detect(string) bool { return b.runner.LookPath("go") }
+
+func (b *goInstall) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion reads the module version built into the installed
+// binary, which go version -m fails to read when there is none
+func (b *goInstall) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("go", "env", "GOBIN", "GOPATH", "GOEXE")
+	if err != nil {
+		return "", err
+	}
+	// One line per variable; GOBIN and GOEXE are usually empty
+	env := strings.Split(strings.ReplaceAll(out, "\r\n", "\n"), "\n")
+	if len(env) < 3 {
+		return "", ErrNotInstalled
+	}
+	gobin, gopath, goexe := strings.TrimSpace(env[0]), strings.TrimSpace(env[1]), strings.TrimSpace(env[2])
+	if gobin == "" {
+		gobin = filepath.Join(filepath.SplitList(gopath)[0], "bin")
+	}
+
+	info, err := b.query("go", "version", "-m", filepath.Join(gobin, goBinary(pkg.Name)+goexe))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(info, "\n") {
+		if fields := strings.Fields(line); len(fields) >= 3 && fields[0] == "mod" {
+			return strings.TrimPrefix(fields[2], "v"), nil
+		}
+	}
+	return "", nil
+}
+
+// Install builds the command at its pinned version, or the latest; go does
+// not prompt
+func (b *goInstall) Install(pkg Package) error {
+	return b.runner.Run("go", "install", pkg.Name+"@"+goVersion(pkg.Version))
+}
+
+func (b *goInstall) Upgrade(pkg Package) error { return b.Install(pkg) }
+
+// Uninstall is not supported: go has no command that removes an installed
+// binary
+func (b *goInstall) Uninstall(Package) error { return ErrUnsupported }
+
+// RefreshIndex does nothing: the module proxy is queried when installing
+func (b *goInstall) RefreshIndex() error { return nil }
+
+// AddRepository is not supported: proxies are set with GOPROXY
+func (b *goInstall) AddRepository(Repository) error { return ErrUnsupported }
+
+// majorSuffix matches the major version element of a module path
+var majorSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// goBinary returns the name go install gives a package's binary: the last
+// path element, skipping a major version suffix
+func goBinary(path string) string {
+	elements := strings.Split(path, "/")
+	if n := len(elements); n > 1 && majorSuffix.MatchString(elements[n-1]) {
+		return elements[n-2]
+	}
+	return elements[len(elements)-1]
+}
+
+// goVersion returns a module query for a version: versions such as 1.2.3
+// need their v prefix, and no version means the latest
+func goVersion(version string) string {
+	switch {
+	case version == "":
+		return "latest"
+	case version[0] >= '0' && version[0] <= '9':
+		return "v" + version
+	}
+	return version
+}
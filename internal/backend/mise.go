@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// mise installs language runtimes and sets their global version. Packages
+// are mise tools such as node or python; the version may be a prefix.
+type mise struct{ base }
+
+func newMise(r Runner, opts Options) Backend { return &mise{base{r, opts}} }
+
+func (b *mise) Name() string { return "mise" }
+
+func (b *mise) Detect(string) bool { return b.runner.LookPath("mise") }
+
+func (b *mise) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion returns the active installed version, or the newest
+// installed one when none is active
+func (b *mise) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("mise", "ls", "--installed", "--json", pkg.Name)
+	if err != nil {
+		return "", err
+	}
+
+	var versions []struct {
+		Version string `json:"version"`
+		Active  bool   `json:"active"`
+	}
+	if err := json.Unmarshal([]byte(out), &versions); err != nil {
+		return "", fmt.Errorf("unexpected mise ls output: %w", err)
+	}
+	if len(versions) == 0 {
+		return "", ErrNotInstalled
+	}
+	for _, v := range versions {
+		if v.Active {
+			return v.Version, nil
+		}
+	}
+	return versions[len(versions)-1].Version, nil
+}
+
+// Install installs the requested version, or the latest, and makes it the
+// global one
+func (b *mise) Install(pkg Package) error {
+	return b.runner.Run("mise", args([]string{"use", "--global"}, b.unless("--yes"), []string{pkg.Name + "@" + latest(pkg.Version)})...)
+}
+
+// Upgrade installs the newest version matching the requested one
+func (b *mise) Upgrade(pkg Package) error {
+	return b.runner.Run("mise", args([]string{"upgrade"}, b.unless("--yes"), []string{pkg.Name})...)
+}
+
+// Uninstall removes the runtime from the global config and uninstalls it
+func (b *mise) Uninstall(pkg Package) error {
+	return b.runner.Run("mise", args([]string{"unuse", "--global"}, b.unless("--yes"), []string{pkg.Name})...)
+}
+
+// RefreshIndex does nothing: mise lists versions when it resolves one
+func (b *mise) RefreshIndex() error { return nil }
+
+// AddRepository installs a plugin from a repository URL
+func (b *mise) AddRepository(repo Repository) error {
+	return b.runner.Run("mise", args([]string{"plugins", "install"}, b.unless("--yes"), []string{repo.Name, repo.URL})...)
+}
+
+// ShimDir returns mise's shims directory in its data directory
+func (b *mise) ShimDir() string {
+	if dir := os.Getenv("MISE_DATA_DIR"); dir != "" {
+		return filepath.Join(dir, "shims")
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("LOCALAPPDATA"), "mise", "shims")
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "mise", "shims")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share", "mise", "shims")
+}
+
+// latest returns a version, or latest when none is requested
+func latest(version string) string {
+	if version == "" {
+		return "latest"
+	}
+	return version
+}
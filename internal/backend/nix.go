@@ -0,0 +1,108 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// nix manages packages in the user's nix profile. Packages are flake
+// references such as nixpkgs#ripgrep; bare names are taken from nixpkgs.
+type nix struct{ base }
+
+func newNix(r Runner, opts Options) Backend { return &nix{base{r, opts}} }
+
+func (b *nix) Name() string { return "nix" }
+
+func (b *nix) Detect(osName string) bool {
+	return (osName == "linux" || osName == "darwin") && b.runner.LookPath("nix")
+}
+
+func (b *nix) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// nixElement is an entry of nix profile list --json
+type nixElement struct {
+	AttrPath   string   `json:"attrPath"`
+	StorePaths []string `json:"storePaths"`
+}
+
+// InstalledVersion finds the package in the profile and reads the version
+// from its store path, /nix/store/<hash>-name-version
+func (b *nix) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("nix", nixArgs("profile", "list", "--json")...)
+	if err != nil {
+		return "", err
+	}
+
+	// Elements are keyed by name since nix 2.20 and a list before
+	var list struct {
+		Elements json.RawMessage `json:"elements"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return "", fmt.Errorf("unexpected nix profile list output: %w", err)
+	}
+	elements := map[string]nixElement{}
+	if err := json.Unmarshal(list.Elements, &elements); err != nil {
+		var old []nixElement
+		if err := json.Unmarshal(list.Elements, &old); err != nil {
+			return "", fmt.Errorf("unexpected nix profile list output: %w", err)
+		}
+		for i, element := range old {
+			elements[fmt.Sprint(i)] = element
+		}
+	}
+
+	name := nixName(pkg.Name)
+	for key, element := range elements {
+		if key != name && !strings.HasSuffix(element.AttrPath, "."+name) {
+			continue
+		}
+		for _, path := range element.StorePaths {
+			base := path[strings.LastIndex(path, "/")+1:]
+			if _, rest, ok := strings.Cut(base, "-"+name+"-"); ok {
+				return rest, nil
+			}
+		}
+		return "", nil
+	}
+	return "", ErrNotInstalled
+}
+
+// Install adds a package to the profile; nix does not prompt
+func (b *nix) Install(pkg Package) error {
+	return b.runner.Run("nix", nixArgs("profile", "install", nixRef(pkg.Name))...)
+}
+
+func (b *nix) Upgrade(pkg Package) error {
+	return b.runner.Run("nix", nixArgs("profile", "upgrade", nixName(pkg.Name))...)
+}
+
+func (b *nix) Uninstall(pkg Package) error {
+	return b.runner.Run("nix", nixArgs("profile", "remove", nixName(pkg.Name))...)
+}
+
+// RefreshIndex does nothing: flake references are fetched when used
+func (b *nix) RefreshIndex() error { return nil }
+
+// AddRepository registers a flake, so packages can be named name#package
+func (b *nix) AddRepository(repo Repository) error {
+	return b.runner.Run("nix", nixArgs("registry", "add", repo.Name, repo.URL)...)
+}
+
+// nixArgs enables the commands nix still marks experimental
+func nixArgs(command ...string) []string {
+	return append([]string{"--extra-experimental-features", "nix-command flakes"}, command...)
+}
+
+// nixRef returns the flake reference of a package
+func nixRef(pkg string) string {
+	if strings.Contains(pkg, "#") {
+		return pkg
+	}
+	return "nixpkgs#" + pkg
+}
+
+// nixName returns the name a package has in the profile
+func nixName(pkg string) string {
+	return pkg[strings.Index(pkg, "#")+1:]
+}
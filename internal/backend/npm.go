@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// npm installs global Node.js packages, as npm install -g
+type npm struct{ base }
+
+func newNPM(r Runner, opts Options) Backend { return &npm{base{r, opts}} }
+
+func (b *npm) Name() string { return "npm" }
+
+func (b *npm) Detect(string) bool { return b.runner.LookPath("npm") }
+
+func (b *npm) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion reads the global package tree; npm ls exits non-zero
+// when the package is missing
+func (b *npm) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("npm", "ls", "-g", "--json", "--depth=0", pkg.Name)
+	if err != nil {
+		return "", err
+	}
+
+	var tree struct {
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal([]byte(out), &tree); err != nil {
+		return "", fmt.Errorf("unexpected npm ls output: %w", err)
+	}
+	dep, ok := tree.Dependencies[pkg.Name]
+	if !ok {
+		return "", ErrNotInstalled
+	}
+	return dep.Version, nil
+}
+
+// Install installs the package globally, at its pinned version if any.
+// npm does not prompt.
+func (b *npm) Install(pkg Package) error {
+	return b.runner.Run("npm", "install", "-g", npmSpec(pkg, ""))
+}
+
+// Upgrade installs the latest or pinned version
+func (b *npm) Upgrade(pkg Package) error {
+	return b.runner.Run("npm", "install", "-g", npmSpec(pkg, "latest"))
+}
+
+func (b *npm) Uninstall(pkg Package) error { return b.runner.Run("npm", "uninstall", "-g", pkg.Name) }
+
+// RefreshIndex does nothing: npm queries the registry when it installs
+func (b *npm) RefreshIndex() error { return nil }
+
+// AddRepository is not supported: registries are npm configuration
+func (b *npm) AddRepository(Repository) error { return ErrUnsupported }
+
+// npmSpec returns name@version, with fallback when no version is pinned
+func npmSpec(pkg Package, fallback string) string {
+	version := pkg.Version
+	if version == "" {
+		version = fallback
+	}
+	if version == "" {
+		return pkg.Name
+	}
+	return pkg.Name + "@" + version
+}
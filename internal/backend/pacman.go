@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"strings"
+	"time"
+)
+
+// pacman manages Arch Linux packages
+type pacman struct{ base }
+
+func newPacman(r Runner, opts Options) Backend { return &pacman{base{r, opts}} }
+
+func (b *pacman) Name() string { return "pacman" }
+
+func (b *pacman) Detect(osName string) bool {
+	return osName == "linux" && b.runner.LookPath("pacman")
+}
+
+func (b *pacman) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+func (b *pacman) InstalledVersion(pkg Package) (string, error) {
+	// pacman -Q prints the package name and version
+	out, err := b.query("pacman", "-Q", pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	return field(out, 1), nil
+}
+
+func (b *pacman) Install(pkg Package) error { return b.InstallAll([]Package{pkg}) }
+
+func (b *pacman) InstallAll(pkgs []Package) error {
+	return b.sudo("pacman", args([]string{"-S"}, b.unless("--noconfirm"), names(pkgs))...)
+}
+
+// Upgrade syncs the package again, which installs its latest version
+func (b *pacman) Upgrade(pkg Package) error {
+	return b.Install(pkg)
+}
+
+func (b *pacman) Uninstall(pkg Package) error {
+	return b.sudo("pacman", args([]string{"-R"}, b.unless("--noconfirm"), []string{pkg.Name})...)
+}
+
+func (b *pacman) RefreshIndex() error { return b.sudo("pacman", "-Sy") }
+
+// pacmanSync holds the repository databases pacman -Sy downloads
+var pacmanSync = "/var/lib/pacman/sync"
+
+func (b *pacman) IndexUpdated() (time.Time, error) { return newestFile(pacmanSync) }
+
+// AddRepository is not supported: pacman repositories are sections of
+// pacman.conf
+func (b *pacman) AddRepository(Repository) error { return ErrUnsupported }
+
+// field returns the nth whitespace-separated field of the first line of
+// out, or "" when there are fewer
+func field(out string, n int) string {
+	line, _, _ := strings.Cut(out, "\n")
+	fields := strings.Fields(line)
+	if n >= len(fields) {
+		return ""
+	}
+	return fields[n]
+}
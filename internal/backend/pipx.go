@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// pipx installs Python applications into isolated environments
+type pipx struct{ base }
+
+func newPipx(r Runner, opts Options) Backend { return &pipx{base{r, opts}} }
+
+func (b *pipx) Name() string { return "pipx" }
+
+func (b *pipx) Detect(string) bool { return b.runner.LookPath("pipx") }
+
+func (b *pipx) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion reads the package's environment from pipx list --json
+func (b *pipx) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("pipx", "list", "--json")
+	if err != nil {
+		return "", err
+	}
+
+	var list struct {
+		Venvs map[string]struct {
+			Metadata struct {
+				MainPackage struct {
+					Version string `json:"package_version"`
+				} `json:"main_package"`
+			} `json:"metadata"`
+		} `json:"venvs"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return "", fmt.Errorf("unexpected pipx list output: %w", err)
+	}
+	venv, ok := list.Venvs[pkg.Name]
+	if !ok {
+		return "", ErrNotInstalled
+	}
+	return venv.Metadata.MainPackage.Version, nil
+}
+
+// Install installs the package, pinned with a requirement specifier
+func (b *pipx) Install(pkg Package) error {
+	return b.runner.Run("pipx", "install", pipxSpec(pkg))
+}
+
+// Upgrade upgrades the package, or reinstalls it at its pinned version
+func (b *pipx) Upgrade(pkg Package) error {
+	if pkg.Version != "" {
+		return b.runner.Run("pipx", "install", "--force", pipxSpec(pkg))
+	}
+	return b.runner.Run("pipx", "upgrade", pkg.Name)
+}
+
+func (b *pipx) Uninstall(pkg Package) error { return b.runner.Run("pipx", "uninstall", pkg.Name) }
+
+// RefreshIndex does nothing: pip queries the index when it installs
+func (b *pipx) RefreshIndex() error { return nil }
+
+// AddRepository is not supported: indexes are pip configuration
+func (b *pipx) AddRepository(Repository) error { return ErrUnsupported }
+
+func pipxSpec(pkg Package) string {
+	if pkg.Version == "" {
+		return pkg.Name
+	}
+	return pkg.Name + "==" + pkg.Version
+}
@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"fmt"
+
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+// Factory creates a backend that runs its commands with r
+type Factory func(r Runner, opts Options) Backend
+
+// registration is a registered backend
+type registration struct {
+	name    string
+	factory Factory
+}
+
+// registry holds the backends in detection order: on a system with several
+// managers, the first detected is the default. Distribution managers come
+// first, as snap, flatpak, brew and nix can be added to any of them; dnf
+// comes before yum, which is an alias for it on newer systems.
+var registry []registration
+
+func init() {
+	Register("apt", newAPT)
+	Register("dnf", newDNF)
+	Register("yum", newYum)
+	Register("pacman", newPacman)
+	Register("zypper", newZypper)
+	Register("apk", newAPK)
+	Register("xbps", newXBPS)
+	Register("emerge", newEmerge)
+	Register("snap", newSnap)
+	Register("flatpak", newFlatpak)
+	Register("brew", newBrew)
+	Register("nix", newNix)
+	Register("winget", newWinget)
+	Register("choco", newChoco)
+
+	Register("pipx", newPipx)
+	Register("npm", newNPM)
+	Register("cargo", newCargo)
+	Register("go", newGo)
+	Register("gem", newGem)
+	Register("mise", newMise)
+	Register("asdf", newAsdf)
+}
+
+// Register adds a backend, or replaces the backend registered under name
+// keeping its place in the detection order
+func Register(name string, factory Factory) {
+	for i := range registry {
+		if registry[i].name == name {
+			registry[i].factory = factory
+			return
+		}
+	}
+	registry = append(registry, registration{name: name, factory: factory})
+}
+
+// Names returns the registered backends in detection order
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, reg := range registry {
+		names[i] = reg.name
+	}
+	return names
+}
+
+// New creates the backend registered under name
+func New(name string, r Runner, opts Options) (Backend, error) {
+	for _, reg := range registry {
+		if reg.name == name {
+			return reg.factory(r, opts), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported package manager: %s", name)
+}
+
+// Detect returns the system backends available on a system running osName,
+// in detection order. Language managers are left out: they are only used
+// for the tools that name them.
+func Detect(osName string, r Runner) []string {
+	var found []string
+	for _, reg := range registry {
+		if domain.IsLanguagePackageManager(reg.name) {
+			continue
+		}
+		if reg.factory(r, Options{}).Detect(osName) {
+			found = append(found, reg.name)
+		}
+	}
+	return found
+}
@@ -0,0 +1,230 @@
+package backend
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/araldhafeeri/stackup/pkg/version"
+)
+
+// managedHeader starts the files written for repositories, so they are
+// recognizable in the system's configuration
+const managedHeader = "# Managed by stackup\n"
+
+// fetch downloads a repository file or signing key; tests replace it
+var fetch = func(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "stackup/"+version.Version)
+
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// signingKey downloads a repository's signing key and checks that every key
+// in it has the pinned fingerprint, so a replaced key, or one appended to
+// the file, is never trusted
+func signingKey(repo Repository) ([]byte, error) {
+	key, err := fetch(repo.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download signing key: %w", err)
+	}
+
+	fingerprints, err := keyFingerprints(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key %s: %w", repo.Key, err)
+	}
+	want := normalizeFingerprint(repo.Fingerprint)
+	for _, fingerprint := range fingerprints {
+		if fingerprint != want {
+			return nil, fmt.Errorf("signing key %s has fingerprint %s, want %s", repo.Key, fingerprint, want)
+		}
+	}
+	return key, nil
+}
+
+// normalizeFingerprint upper-cases a fingerprint and drops the spaces it is
+// usually printed with
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(fingerprint), ""))
+}
+
+// isArmored reports whether a key is ASCII-armored rather than binary
+func isArmored(key []byte) bool {
+	return bytes.Contains(key, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----"))
+}
+
+// keyFingerprints returns the fingerprints of the primary keys in an
+// OpenPGP public key file, armored or binary. Subkeys are covered by their
+// primary key's signature, so they are not listed.
+func keyFingerprints(data []byte) ([]string, error) {
+	if isArmored(data) {
+		var err error
+		if data, err = dearmor(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var fingerprints []string
+	for len(data) > 0 {
+		tag, body, rest, err := nextPacket(data)
+		if err != nil {
+			return nil, err
+		}
+		data = rest
+
+		// Tag 6 is a public key; subkeys, user IDs and signatures follow it
+		if tag != 6 {
+			continue
+		}
+		if len(body) == 0 {
+			return nil, errors.New("empty public key packet")
+		}
+		switch body[0] {
+		case 4:
+			sum := sha1.Sum(slices.Concat([]byte{0x99}, binary.BigEndian.AppendUint16(nil, uint16(len(body))), body))
+			fingerprints = append(fingerprints, strings.ToUpper(hex.EncodeToString(sum[:])))
+		case 6:
+			sum := sha256.Sum256(slices.Concat([]byte{0x9b}, binary.BigEndian.AppendUint32(nil, uint32(len(body))), body))
+			fingerprints = append(fingerprints, strings.ToUpper(hex.EncodeToString(sum[:])))
+		default:
+			return nil, fmt.Errorf("unsupported key version %d", body[0])
+		}
+	}
+
+	if len(fingerprints) == 0 {
+		return nil, errors.New("no public key found")
+	}
+	return fingerprints, nil
+}
+
+// dearmor decodes the first ASCII-armored block, skipping its headers and
+// checksum
+func dearmor(data []byte) ([]byte, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	start := slices.IndexFunc(lines, func(line string) bool { return strings.HasPrefix(line, "-----BEGIN PGP") })
+	if start < 0 {
+		return nil, errors.New("no armored block")
+	}
+
+	var encoded strings.Builder
+	for _, line := range lines[start+1:] {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "-----END PGP"):
+			return base64.StdEncoding.DecodeString(encoded.String())
+		case line == "", strings.Contains(line, ":"), strings.HasPrefix(line, "="):
+			// Headers, the line ending them and the CRC24 checksum; base64
+			// has no colons and only ends lines with =
+		default:
+			encoded.WriteString(line)
+		}
+	}
+	return nil, errors.New("unterminated armored block")
+}
+
+// nextPacket splits the first OpenPGP packet off data, in either the old or
+// the new packet format
+func nextPacket(data []byte) (tag byte, body, rest []byte, err error) {
+	malformed := errors.New("malformed packet")
+	if data[0]&0x80 == 0 {
+		return 0, nil, nil, malformed
+	}
+
+	var length, offset int
+	if data[0]&0x40 == 0 {
+		tag = data[0] >> 2 & 0x0f
+		switch data[0] & 0x03 {
+		case 0:
+			if len(data) < 2 {
+				return 0, nil, nil, malformed
+			}
+			length, offset = int(data[1]), 2
+		case 1:
+			if len(data) < 3 {
+				return 0, nil, nil, malformed
+			}
+			length, offset = int(binary.BigEndian.Uint16(data[1:])), 3
+		case 2:
+			if len(data) < 5 {
+				return 0, nil, nil, malformed
+			}
+			length, offset = int(binary.BigEndian.Uint32(data[1:])), 5
+		default:
+			// Indeterminate length runs to the end of the data
+			length, offset = len(data)-1, 1
+		}
+	} else {
+		tag = data[0] & 0x3f
+		if len(data) < 2 {
+			return 0, nil, nil, malformed
+		}
+		switch first := int(data[1]); {
+		case first < 192:
+			length, offset = first, 2
+		case first < 224:
+			if len(data) < 3 {
+				return 0, nil, nil, malformed
+			}
+			length, offset = (first-192)<<8+int(data[2])+192, 3
+		case first == 255:
+			if len(data) < 6 {
+				return 0, nil, nil, malformed
+			}
+			length, offset = int(binary.BigEndian.Uint32(data[2:])), 6
+		default:
+			// Partial lengths only appear in data packets, never in keys
+			return 0, nil, nil, errors.New("unexpected partial-length packet")
+		}
+	}
+
+	if length < 0 || offset+length > len(data) {
+		return 0, nil, nil, malformed
+	}
+	return tag, data[offset : offset+length], data[offset+length:], nil
+}
+
+// writeFile installs data as a root-owned file at path unless it already
+// has that content
+func (b base) writeFile(path string, data []byte) error {
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp("", "stackup-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return b.sudo("install", "-D", "-m", "0644", tmp.Name(), path)
+}
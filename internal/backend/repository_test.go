@@ -0,0 +1,193 @@
+package backend
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testKey is an ed25519 public key generated for these tests
+const testKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: StackUp Test <test@example.com>
+
+mDMEatXtghYJKwYBBAHaRw8BAQdAvFIteFgcV/H/ev0nKdMMCNq1eNvk0equbxzp
+sagxb+O0H1N0YWNrVXAgVGVzdCA8dGVzdEBleGFtcGxlLmNvbT6IkAQTFggAOBYh
+BI3MYvIcCgIdPWi7zwQeKiRWbVU8BQJq1e2CAhsDBQsJCAcCBhUKCQgLAgQWAgMB
+Ah4BAheAAAoJEAQeKiRWbVU8BEQA/AyGqcRDY9/JlnVZs18prGpl/gldMY6Vim3K
+GUtbq9J7AP9ZF+glAdpGWS5QgnMAB6/Q+7FNRSvMI69GsBUvhmfnDA==
+=X2Oq
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+const testFingerprint = "8DCC 62F2 1C0A 021D 3D68  BBCF 041E 2A24 566D 553C"
+
+func TestKeyFingerprints(t *testing.T) {
+	armored := strings.Split(testKey, "\n")
+	binary, err := base64.StdEncoding.DecodeString(strings.Join(armored[3:8], ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string][]byte{"armored": []byte(testKey), "binary": binary} {
+		got, err := keyFingerprints(data)
+		if err != nil || len(got) != 1 || got[0] != normalizeFingerprint(testFingerprint) {
+			t.Errorf("%s: keyFingerprints() = %v, %v; want %s", name, got, err, testFingerprint)
+		}
+	}
+
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"truncated": binary[:20],
+		"not a key": []byte("<html>Not Found</html>"),
+	} {
+		if got, err := keyFingerprints(data); err == nil {
+			t.Errorf("%s: keyFingerprints() = %v, want an error", name, got)
+		}
+	}
+}
+
+// repositoryDirs points the repository directories at a temporary directory
+func repositoryDirs(t *testing.T) string {
+	dir := t.TempDir()
+	for _, v := range []*string{&aptSources, &aptKeyrings, &yumRepos, &zyppRepos, &rpmKeys} {
+		old := *v
+		t.Cleanup(func() { *v = old })
+	}
+	aptSources, aptKeyrings = filepath.Join(dir, "sources.list.d"), filepath.Join(dir, "keyrings")
+	yumRepos, zyppRepos, rpmKeys = filepath.Join(dir, "yum.repos.d"), filepath.Join(dir, "zypp"), filepath.Join(dir, "rpm-gpg")
+
+	oldFetch := fetch
+	t.Cleanup(func() { fetch = oldFetch })
+	fetch = func(url string) ([]byte, error) {
+		switch url {
+		case "https://example.com/key.asc":
+			return []byte(testKey), nil
+		case "https://example.com/vendor.repo":
+			return []byte("[vendor]\nbaseurl=https://example.com/rpm\n"), nil
+		}
+		return nil, errors.New("404 Not Found")
+	}
+	return dir
+}
+
+func TestAPTRepository(t *testing.T) {
+	dir := repositoryDirs(t)
+	repo := Repository{
+		Name:        "docker",
+		URL:         "https://download.docker.com/linux/ubuntu",
+		Suites:      []string{"noble"},
+		Components:  []string{"stable"},
+		Key:         "https://example.com/key.asc",
+		Fingerprint: testFingerprint,
+	}
+
+	runner := &fakeRunner{}
+	b, _ := New("apt", runner, Options{AsRoot: true})
+	if err := b.AddRepository(repo); err != nil {
+		t.Fatal(err)
+	}
+	keyring, source := filepath.Join(dir, "keyrings", "docker.asc"), filepath.Join(dir, "sources.list.d", "docker.sources")
+	want := "install -D -m 0644 $TMP " + keyring + "; install -D -m 0644 $TMP " + source
+	if got := strings.Join(runner.calls, "; "); got != want {
+		t.Errorf("AddRepository() ran %q, want %q", got, want)
+	}
+
+	// Once the files are in place, adding the repository again changes nothing
+	wantSource := "# Managed by stackup\nTypes: deb\nURIs: https://download.docker.com/linux/ubuntu\nSuites: noble\nComponents: stable\nSigned-By: " + keyring + "\n"
+	for path, data := range map[string]string{keyring: testKey, source: wantSource} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runner.calls = nil
+	if err := b.AddRepository(repo); err != nil || len(runner.calls) != 0 {
+		t.Errorf("AddRepository() again ran %q, %v; want nothing", runner.calls, err)
+	}
+
+	repo.Fingerprint = "0000 0000 0000 0000 0000  0000 0000 0000 0000 0000"
+	if err := b.AddRepository(repo); err == nil || !strings.Contains(err.Error(), "has fingerprint 8DCC62F21C0A021D3D68BBCF041E2A24566D553C") {
+		t.Errorf("AddRepository() with another fingerprint error = %v", err)
+	}
+	if err := b.AddRepository(Repository{Name: "docker", URL: repo.URL}); err == nil {
+		t.Error("AddRepository() without suites succeeded")
+	}
+}
+
+func TestRPMRepository(t *testing.T) {
+	dir := repositoryDirs(t)
+	runner := &fakeRunner{}
+	b, _ := New("zypper", runner, Options{AsRoot: true})
+	repo := Repository{Name: "hashicorp", URL: "https://example.com/rpm", Key: "https://example.com/key.asc", Fingerprint: testFingerprint}
+	if err := b.AddRepository(repo); err != nil {
+		t.Fatal(err)
+	}
+
+	key, file := filepath.Join(dir, "rpm-gpg", "RPM-GPG-KEY-hashicorp"), filepath.Join(dir, "zypp", "hashicorp.repo")
+	want := "install -D -m 0644 $TMP " + key + "; rpm --import " + key + "; install -D -m 0644 $TMP " + file
+	if got := strings.Join(runner.calls, "; "); got != want {
+		t.Errorf("AddRepository() ran %q, want %q", got, want)
+	}
+
+	// A .repo file is written as downloaded
+	b, _ = New("dnf", runner, Options{AsRoot: true})
+	vendor := filepath.Join(dir, "yum.repos.d", "vendor.repo")
+	if err := os.MkdirAll(filepath.Dir(vendor), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(vendor, []byte("# Managed by stackup\n[vendor]\nbaseurl=https://example.com/rpm\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runner.calls = nil
+	if err := b.AddRepository(Repository{Name: "vendor", URL: "https://example.com/vendor.repo"}); err != nil || len(runner.calls) != 0 {
+		t.Errorf("AddRepository(.repo) ran %q, %v; want nothing", runner.calls, err)
+	}
+
+	if err := b.AddRepository(Repository{Name: "missing", URL: "https://example.com/missing.repo"}); err == nil {
+		t.Error("AddRepository() with a missing .repo file succeeded")
+	}
+}
+
+func TestRepositoryFiles(t *testing.T) {
+	dir := repositoryDirs(t)
+	for _, tt := range []struct {
+		manager string
+		repo    Repository
+		file    string
+		want    string
+	}{
+		{"apt", Repository{Name: "flat", URL: "https://example.com/debian", Suites: []string{"./"}}, "sources.list.d/flat.sources",
+			"# Managed by stackup\nTypes: deb\nURIs: https://example.com/debian\nSuites: ./\n"},
+		{"apt", Repository{Name: "main", URL: "https://example.com/debian", Suites: []string{"bookworm", "bookworm-updates"}}, "sources.list.d/main.sources",
+			"# Managed by stackup\nTypes: deb\nURIs: https://example.com/debian\nSuites: bookworm bookworm-updates\nComponents: main\n"},
+		{"dnf", Repository{Name: "hashicorp", URL: "https://example.com/rpm", Key: "https://example.com/key.asc", Fingerprint: testFingerprint}, "yum.repos.d/hashicorp.repo",
+			"# Managed by stackup\n[hashicorp]\nname=hashicorp\nbaseurl=https://example.com/rpm\nenabled=1\ngpgcheck=1\ngpgkey=file://" + filepath.ToSlash(filepath.Join(dir, "rpm-gpg", "RPM-GPG-KEY-hashicorp")) + "\n"},
+		{"zypper", Repository{Name: "unsigned", URL: "https://example.com/rpm"}, "zypp/unsigned.repo",
+			"# Managed by stackup\n[unsigned]\nname=unsigned\nbaseurl=https://example.com/rpm\nenabled=1\nautorefresh=1\ntype=rpm-md\ngpgcheck=0\n"},
+	} {
+		// Write what the backend would install, then check adding it is a no-op
+		path := filepath.Join(dir, filepath.FromSlash(tt.file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(tt.want), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		runner := &fakeRunner{}
+		b, _ := New(tt.manager, runner, Options{AsRoot: true})
+		if err := b.AddRepository(tt.repo); err != nil {
+			t.Errorf("%s AddRepository(%s) error: %v", tt.manager, tt.repo.Name, err)
+		}
+		for _, call := range runner.calls {
+			if strings.HasSuffix(call, path) {
+				t.Errorf("%s AddRepository(%s) rewrote %s; the expected content differs", tt.manager, tt.repo.Name, tt.file)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package backend
+
+import "strings"
+
+// snap manages snaps, which install the same way on most distributions
+type snap struct{ base }
+
+func newSnap(r Runner, opts Options) Backend { return &snap{base{r, opts}} }
+
+func (b *snap) Name() string { return "snap" }
+
+func (b *snap) Detect(osName string) bool {
+	return osName == "linux" && b.runner.LookPath("snap")
+}
+
+func (b *snap) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion reads the version column of snap list, which exits
+// non-zero for snaps that are not installed
+func (b *snap) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("snap", "list", pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	_, rows, _ := strings.Cut(out, "\n")
+	if version := field(rows, 1); version != "" {
+		return version, nil
+	}
+	return "", ErrNotInstalled
+}
+
+// Install installs a snap; snap does not prompt
+func (b *snap) Install(pkg Package) error {
+	return b.sudo("snap", append([]string{"install", pkg.Name}, snapFlags(pkg)...)...)
+}
+
+// Upgrade refreshes a snap, switching it to the configured channel
+func (b *snap) Upgrade(pkg Package) error {
+	return b.sudo("snap", append([]string{"refresh", pkg.Name}, snapFlags(pkg)...)...)
+}
+
+func (b *snap) Uninstall(pkg Package) error { return b.sudo("snap", "remove", pkg.Name) }
+
+// RefreshIndex does nothing: snaps are looked up in the store when
+// installed
+func (b *snap) RefreshIndex() error { return nil }
+
+// AddRepository is not supported: snaps come from the Snap Store only
+func (b *snap) AddRepository(Repository) error { return ErrUnsupported }
+
+// snapFlags returns the confinement and channel flags of a snap
+func snapFlags(pkg Package) []string {
+	var flags []string
+	if pkg.Classic {
+		flags = append(flags, "--classic")
+	}
+	if pkg.Channel != "" {
+		flags = append(flags, "--channel="+pkg.Channel)
+	}
+	return flags
+}
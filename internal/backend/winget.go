@@ -0,0 +1,58 @@
+package backend
+
+import "strings"
+
+// winget manages Windows packages with the Windows Package Manager
+type winget struct{ base }
+
+func newWinget(r Runner, opts Options) Backend { return &winget{base{r, opts}} }
+
+func (b *winget) Name() string { return "winget" }
+
+func (b *winget) Detect(osName string) bool {
+	return osName == "windows" && b.runner.LookPath("winget")
+}
+
+func (b *winget) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion reads the version column of winget list, which follows
+// the package ID. Names may contain spaces, so the ID is located first.
+func (b *winget) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("winget", "list", "-e", "--id", pkg.Name, "--accept-source-agreements")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		for i := 0; i+1 < len(fields); i++ {
+			if strings.EqualFold(fields[i], pkg.Name) {
+				return fields[i+1], nil
+			}
+		}
+	}
+	return "", ErrNotInstalled
+}
+
+func (b *winget) Install(pkg Package) error {
+	return b.runner.Run("winget", args([]string{"install", "-e", "--id", pkg.Name}, b.agreements())...)
+}
+
+func (b *winget) Upgrade(pkg Package) error {
+	return b.runner.Run("winget", args([]string{"upgrade", "-e", "--id", pkg.Name}, b.agreements())...)
+}
+
+func (b *winget) Uninstall(pkg Package) error {
+	return b.runner.Run("winget", args([]string{"uninstall", "-e", "--id", pkg.Name}, b.unless("--accept-source-agreements"))...)
+}
+
+func (b *winget) RefreshIndex() error { return b.runner.Run("winget", "source", "update") }
+
+func (b *winget) AddRepository(repo Repository) error {
+	return b.runner.Run("winget", args([]string{"source", "add", "--name", repo.Name, "--arg", repo.URL}, b.unless("--accept-source-agreements"))...)
+}
+
+// agreements accepts the package and source agreements winget otherwise
+// asks about
+func (b *winget) agreements() []string {
+	return b.unless("--accept-package-agreements", "--accept-source-agreements")
+}
@@ -0,0 +1,47 @@
+package backend
+
+import "strings"
+
+// xbps manages Void Linux packages
+type xbps struct{ base }
+
+func newXBPS(r Runner, opts Options) Backend { return &xbps{base{r, opts}} }
+
+func (b *xbps) Name() string { return "xbps" }
+
+func (b *xbps) Detect(osName string) bool {
+	return osName == "linux" && b.runner.LookPath("xbps-install")
+}
+
+func (b *xbps) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+// InstalledVersion reads the package's pkgver, name-version_revision
+func (b *xbps) InstalledVersion(pkg Package) (string, error) {
+	out, err := b.query("xbps-query", "-p", "pkgver", pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	version, ok := strings.CutPrefix(strings.TrimSpace(out), pkg.Name+"-")
+	if !ok {
+		return "", ErrNotInstalled
+	}
+	return version, nil
+}
+
+func (b *xbps) Install(pkg Package) error {
+	return b.sudo("xbps-install", args(b.unless("-y"), []string{pkg.Name})...)
+}
+
+func (b *xbps) Upgrade(pkg Package) error {
+	return b.sudo("xbps-install", args([]string{"-u"}, b.unless("-y"), []string{pkg.Name})...)
+}
+
+func (b *xbps) Uninstall(pkg Package) error {
+	return b.sudo("xbps-remove", args(b.unless("-y"), []string{pkg.Name})...)
+}
+
+func (b *xbps) RefreshIndex() error { return b.sudo("xbps-install", "-S") }
+
+// AddRepository is not supported: repositories are files in
+// /etc/xbps.d
+func (b *xbps) AddRepository(Repository) error { return ErrUnsupported }
@@ -0,0 +1,36 @@
+package backend
+
+// yum manages packages on CentOS 7 and older RHEL, which predate dnf
+type yum struct{ base }
+
+func newYum(r Runner, opts Options) Backend { return &yum{base{r, opts}} }
+
+func (b *yum) Name() string { return "yum" }
+
+func (b *yum) Detect(osName string) bool {
+	return osName == "linux" && b.runner.LookPath("yum")
+}
+
+func (b *yum) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+func (b *yum) InstalledVersion(pkg Package) (string, error) {
+	return rpmVersion(b.base, pkg.Name)
+}
+
+func (b *yum) Install(pkg Package) error {
+	return b.sudo("yum", args([]string{"install"}, b.unless("-y"), []string{pkg.Name})...)
+}
+
+func (b *yum) Upgrade(pkg Package) error {
+	return b.sudo("yum", args([]string{"update"}, b.unless("-y"), []string{pkg.Name})...)
+}
+
+func (b *yum) Uninstall(pkg Package) error {
+	return b.sudo("yum", args([]string{"remove"}, b.unless("-y"), []string{pkg.Name})...)
+}
+
+func (b *yum) RefreshIndex() error { return b.sudo("yum", "makecache") }
+
+func (b *yum) AddRepository(repo Repository) error {
+	return addRPMRepository(b.base, yumRepos, repo, "")
+}
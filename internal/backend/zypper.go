@@ -0,0 +1,37 @@
+package backend
+
+// zypper manages openSUSE and SLES packages
+type zypper struct{ base }
+
+func newZypper(r Runner, opts Options) Backend { return &zypper{base{r, opts}} }
+
+func (b *zypper) Name() string { return "zypper" }
+
+func (b *zypper) Detect(osName string) bool {
+	return osName == "linux" && b.runner.LookPath("zypper")
+}
+
+func (b *zypper) IsInstalled(pkg Package) (bool, error) { return isInstalled(b, pkg) }
+
+func (b *zypper) InstalledVersion(pkg Package) (string, error) {
+	return rpmVersion(b.base, pkg.Name)
+}
+
+func (b *zypper) Install(pkg Package) error { return b.zypper("install", pkg.Name) }
+
+func (b *zypper) Upgrade(pkg Package) error { return b.zypper("update", pkg.Name) }
+
+func (b *zypper) Uninstall(pkg Package) error { return b.zypper("remove", pkg.Name) }
+
+func (b *zypper) RefreshIndex() error { return b.zypper("refresh") }
+
+// AddRepository writes a repository zypper refreshes automatically
+func (b *zypper) AddRepository(repo Repository) error {
+	return addRPMRepository(b.base, zyppRepos, repo, "autorefresh=1\ntype=rpm-md\n")
+}
+
+// zypper runs a zypper command; --non-interactive is a global option and
+// goes before it
+func (b *zypper) zypper(command ...string) error {
+	return b.sudo("zypper", args(b.unless("--non-interactive"), command)...)
+}
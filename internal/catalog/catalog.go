@@ -0,0 +1,220 @@
+// Package catalog provides the built-in tool recipes that configs reference
+// with use and that new configs can be generated from. Local directories can
+// add recipes or override built-in ones.
+package catalog
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+// Version identifies the built-in recipes. It changes whenever a recipe
+// does, so configs behave the same across binaries with the same catalog.
+const Version = "2026.10.2"
+
+// PathEnv lists extra recipe directories, separated like PATH, searched
+// before the built-in recipes
+const PathEnv = "STACKUP_CATALOG_PATH"
+
+//go:embed tools/*.yaml
+var files embed.FS
+
+// extensions are the recipe file formats searched in local directories
+var extensions = []string{".yaml", ".yml", ".json", ".toml"}
+
+// recipeName is the syntax of a recipe name, which is also its file name
+var recipeName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Catalog finds recipes in local directories, in order, and then in the
+// built-in recipes
+type Catalog struct {
+	dirs []string
+}
+
+// New returns a catalog that searches dirs before the built-in recipes
+func New(dirs ...string) *Catalog {
+	return &Catalog{dirs: dirs}
+}
+
+// Default returns a catalog searching the directories in STACKUP_CATALOG_PATH
+// and the user's stackup/catalog config directory
+func Default() *Catalog {
+	return New(DefaultDirs()...)
+}
+
+// DefaultDirs returns the local recipe directories used by Default
+func DefaultDirs() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv(PathEnv)) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "stackup", "catalog"))
+	}
+	return dirs
+}
+
+// Entry describes a recipe in the catalog
+type Entry struct {
+	Name        string
+	Description string
+	Version     string // default tool version
+	File        string // where the recipe was read from
+}
+
+// Recipe returns the definition of a recipe and the file it was read from.
+// Built-in recipes are named catalog@<Version>/<name>.yaml.
+func (c *Catalog) Recipe(name string) ([]byte, string, error) {
+	if !recipeName.MatchString(name) {
+		return nil, "", fmt.Errorf("invalid recipe name %q", name)
+	}
+
+	for _, dir := range c.dirs {
+		for _, ext := range extensions {
+			file := filepath.Join(dir, name+ext)
+			data, err := os.ReadFile(file)
+			if err == nil {
+				return data, file, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, "", err
+			}
+		}
+	}
+
+	data, err := files.ReadFile(path.Join("tools", name+".yaml"))
+	if err != nil {
+		return nil, "", fmt.Errorf("unknown recipe %q", name)
+	}
+	return data, builtinFile(name), nil
+}
+
+// Tool returns a recipe decoded into a tool
+func (c *Catalog) Tool(name string) (config.Tool, error) {
+	data, file, err := c.Recipe(name)
+	if err != nil {
+		return config.Tool{}, err
+	}
+	return decode(name, data, file)
+}
+
+// List returns every recipe, sorted by name. Local recipes hide built-in
+// ones of the same name.
+func (c *Catalog) List() ([]Entry, error) {
+	names, err := c.names()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(names))
+	for _, name := range names {
+		data, file, err := c.Recipe(name)
+		if err != nil {
+			return nil, err
+		}
+		tool, err := decode(name, data, file)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{Name: name, Description: tool.Description, Version: tool.Version, File: file})
+	}
+	return entries, nil
+}
+
+// names returns the names of the recipes in every directory and the
+// built-in recipes
+func (c *Catalog) names() ([]string, error) {
+	seen := make(map[string]bool)
+
+	for _, dir := range c.dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			name := strings.TrimSuffix(entry.Name(), ext)
+			if !entry.IsDir() && slices.Contains(extensions, ext) && recipeName.MatchString(name) {
+				seen[name] = true
+			}
+		}
+	}
+
+	entries, err := files.ReadDir("tools")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		seen[strings.TrimSuffix(entry.Name(), ".yaml")] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// decode parses a recipe, whose name must match its file name
+func decode(name string, data []byte, file string) (config.Tool, error) {
+	tool, err := config.DecodeRecipe(data, file)
+	if err != nil {
+		return tool, err
+	}
+	if tool.Name != name {
+		return tool, fmt.Errorf("%s: name %q does not match the file name", file, tool.Name)
+	}
+	return tool, nil
+}
+
+func builtinFile(name string) string {
+	return fmt.Sprintf("catalog@%s/%s.yaml", Version, name)
+}
+
+// builtin holds only the built-in recipes
+var builtin = New()
+
+// Tools returns every built-in recipe as a tool, sorted by name
+func Tools() ([]config.Tool, error) {
+	entries, err := builtin.List()
+	if err != nil {
+		return nil, err
+	}
+
+	tools := make([]config.Tool, len(entries))
+	for i, entry := range entries {
+		if tools[i], err = builtin.Tool(entry.Name); err != nil {
+			return nil, err
+		}
+	}
+	return tools, nil
+}
+
+// Lookup returns a built-in recipe as a tool
+func Lookup(name string) (config.Tool, bool) {
+	tool, err := builtin.Tool(name)
+	return tool, err == nil
+}
+
+// Names returns the names of every built-in recipe, sorted
+func Names() []string {
+	names, _ := builtin.names()
+	return names
+}
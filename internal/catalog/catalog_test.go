@@ -0,0 +1,139 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/lint"
+)
+
+func TestToolsValidate(t *testing.T) {
+	tools, err := Tools()
+	if err != nil {
+		t.Fatalf("Tools() error: %v", err)
+	}
+	if len(tools) == 0 {
+		t.Fatal("Catalog is empty")
+	}
+
+	for _, tool := range tools {
+		t.Run(tool.Name, func(t *testing.T) {
+			if tool.Description == "" {
+				t.Error("Catalog tools need a description for the init wizard")
+			}
+			cfg := &config.Config{Profile: "catalog", Tools: []config.Tool{tool}}
+			if err := config.Validate(cfg); err != nil {
+				t.Errorf("Validate() error: %v", err)
+			}
+		})
+	}
+}
+
+func TestToolsLint(t *testing.T) {
+	tools, err := Tools()
+	if err != nil {
+		t.Fatalf("Tools() error: %v", err)
+	}
+
+	for _, tool := range tools {
+		t.Run(tool.Name, func(t *testing.T) {
+			cfg := &config.Config{Profile: "catalog", Tools: []config.Tool{tool}}
+			for _, finding := range lint.Run(cfg) {
+				t.Errorf("Lint finding: %s", finding)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tool, ok := Lookup("git")
+	if !ok {
+		t.Fatal("Lookup(git) not found")
+	}
+	if tool.VerifyCommand == "" {
+		t.Error("git should have a verify command")
+	}
+
+	if _, ok := Lookup("no-such-tool"); ok {
+		t.Error("Lookup() found an unknown tool")
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := Names()
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("Names() not sorted: %v", names)
+	}
+	if tools, _ := Tools(); len(names) != len(tools) {
+		t.Errorf("Names() has %d entries, want %d", len(names), len(tools))
+	}
+}
+
+func TestLocalOverrides(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	writeRecipe(t, first, "git.yaml", "name: git\nversion: \"2.45\"\ndescription: Pinned git\nverify_command: git --version\n")
+	writeRecipe(t, second, "git.yaml", "name: git\nversion: \"2.40\"\n")
+	writeRecipe(t, second, "internal-cli.toml", "name = \"internal-cli\"\nversion = \"1.2\"\ndescription = \"Our CLI\"\n")
+	writeRecipe(t, second, "README.md", "not a recipe")
+
+	c := New(first, second, filepath.Join(first, "missing"))
+
+	tool, err := c.Tool("git")
+	if err != nil {
+		t.Fatalf("Tool(git) error: %v", err)
+	}
+	if tool.Version != "2.45" {
+		t.Errorf("Version = %q, want the first directory's recipe", tool.Version)
+	}
+
+	if _, file, _ := c.Recipe("node"); file != "catalog@"+Version+"/node.yaml" {
+		t.Errorf("Recipe(node) file = %q, want the built-in recipe", file)
+	}
+
+	entries, err := c.List()
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	files := make(map[string]string)
+	for _, entry := range entries {
+		files[entry.Name] = entry.File
+	}
+	if files["git"] != filepath.Join(first, "git.yaml") || files["internal-cli"] != filepath.Join(second, "internal-cli.toml") {
+		t.Errorf("List() files = %v", files)
+	}
+	if len(entries) != len(Names())+1 {
+		t.Errorf("List() has %d entries, want the built-ins plus internal-cli", len(entries))
+	}
+}
+
+func TestRecipeErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeRecipe(t, dir, "misnamed.yaml", "name: other\nversion: \"1\"\n")
+
+	c := New(dir)
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "../etc/passwd", want: "invalid recipe name"},
+		{name: "nope", want: `unknown recipe "nope"`},
+		{name: "misnamed", want: "does not match the file name"},
+	}
+
+	for _, tt := range tests {
+		if _, err := c.Tool(tt.name); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Tool(%q) error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func writeRecipe(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
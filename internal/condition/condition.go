@@ -0,0 +1,258 @@
+// Package condition implements the small expression language used by `when:` fields
+package condition
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Expression is a parsed `when:` expression
+type Expression struct {
+	source string
+	root   node
+}
+
+// Parse compiles an expression, rejecting unknown facts and functions
+func Parse(source string) (*Expression, error) {
+	p := &parser{source: source}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+
+	if len(p.tokens) == 1 {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("col %d: unexpected %q", tok.pos+1, tok.text)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+// Evaluate parses and evaluates an expression in one step
+func Evaluate(source string, facts *Facts) (bool, error) {
+	expr, err := Parse(source)
+	if err != nil {
+		return false, err
+	}
+	return expr.Eval(facts), nil
+}
+
+// Eval evaluates the expression against the given facts
+func (e *Expression) Eval(facts *Facts) bool {
+	return e.root.eval(facts).truthy()
+}
+
+// String returns the original expression source
+func (e *Expression) String() string {
+	return e.source
+}
+
+// DependsOnlyOn reports whether the expression references no facts other
+// than the given ones and calls no functions that probe the host, so it can
+// be evaluated statically once those facts are known. is_distro counts as
+// a reference to distro.
+func (e *Expression) DependsOnlyOn(facts ...string) bool {
+	allowed := make(map[string]bool, len(facts))
+	for _, fact := range facts {
+		allowed[fact] = true
+	}
+	return dependsOnly(e.root, allowed)
+}
+
+func dependsOnly(n node, allowed map[string]bool) bool {
+	switch n := n.(type) {
+	case literalNode:
+		return true
+	case factNode:
+		return allowed[n.name]
+	case callNode:
+		return n.fn == "is_distro" && allowed["distro"]
+	case notNode:
+		return dependsOnly(n.operand, allowed)
+	case logicalNode:
+		return dependsOnly(n.left, allowed) && dependsOnly(n.right, allowed)
+	case compareNode:
+		return dependsOnly(n.left, allowed) && dependsOnly(n.right, allowed)
+	default:
+		return false
+	}
+}
+
+// value is the result of evaluating a node
+type value struct {
+	str    string
+	b      bool
+	isBool bool
+}
+
+func (v value) truthy() bool {
+	if v.isBool {
+		return v.b
+	}
+	return v.str != "" && v.str != "false" && v.str != "0"
+}
+
+func (v value) String() string {
+	if v.isBool {
+		if v.b {
+			return "true"
+		}
+		return "false"
+	}
+	return v.str
+}
+
+func boolValue(b bool) value {
+	return value{b: b, isBool: true}
+}
+
+// node is an element of the expression tree
+type node interface {
+	eval(facts *Facts) value
+}
+
+type literalNode struct {
+	val string
+}
+
+func (n literalNode) eval(*Facts) value {
+	return value{str: n.val}
+}
+
+type factNode struct {
+	name string
+}
+
+func (n factNode) eval(facts *Facts) value {
+	return value{str: facts.lookup(n.name)}
+}
+
+type callNode struct {
+	fn  string
+	arg string
+}
+
+func (n callNode) eval(facts *Facts) value {
+	switch n.fn {
+	case "file_exists":
+		return boolValue(facts.fileExists(n.arg))
+	case "command_exists":
+		return boolValue(facts.commandExists(n.arg))
+	case "is_distro":
+		return boolValue(facts.isDistro(n.arg))
+	}
+	return boolValue(false)
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n notNode) eval(facts *Facts) value {
+	return boolValue(!n.operand.eval(facts).truthy())
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n logicalNode) eval(facts *Facts) value {
+	left := n.left.eval(facts).truthy()
+	if n.op == "&&" {
+		return boolValue(left && n.right.eval(facts).truthy())
+	}
+	return boolValue(left || n.right.eval(facts).truthy())
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(facts *Facts) value {
+	left := n.left.eval(facts).String()
+	right := n.right.eval(facts).String()
+
+	switch n.op {
+	case "==":
+		return boolValue(left == right)
+	case "!=":
+		return boolValue(left != right)
+	}
+
+	cmp := CompareVersions(left, right)
+	switch n.op {
+	case "<":
+		return boolValue(cmp < 0)
+	case "<=":
+		return boolValue(cmp <= 0)
+	case ">":
+		return boolValue(cmp > 0)
+	default:
+		return boolValue(cmp >= 0)
+	}
+}
+
+// CompareVersions compares two dotted version strings segment by segment,
+// numerically where both segments are numbers. It returns -1, 0 or 1.
+func CompareVersions(a, b string) int {
+	as := splitVersion(a)
+	bs := splitVersion(b)
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+
+		if c := compareSegment(x, y); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+func splitVersion(v string) []string {
+	return strings.FieldsFunc(v, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func compareSegment(x, y string) int {
+	if isNumber(x) && isNumber(y) {
+		x = strings.TrimLeft(x, "0")
+		y = strings.TrimLeft(y, "0")
+		if len(x) != len(y) {
+			if len(x) < len(y) {
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(x, y)
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package condition
+
+import (
+	"testing"
+)
+
+func testFacts() *Facts {
+	return &Facts{
+		Values: map[string]string{
+			"os":              "linux",
+			"arch":            "arm64",
+			"distro":          "ubuntu",
+			"distro_version":  "22.04",
+			"package_manager": "apt",
+			"libc":            "glibc",
+			"wsl":             "true",
+			"container":       "false",
+		},
+		DistroIDs: []string{"ubuntu", "debian"},
+		Getenv: func(key string) string {
+			if key == "WSL_DISTRO_NAME" {
+				return "Ubuntu"
+			}
+			return ""
+		},
+		FileExists: func(path string) bool {
+			return path == "/etc/wsl.conf"
+		},
+		CommandExists: func(name string) bool {
+			return name == "docker"
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected bool
+	}{
+		{"Equality", `os == "linux"`, true},
+		{"Inequality", `arch != "arm64"`, false},
+		{"Single Quotes", `distro == 'ubuntu'`, true},
+		{"And", `os == "linux" && arch == "arm64"`, true},
+		{"Or", `os == "windows" || arch == "arm64"`, true},
+		{"Not", `!(os == "windows")`, true},
+		{"Version Greater Equal", `distro_version >= "22.04"`, true},
+		{"Version Unquoted Number", `distro_version >= 20.10`, true},
+		{"Version Less Than", `distro_version < 22.10`, true},
+		{"Version Numeric Not Lexical", `distro_version > 9`, true},
+		{"Env Set", `env.WSL_DISTRO_NAME`, true},
+		{"Env Unset", `env.CI`, false},
+		{"Env Compare", `env.WSL_DISTRO_NAME == "Ubuntu"`, true},
+		{"File Exists", `file_exists("/etc/wsl.conf")`, true},
+		{"File Missing", `file_exists("/nonexistent")`, false},
+		{"Command Exists", `command_exists("docker")`, true},
+		{"Command Missing", `!command_exists("podman")`, true},
+		{"Boolean Literal", `true && !false`, true},
+		{"Boolean Fact", `wsl && !container`, true},
+		{"Libc", `libc == "glibc"`, true},
+		{"Is Distro Parent", `is_distro("debian")`, true},
+		{"Is Distro Other", `is_distro("fedora")`, false},
+		{"Precedence", `os == "windows" && arch == "amd64" || package_manager == "apt"`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.expr, testFacts())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Evaluate(%q) = %v, want %v", tt.expr, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"Empty", ""},
+		{"Unknown Fact", `distribution == "ubuntu"`},
+		{"Unknown Function", `path_exists("/tmp")`},
+		{"Unterminated String", `os == "linux`},
+		{"Single Equals", `os = "linux"`},
+		{"Missing Paren", `(os == "linux"`},
+		{"Trailing Token", `os == "linux" "extra"`},
+		{"Function Without String", `file_exists(os)`},
+		{"Bad Env Reference", `env.`},
+		{"Dangling Operator", `os == "linux" &&`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.expr); err == nil {
+				t.Errorf("Parse(%q) expected error", tt.expr)
+			}
+		})
+	}
+}
+
+func TestDependsOnlyOn(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{`os == "linux"`, true},
+		{`!(os == "windows") || os == "darwin"`, true},
+		{`os == "linux" && arch == "arm64"`, false},
+		{`os == "linux" && command_exists("docker")`, false},
+		{`true`, true},
+		{`is_distro("debian")`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+			}
+			if got := expr.DependsOnlyOn("os"); got != tt.expected {
+				t.Errorf("DependsOnlyOn(os) = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	expr, err := Parse(`os == "linux" && is_distro("debian")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expr.DependsOnlyOn("os", "distro") {
+		t.Error("is_distro should count as a reference to distro")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"22.04", "22.04", 0},
+		{"22.04", "20.10", 1},
+		{"9", "10", -1},
+		{"22", "22.04", -1},
+		{"1.2.3", "1.2.10", -1},
+		{"3.18", "3.9", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			if result := CompareVersions(tt.a, tt.b); result != tt.expected {
+				t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
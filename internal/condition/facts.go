@@ -0,0 +1,75 @@
+package condition
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+// Facts holds the values an expression is evaluated against
+type Facts struct {
+	Values        map[string]string
+	DistroIDs     []string
+	Getenv        func(string) string
+	FileExists    func(string) bool
+	CommandExists func(string) bool
+}
+
+// NewFacts builds the facts for a detected system, probing the host
+// filesystem, PATH and environment for functions and env.X references
+func NewFacts(sys *domain.System) *Facts {
+	return &Facts{
+		Values: map[string]string{
+			"os":              sys.OS,
+			"arch":            sys.Arch,
+			"distro":          sys.Distro,
+			"distro_version":  sys.DistroVersion,
+			"package_manager": sys.PackageManager,
+			"kernel_version":  sys.KernelVersion,
+			"libc":            sys.Libc,
+			"wsl":             strconv.FormatBool(sys.IsWSL),
+			"container":       strconv.FormatBool(sys.IsContainer),
+		},
+		DistroIDs: sys.DistroIDs(),
+		Getenv:    os.Getenv,
+		FileExists: func(path string) bool {
+			_, err := os.Stat(path)
+			return err == nil
+		},
+		CommandExists: func(name string) bool {
+			_, err := exec.LookPath(name)
+			return err == nil
+		},
+	}
+}
+
+// lookup resolves a fact or env.X reference
+func (f *Facts) lookup(name string) string {
+	if key, ok := strings.CutPrefix(name, "env."); ok {
+		if f.Getenv == nil {
+			return ""
+		}
+		return f.Getenv(key)
+	}
+	return f.Values[name]
+}
+
+func (f *Facts) fileExists(path string) bool {
+	return f.FileExists != nil && f.FileExists(path)
+}
+
+func (f *Facts) commandExists(name string) bool {
+	return f.CommandExists != nil && f.CommandExists(name)
+}
+
+func (f *Facts) isDistro(id string) bool {
+	for _, candidate := range f.DistroIDs {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package condition
+
+import (
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+func TestNewFacts(t *testing.T) {
+	sys := &domain.System{
+		OS:             "linux",
+		Arch:           "amd64",
+		PackageManager: "dnf",
+		Distro:         "fedora",
+		DistroVersion:  "40",
+	}
+
+	facts := NewFacts(sys)
+
+	expected := map[string]string{
+		"os":              "linux",
+		"arch":            "amd64",
+		"distro":          "fedora",
+		"distro_version":  "40",
+		"package_manager": "dnf",
+	}
+
+	for name, want := range expected {
+		if got := facts.lookup(name); got != want {
+			t.Errorf("lookup(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	t.Setenv("STACKUP_CONDITION_TEST", "yes")
+	if got := facts.lookup("env.STACKUP_CONDITION_TEST"); got != "yes" {
+		t.Errorf("lookup(env.STACKUP_CONDITION_TEST) = %q, want %q", got, "yes")
+	}
+
+	if !facts.fileExists(t.TempDir()) {
+		t.Error("fileExists should report existing directory")
+	}
+
+	if facts.commandExists("this-command-does-not-exist-12345") {
+		t.Error("commandExists should be false for missing command")
+	}
+}
+
+func TestNilProbes(t *testing.T) {
+	facts := &Facts{}
+
+	if facts.lookup("env.HOME") != "" {
+		t.Error("lookup without Getenv should be empty")
+	}
+
+	if facts.fileExists("/") || facts.commandExists("sh") {
+		t.Error("probes without functions should be false")
+	}
+}
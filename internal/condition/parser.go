@@ -0,0 +1,284 @@
+package condition
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Facts and functions that expressions may reference
+var (
+	knownFacts = map[string]bool{
+		"os":              true,
+		"arch":            true,
+		"distro":          true,
+		"distro_version":  true,
+		"package_manager": true,
+		"kernel_version":  true,
+		"libc":            true,
+		"wsl":             true,
+		"container":       true,
+	}
+
+	knownFunctions = map[string]bool{
+		"file_exists":    true,
+		"command_exists": true,
+		"is_distro":      true,
+	}
+
+	twoCharOperators = map[string]bool{
+		"==": true, "!=": true, "<=": true, ">=": true, "&&": true, "||": true,
+	}
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type parser struct {
+	source string
+	tokens []token
+	cursor int
+}
+
+// tokenize splits the source into tokens
+func (p *parser) tokenize() error {
+	src := p.source
+	i := 0
+
+	for i < len(src) {
+		c := src[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '(':
+			p.tokens = append(p.tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+
+		case c == ')':
+			p.tokens = append(p.tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return fmt.Errorf("col %d: unterminated string", i+1)
+			}
+			p.tokens = append(p.tokens, token{kind: tokenString, text: src[i+1 : i+1+end], pos: i})
+			i += end + 2
+
+		case strings.ContainsRune("=!<>&|", rune(c)):
+			op := string(c)
+			if i+1 < len(src) && twoCharOperators[src[i:i+2]] {
+				op = src[i : i+2]
+			}
+			if op == "=" || op == "&" || op == "|" {
+				return fmt.Errorf("col %d: unexpected %q", i+1, op)
+			}
+			p.tokens = append(p.tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+
+		case isDigit(c):
+			start := i
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenNumber, text: src[start:i], pos: start})
+
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && (isIdentPart(src[i]) || src[i] == '.') {
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenIdent, text: src[start:i], pos: start})
+
+		default:
+			return fmt.Errorf("col %d: unexpected character %q", i+1, c)
+		}
+	}
+
+	p.tokens = append(p.tokens, token{kind: tokenEOF, text: "end of expression", pos: len(src)})
+	return nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.cursor]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.cursor]
+	if tok.kind != tokenEOF {
+		p.cursor++
+	}
+	return tok
+}
+
+// parseOr handles `a || b`
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().text == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "||", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseAnd handles `a && b`
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().text == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "&&", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseUnary handles `!a`
+func (p *parser) parseUnary() (node, error) {
+	if tok := p.peek(); tok.kind == tokenOperator && tok.text == "!" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+
+	return p.parseComparison()
+}
+
+// parseComparison handles `a == b`, `a >= b` and friends
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	switch tok.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: tok.text, left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+// parsePrimary handles literals, facts, function calls and parentheses
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenString, tokenNumber:
+		return literalNode{val: tok.text}, nil
+
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("col %d: expected \")\", got %q", closing.pos+1, closing.text)
+		}
+		return inner, nil
+
+	case tokenIdent:
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(tok)
+		}
+		return p.parseFact(tok)
+	}
+
+	return nil, fmt.Errorf("col %d: unexpected %q", tok.pos+1, tok.text)
+}
+
+// parseFact resolves an identifier to a known fact
+func (p *parser) parseFact(tok token) (node, error) {
+	name := tok.text
+
+	if strings.HasPrefix(name, "env.") {
+		if len(name) == len("env.") || strings.Contains(name[len("env."):], ".") {
+			return nil, fmt.Errorf("col %d: invalid environment reference %q", tok.pos+1, name)
+		}
+		return factNode{name: name}, nil
+	}
+
+	if name == "true" || name == "false" {
+		return literalNode{val: name}, nil
+	}
+
+	if !knownFacts[name] {
+		return nil, fmt.Errorf("col %d: unknown fact %q", tok.pos+1, name)
+	}
+
+	return factNode{name: name}, nil
+}
+
+// parseCall parses a single-argument function call
+func (p *parser) parseCall(tok token) (node, error) {
+	if !knownFunctions[tok.text] {
+		return nil, fmt.Errorf("col %d: unknown function %q", tok.pos+1, tok.text)
+	}
+
+	p.next() // (
+
+	arg := p.next()
+	if arg.kind != tokenString {
+		return nil, fmt.Errorf("col %d: %s expects a quoted string argument", arg.pos+1, tok.text)
+	}
+
+	if closing := p.next(); closing.kind != tokenRParen {
+		return nil, fmt.Errorf("col %d: expected \")\", got %q", closing.pos+1, closing.text)
+	}
+
+	return callNode{fn: tok.text, arg: arg.text}, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
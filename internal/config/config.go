@@ -1,18 +1,87 @@
 // Package config handles configuration structures and management
 package config
 
+import (
+	"time"
+
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
 // Config represents the main application configuration
 type Config struct {
+	Include  []string          `yaml:"include,omitempty"` // resolved and cleared while loading
 	Profile  string            `yaml:"profile"`
 	Settings Settings          `yaml:"settings"`
 	Tools    []Tool            `yaml:"tools"`
 	Presets  map[string]Preset `yaml:"presets,omitempty"`
+
+	// Templates are reusable tool definitions referenced by Tool.Extends.
+	// They are expanded into the tools and cleared while loading.
+	Templates map[string]Tool `yaml:"templates,omitempty"`
+
+	// Vars are substituted for ${name} in every string of the tools. They
+	// are stored resolved.
+	Vars map[string]string `yaml:"vars,omitempty"`
+
+	// Secrets are read at install time and referenced as ${secret:name}
+	Secrets map[string]Secret `yaml:"secrets,omitempty"`
+
+	source  *sourceMap // where tools were defined, for error positions
+	files   []string   // every file read while loading
+	origins []string   // where each top-level config came from
 }
 
 // Settings contains global installation settings
 type Settings struct {
 	AutoUpdatePath      bool `yaml:"auto_update_path"`
 	VerifyInstallations bool `yaml:"verify_installations"`
+
+	// NonInteractive passes package managers their assume-yes flags, so
+	// unattended runs never wait for a confirmation
+	NonInteractive bool `yaml:"non_interactive,omitempty"`
+
+	// ManagerPriority orders the system package managers by preference.
+	// Tools use the first available manager their package_names map, and
+	// the first available one is the default.
+	ManagerPriority []string `yaml:"manager_priority,omitempty"`
+
+	// RefreshIndexes refreshes the package index of each manager used
+	// before installing; unset means true
+	RefreshIndexes *bool `yaml:"refresh_indexes,omitempty"`
+
+	// IndexMaxAge is how many minutes a refreshed package index counts as
+	// fresh; unset means 60
+	IndexMaxAge int `yaml:"index_max_age,omitempty"`
+}
+
+// ShouldRefreshIndexes reports whether package indexes are refreshed before
+// installing, which they are unless disabled
+func (s Settings) ShouldRefreshIndexes() bool {
+	return s.RefreshIndexes == nil || *s.RefreshIndexes
+}
+
+// DefaultIndexMaxAge is how long a package index counts as fresh when
+// settings.index_max_age is unset
+const DefaultIndexMaxAge = time.Hour
+
+// MaxIndexAge returns how long a refreshed package index counts as fresh
+func (s Settings) MaxIndexAge() time.Duration {
+	if s.IndexMaxAge <= 0 {
+		return DefaultIndexMaxAge
+	}
+	return time.Duration(s.IndexMaxAge) * time.Minute
+}
+
+// SystemPackageManagers are the managers settings.manager_priority may
+// list; language managers are only used by tools naming them
+func SystemPackageManagers() []string {
+	var managers []string
+	for _, manager := range domain.PackageManagers {
+		if !domain.IsLanguagePackageManager(manager) {
+			managers = append(managers, manager)
+		}
+	}
+	return managers
 }
 
 // Preset defines a named collection of tools
@@ -23,30 +92,91 @@ type Preset struct {
 
 // Tool represents a single installable tool
 type Tool struct {
-	Name            string          `yaml:"name"`
-	DisplayName     string          `yaml:"display_name,omitempty"`
-	Version         string          `yaml:"version"`
-	Description     string          `yaml:"description,omitempty"`
-	Manager         string          `yaml:"manager,omitempty"`
-	Windows         *PlatformConfig `yaml:"windows,omitempty"`
-	Linux           *PlatformConfig `yaml:"linux,omitempty"`
-	MacOS           *PlatformConfig `yaml:"macos,omitempty"`
-	PreInstall      []Command       `yaml:"pre_install,omitempty"`
-	CustomInstall   []Command       `yaml:"custom_install,omitempty"`
-	PostInstall     []Command       `yaml:"post_install,omitempty"`
-	VerifyCommand   string          `yaml:"verify_command,omitempty"`
-	RequiresReboot  bool            `yaml:"requires_reboot,omitempty"`
-	Dependencies    []string        `yaml:"dependencies,omitempty"`
+	Name           string          `yaml:"name"`
+	DisplayName    string          `yaml:"display_name,omitempty"`
+	Version        string          `yaml:"version"`
+	Description    string          `yaml:"description,omitempty"`
+	Manager        string          `yaml:"manager,omitempty"`
+	Windows        *PlatformConfig `yaml:"windows,omitempty"`
+	Linux          *PlatformConfig `yaml:"linux,omitempty"`
+	MacOS          *PlatformConfig `yaml:"macos,omitempty"`
+	PreInstall     []Command       `yaml:"pre_install,omitempty"`
+	CustomInstall  []Command       `yaml:"custom_install,omitempty"`
+	PostInstall    []Command       `yaml:"post_install,omitempty"`
+	VerifyCommand  string          `yaml:"verify_command,omitempty"`
+	RequiresReboot bool            `yaml:"requires_reboot,omitempty"`
+	Dependencies   []string        `yaml:"dependencies,omitempty"`
+	When           string          `yaml:"when,omitempty"`        // condition expression evaluated against the host
+	LintIgnore     []string        `yaml:"lint_ignore,omitempty"` // lint rule IDs or names suppressed for this tool
+
+	// Use names a catalog recipe to deep-merge under this tool; the tool's
+	// name defaults to the recipe's. It is consumed while loading.
+	Use string `yaml:"use,omitempty"`
+
+	// Extends names a template to deep-merge under this tool; Params fill
+	// its {{ placeholders }}. Both are consumed while loading.
+	Extends string            `yaml:"extends,omitempty"`
+	Params  map[string]string `yaml:"params,omitempty"`
 }
 
 // PlatformConfig contains platform-specific installation details
 type PlatformConfig struct {
 	Installer      string            `yaml:"installer,omitempty"`
+	SHA256         string            `yaml:"sha256,omitempty"` // expected checksum of the downloaded installer
 	Type           string            `yaml:"type,omitempty"`
 	SilentFlags    []string          `yaml:"silent_flags,omitempty"`
+	Headers        map[string]string `yaml:"headers,omitempty"` // HTTP headers sent when downloading the installer
 	PackageNames   map[string]string `yaml:"package_names,omitempty"`
 	Brew           string            `yaml:"brew,omitempty"`
+	Snap           *SnapOptions      `yaml:"snap,omitempty"`    // how package_names.snap is installed
+	Flatpak        *FlatpakOptions   `yaml:"flatpak,omitempty"` // how package_names.flatpak is installed
+	Repositories   []Repository      `yaml:"repositories,omitempty"`
 	CustomCommands []Command         `yaml:"custom_commands,omitempty"`
+	When           string            `yaml:"when,omitempty"`
+
+	// Distros holds distribution-specific overrides keyed by os-release ID
+	// (e.g. ubuntu, fedora, arch) that are merged over this section
+	Distros map[string]*PlatformConfig `yaml:",inline"`
+}
+
+// SnapOptions configure how a snap is installed
+type SnapOptions struct {
+	Classic bool   `yaml:"classic,omitempty"` // install without confinement
+	Channel string `yaml:"channel,omitempty"` // e.g. latest/edge
+}
+
+// FlatpakOptions configure how a flatpak is installed
+type FlatpakOptions struct {
+	Remote string `yaml:"remote,omitempty"` // defaults to flathub
+	Scope  string `yaml:"scope,omitempty"`  // system, the default, or user
+}
+
+// FlatpakScopes are the values of FlatpakOptions.Scope
+var FlatpakScopes = []string{"system", "user"}
+
+// Repository is a third-party repository added before the tool's package
+// is installed. Repositories are left in place; StackUp does not remove them.
+type Repository struct {
+	Name    string `yaml:"name"`              // file name, or user/repo for brew taps
+	Manager string `yaml:"manager,omitempty"` // defaults to the manager installing the tool
+	URL     string `yaml:"url,omitempty"`     // base URL, .repo file, PPA or tap remote
+
+	// Suites and Components of an apt repository; suites default to the
+	// distribution's codename and components to main
+	Suites     []string `yaml:"suites,omitempty"`
+	Components []string `yaml:"components,omitempty"`
+
+	Key         string `yaml:"key,omitempty"`         // URL of the signing key
+	Fingerprint string `yaml:"fingerprint,omitempty"` // fingerprint the signing key must have
+}
+
+// RepositoryManagers are the package managers repositories can be added to
+var RepositoryManagers = []string{
+	domain.PackageManagerAPT,
+	domain.PackageManagerDNF,
+	domain.PackageManagerYum,
+	domain.PackageManagerZypper,
+	domain.PackageManagerBrew,
 }
 
 // Command represents a command to execute
@@ -57,6 +187,25 @@ type Command struct {
 	Sudo        bool     `yaml:"sudo,omitempty"`
 	WaitFor     int      `yaml:"wait_for,omitempty"` // seconds to wait after command
 	IgnoreError bool     `yaml:"ignore_error,omitempty"`
+	When        string   `yaml:"when,omitempty"`
+
+	Env map[string]string `yaml:"env,omitempty"` // added to the command's environment
+}
+
+// InstallerTypes are the values of PlatformConfig.Type with a dedicated
+// install command. Other values run the download as a generic executable.
+var InstallerTypes = []string{"exe", "msi", "sh", "bash", "deb", "rpm", "dmg", "pkg", "appimage"}
+
+// Files returns the paths of every file the configuration was loaded from,
+// including included files, in the order they were read
+func (c *Config) Files() []string {
+	return c.files
+}
+
+// Origins describes where each top-level config was read from: a path,
+// stdin, or a URL noting whether a cached copy was used
+func (c *Config) Origins() []string {
+	return c.origins
 }
 
 // GetDisplayName returns the display name or falls back to name
@@ -68,9 +217,9 @@ func (t *Tool) GetDisplayName() string {
 
 	// fallback to tool name
 	if t.Name != "" {
-		return t.Name 
+		return t.Name
 	}
-	
+
 	return "ToolNameNotSet"
 }
 
@@ -86,4 +235,102 @@ func (t *Tool) GetPlatformConfig(osName string) *PlatformConfig {
 	default:
 		return nil
 	}
-}
\ No newline at end of file
+}
+
+// platformSection pairs a platform section with its YAML key
+type platformSection struct {
+	name string
+	cfg  *PlatformConfig
+}
+
+// platformSections returns the tool's platform sections in a fixed order
+func (t *Tool) platformSections() []platformSection {
+	return []platformSection{
+		{"windows", t.Windows},
+		{"linux", t.Linux},
+		{"macos", t.MacOS},
+	}
+}
+
+// PlatformConfigFor returns the platform configuration for an OS, with the
+// first matching distribution override merged over the generic section.
+// distroIDs should be ordered most specific first (ID, then ID_LIKE).
+// Tools installed by a language package manager work on every OS, with an
+// empty section where they have none.
+func (t *Tool) PlatformConfigFor(osName string, distroIDs ...string) *PlatformConfig {
+	base := t.GetPlatformConfig(osName)
+	if base == nil && domain.IsLanguagePackageManager(t.Manager) {
+		return &PlatformConfig{}
+	}
+	if base == nil || len(base.Distros) == 0 {
+		return base
+	}
+
+	for _, id := range distroIDs {
+		if override, ok := base.Distros[id]; ok && override != nil {
+			return base.Merge(override)
+		}
+	}
+
+	return base
+}
+
+// Merge returns a copy of the section with the non-empty fields of override
+// applied on top. Package names are merged by key; lists are replaced.
+func (p *PlatformConfig) Merge(override *PlatformConfig) *PlatformConfig {
+	merged := *p
+	merged.Distros = nil
+
+	if override.Installer != "" {
+		merged.Installer = override.Installer
+	}
+	if override.SHA256 != "" {
+		merged.SHA256 = override.SHA256
+	}
+	if override.Type != "" {
+		merged.Type = override.Type
+	}
+	if len(override.SilentFlags) > 0 {
+		merged.SilentFlags = override.SilentFlags
+	}
+	if override.Brew != "" {
+		merged.Brew = override.Brew
+	}
+	if override.Snap != nil {
+		merged.Snap = override.Snap
+	}
+	if override.Flatpak != nil {
+		merged.Flatpak = override.Flatpak
+	}
+	if len(override.Repositories) > 0 {
+		merged.Repositories = override.Repositories
+	}
+	if len(override.CustomCommands) > 0 {
+		merged.CustomCommands = override.CustomCommands
+	}
+	if override.When != "" {
+		merged.When = override.When
+	}
+
+	if len(override.Headers) > 0 {
+		merged.Headers = make(map[string]string, len(p.Headers)+len(override.Headers))
+		for key, val := range p.Headers {
+			merged.Headers[key] = val
+		}
+		for key, val := range override.Headers {
+			merged.Headers[key] = val
+		}
+	}
+
+	if len(override.PackageNames) > 0 {
+		merged.PackageNames = make(map[string]string, len(p.PackageNames)+len(override.PackageNames))
+		for manager, name := range p.PackageNames {
+			merged.PackageNames[manager] = name
+		}
+		for manager, name := range override.PackageNames {
+			merged.PackageNames[manager] = name
+		}
+	}
+
+	return &merged
+}
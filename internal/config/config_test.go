@@ -2,6 +2,9 @@ package config
 
 import (
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestToolGetDisplayName(t *testing.T) {
@@ -98,6 +101,98 @@ func TestToolGetPlatformConfig(t *testing.T) {
 	}
 }
 
+func TestToolPlatformConfigFor(t *testing.T) {
+	tool := Tool{
+		Name: "docker",
+		Linux: &PlatformConfig{
+			PackageNames: map[string]string{"apt": "docker.io", "dnf": "docker"},
+			Distros: map[string]*PlatformConfig{
+				"ubuntu": {PackageNames: map[string]string{"apt": "docker-ce"}},
+				"fedora": {PackageNames: map[string]string{"dnf": "moby-engine"}, When: `distro_version >= 39`},
+				"arch": {
+					CustomCommands: []Command{{Command: "pacman", Args: []string{"-S", "docker"}}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		osName    string
+		distroIDs []string
+		validate  func(*testing.T, *PlatformConfig)
+	}{
+		{
+			name:      "Exact Distro",
+			osName:    "linux",
+			distroIDs: []string{"ubuntu", "debian"},
+			validate: func(t *testing.T, cfg *PlatformConfig) {
+				if cfg.PackageNames["apt"] != "docker-ce" {
+					t.Errorf("PackageNames[apt] = %q, want %q", cfg.PackageNames["apt"], "docker-ce")
+				}
+				if cfg.PackageNames["dnf"] != "docker" {
+					t.Errorf("PackageNames[dnf] = %q, want generic %q", cfg.PackageNames["dnf"], "docker")
+				}
+				if cfg.Distros != nil {
+					t.Error("Merged config should not carry distro overrides")
+				}
+			},
+		},
+		{
+			name:      "ID_LIKE Fallback",
+			osName:    "linux",
+			distroIDs: []string{"nobara", "fedora"},
+			validate: func(t *testing.T, cfg *PlatformConfig) {
+				if cfg.PackageNames["dnf"] != "moby-engine" {
+					t.Errorf("PackageNames[dnf] = %q, want %q", cfg.PackageNames["dnf"], "moby-engine")
+				}
+				if cfg.When != `distro_version >= 39` {
+					t.Errorf("When = %q, want override condition", cfg.When)
+				}
+			},
+		},
+		{
+			name:      "Override Commands",
+			osName:    "linux",
+			distroIDs: []string{"arch"},
+			validate: func(t *testing.T, cfg *PlatformConfig) {
+				if len(cfg.CustomCommands) != 1 {
+					t.Errorf("len(CustomCommands) = %d, want 1", len(cfg.CustomCommands))
+				}
+			},
+		},
+		{
+			name:      "No Matching Distro",
+			osName:    "linux",
+			distroIDs: []string{"alpine"},
+			validate: func(t *testing.T, cfg *PlatformConfig) {
+				if cfg != tool.Linux {
+					t.Error("Expected the generic linux section")
+				}
+			},
+		},
+		{
+			name:   "Other OS",
+			osName: "darwin",
+			validate: func(t *testing.T, cfg *PlatformConfig) {
+				if cfg != nil {
+					t.Error("Expected nil for unconfigured OS")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.validate(t, tool.PlatformConfigFor(tt.osName, tt.distroIDs...))
+		})
+	}
+
+	if tool.Linux.PackageNames["apt"] != "docker.io" {
+		t.Error("PlatformConfigFor must not modify the generic section")
+	}
+}
+
 func TestCommandStructure(t *testing.T) {
 	cmd := Command{
 		Command:     "wsl",
@@ -128,3 +223,31 @@ func TestCommandStructure(t *testing.T) {
 		t.Error("IgnoreError should be false")
 	}
 }
+
+func TestSettingsShouldRefreshIndexes(t *testing.T) {
+	for _, tt := range []struct {
+		yaml string
+		want bool
+	}{
+		{"verify_installations: true", true},
+		{"refresh_indexes: true", true},
+		{"refresh_indexes: false", false},
+	} {
+		var settings Settings
+		if err := yaml.Unmarshal([]byte(tt.yaml), &settings); err != nil {
+			t.Fatal(err)
+		}
+		if got := settings.ShouldRefreshIndexes(); got != tt.want {
+			t.Errorf("%s: ShouldRefreshIndexes() = %v, want %v", tt.yaml, got, tt.want)
+		}
+	}
+}
+
+func TestSettingsMaxIndexAge(t *testing.T) {
+	if got := (Settings{}).MaxIndexAge(); got != time.Hour {
+		t.Errorf("MaxIndexAge() unset = %v, want 1h", got)
+	}
+	if got := (Settings{IndexMaxAge: 1440}).MaxIndexAge(); got != 24*time.Hour {
+		t.Errorf("MaxIndexAge() of 1440 = %v, want 24h", got)
+	}
+}
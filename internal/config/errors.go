@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Position locates a value in a configuration file
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String formats the position as file:line:column, omitting unknown parts
+func (p Position) String() string {
+	var parts []string
+	if p.File != "" {
+		parts = append(parts, p.File)
+	}
+	if p.Line > 0 {
+		parts = append(parts, strconv.Itoa(p.Line))
+		if p.Column > 0 {
+			parts = append(parts, strconv.Itoa(p.Column))
+		}
+	}
+	return strings.Join(parts, ":")
+}
+
+// Error is a single configuration problem with its location
+type Error struct {
+	Pos     Position
+	Context string // e.g. `tool "node"`
+	Msg     string
+}
+
+// Error formats as `file:line:col: tool "name": message`
+func (e *Error) Error() string {
+	var b strings.Builder
+	if pos := e.Pos.String(); pos != "" {
+		b.WriteString(pos)
+		b.WriteString(": ")
+	}
+	if e.Context != "" {
+		b.WriteString(e.Context)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Msg)
+	return b.String()
+}
+
+// Errors collects every problem found in a configuration
+type Errors []*Error
+
+// Error joins all errors, one per line
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap exposes the individual errors to errors.Is and errors.As
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// add appends a new error
+func (e *Errors) add(pos Position, context, format string, args ...interface{}) {
+	*e = append(*e, &Error{Pos: pos, Context: context, Msg: fmt.Sprintf(format, args...)})
+}
+
+// err returns nil when empty so callers can return it as an error
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// yamlLine matches the "line N: " prefix yaml.v3 puts on its messages
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// splitYAMLMessage extracts the line number from a yaml.v3 error message
+func splitYAMLMessage(msg string) (int, string) {
+	if m := yamlLine.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return line, m[2]
+	}
+	return 0, strings.TrimPrefix(msg, "yaml: ")
+}
+
+// closestMatch returns the candidate nearest to name by edit distance, or ""
+// when none is close enough to be a likely typo
+func closestMatch(name string, candidates []string) string {
+	best, bestDist := "", -1
+	for _, candidate := range candidates {
+		dist := levenshtein(name, candidate)
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+
+	limit := len(name) / 4
+	if limit < 2 {
+		limit = 2
+	}
+	if bestDist < 0 || bestDist > limit {
+		return ""
+	}
+	return best
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
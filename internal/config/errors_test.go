@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *Error
+		expected string
+	}{
+		{
+			name:     "Full Position With Context",
+			err:      &Error{Pos: Position{File: "dev.yaml", Line: 23, Column: 5}, Context: `tool "node"`, Msg: "unknown field"},
+			expected: `dev.yaml:23:5: tool "node": unknown field`,
+		},
+		{
+			name:     "Line Without Column",
+			err:      &Error{Pos: Position{File: "dev.yaml", Line: 3}, Msg: "bad"},
+			expected: "dev.yaml:3: bad",
+		},
+		{
+			name:     "No Position",
+			err:      &Error{Msg: "no tools defined in configuration"},
+			expected: "no tools defined in configuration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.expected {
+				t.Errorf("Error() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestErrorsJoinAndUnwrap(t *testing.T) {
+	var errs Errors
+	if errs.err() != nil {
+		t.Fatal("empty Errors should convert to a nil error")
+	}
+
+	errs.add(Position{File: "a.yaml", Line: 1, Column: 1}, "", "first")
+	errs.add(Position{File: "a.yaml", Line: 2, Column: 3}, `tool "git"`, "second %d", 2)
+
+	expected := "a.yaml:1:1: first\na.yaml:2:3: tool \"git\": second 2"
+	if got := errs.Error(); got != expected {
+		t.Errorf("Error() = %q, want %q", got, expected)
+	}
+
+	var single *Error
+	if !errors.As(errs.err(), &single) || single.Msg != "first" {
+		t.Errorf("errors.As should find the first *Error, got %v", single)
+	}
+}
+
+func TestSplitYAMLMessage(t *testing.T) {
+	line, msg := splitYAMLMessage("line 12: cannot unmarshal !!str `x` into int")
+	if line != 12 || msg != "cannot unmarshal !!str `x` into int" {
+		t.Errorf("splitYAMLMessage() = %d, %q", line, msg)
+	}
+
+	line, msg = splitYAMLMessage("yaml: did not find expected key")
+	if line != 0 || msg != "did not find expected key" {
+		t.Errorf("splitYAMLMessage() = %d, %q", line, msg)
+	}
+}
+
+func TestClosestMatch(t *testing.T) {
+	candidates := []string{"post_install", "package_names", "verify_command", "dependencies"}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"post_instal", "post_install"},
+		{"packge_names", "package_names"},
+		{"verfy_command", "verify_command"},
+		{"dependancies", "dependencies"},
+		{"homepage", ""},
+		{"x", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := closestMatch(tt.name, candidates); got != tt.expected {
+				t.Errorf("closestMatch(%q) = %q, want %q", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"post_instal", "post_install", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.expected {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/araldhafeeri/stackup/pkg/version"
+)
+
+// isRemote reports whether a config path is an HTTP(S) URL
+func isRemote(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// Fetcher downloads remote configuration files. Responses are cached on disk
+// and revalidated with their ETag, so a run without network access falls
+// back to the last copy fetched.
+type Fetcher struct {
+	Client   *http.Client
+	CacheDir string // empty disables caching
+}
+
+// NewFetcher creates a fetcher caching under the user cache directory
+func NewFetcher() *Fetcher {
+	f := &Fetcher{Client: &http.Client{Timeout: 30 * time.Second}}
+	if dir, err := os.UserCacheDir(); err == nil {
+		f.CacheDir = filepath.Join(dir, "stackup", "configs")
+	}
+	return f
+}
+
+// Fetched is a downloaded config and how it was obtained
+type Fetched struct {
+	Data      []byte
+	FromCache bool      // the server confirmed the cached copy, or was unreachable
+	Offline   bool      // the server was unreachable and the cached copy was used
+	FetchedAt time.Time // when the content was last downloaded
+}
+
+// cacheEntry is stored next to each cached body
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// Fetch downloads url, revalidating any cached copy
+func (f *Fetcher) Fetch(url string) (*Fetched, error) {
+	cached, entry := f.readCache(url)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "stackup/"+version.Version)
+	if cached != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if cached != nil {
+			return &Fetched{Data: cached, FromCache: true, Offline: true, FetchedAt: entry.FetchedAt}, nil
+		}
+		return nil, fmt.Errorf("failed to fetch config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return &Fetched{Data: cached, FromCache: true, FetchedAt: entry.FetchedAt}, nil
+
+	case resp.StatusCode == http.StatusOK:
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch config: %w", err)
+		}
+		entry = cacheEntry{
+			URL:          url,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    time.Now().UTC(),
+		}
+		f.writeCache(entry, data)
+		return &Fetched{Data: data, FetchedAt: entry.FetchedAt}, nil
+
+	case resp.StatusCode >= 500 && cached != nil:
+		return &Fetched{Data: cached, FromCache: true, Offline: true, FetchedAt: entry.FetchedAt}, nil
+
+	default:
+		return nil, fmt.Errorf("failed to fetch config: bad status: %s", resp.Status)
+	}
+}
+
+// cachePath returns the body path for a URL; metadata lives beside it
+func (f *Fetcher) cachePath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(f.CacheDir, hex.EncodeToString(sum[:]))
+}
+
+// readCache returns the cached body for url, or nil when there is none
+func (f *Fetcher) readCache(url string) ([]byte, cacheEntry) {
+	var entry cacheEntry
+	if f.CacheDir == "" {
+		return nil, entry
+	}
+
+	path := f.cachePath(url)
+	meta, err := os.ReadFile(path + ".json")
+	if err != nil || json.Unmarshal(meta, &entry) != nil || entry.URL != url {
+		return nil, entry
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, entry
+	}
+	return data, entry
+}
+
+// writeCache stores a response. Failures only cost the offline fallback, so
+// they are ignored.
+func (f *Fetcher) writeCache(entry cacheEntry, data []byte) {
+	if f.CacheDir == "" {
+		return
+	}
+	if err := os.MkdirAll(f.CacheDir, 0755); err != nil {
+		return
+	}
+
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	path := f.cachePath(entry.URL)
+	if os.WriteFile(path, data, 0644) == nil {
+		os.WriteFile(path+".json", meta, 0644)
+	}
+}
+
+// verifySHA256 checks content against a pinned hex digest
+func verifySHA256(data []byte, expected string) error {
+	sum := sha256.Sum256(data)
+	actual := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("sha256 mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// configServer serves files with ETags and counts full responses
+type configServer struct {
+	files map[string]string
+	sent  int
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	content, ok := s.files[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	s.sent++
+	w.Header().Set("ETag", etag)
+	w.Write([]byte(content))
+}
+
+func TestFetcherRevalidatesCache(t *testing.T) {
+	srv := &configServer{files: map[string]string{"/team.yaml": "profile: team\n"}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	f := &Fetcher{Client: server.Client(), CacheDir: t.TempDir()}
+	url := server.URL + "/team.yaml"
+
+	first, err := f.Fetch(url)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	if first.FromCache || string(first.Data) != "profile: team\n" {
+		t.Errorf("First fetch = %+v", first)
+	}
+
+	second, err := f.Fetch(url)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	if !second.FromCache || second.Offline || string(second.Data) != "profile: team\n" {
+		t.Errorf("Second fetch should be revalidated from cache, got %+v", second)
+	}
+
+	srv.files["/team.yaml"] = "profile: changed\n"
+	third, err := f.Fetch(url)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	if third.FromCache || string(third.Data) != "profile: changed\n" {
+		t.Errorf("Changed content should be downloaded, got %+v", third)
+	}
+
+	if srv.sent != 2 {
+		t.Errorf("Server sent %d full responses, want 2", srv.sent)
+	}
+}
+
+func TestFetcherOfflineFallback(t *testing.T) {
+	srv := &configServer{files: map[string]string{"/team.yaml": "profile: team\n"}}
+	server := httptest.NewServer(srv)
+
+	f := &Fetcher{Client: server.Client(), CacheDir: t.TempDir()}
+	url := server.URL + "/team.yaml"
+
+	if _, err := f.Fetch(url); err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	server.Close()
+
+	fetched, err := f.Fetch(url)
+	if err != nil {
+		t.Fatalf("Fetch() without network should use the cache: %v", err)
+	}
+	if !fetched.Offline || string(fetched.Data) != "profile: team\n" {
+		t.Errorf("Offline fetch = %+v", fetched)
+	}
+
+	uncached := &Fetcher{Client: server.Client(), CacheDir: t.TempDir()}
+	if _, err := uncached.Fetch(url); err == nil {
+		t.Error("Fetch() without network or cache should fail")
+	}
+}
+
+func TestFetcherBadStatus(t *testing.T) {
+	server := httptest.NewServer(&configServer{})
+	defer server.Close()
+
+	f := &Fetcher{Client: server.Client()}
+	_, err := f.Fetch(server.URL + "/missing.yaml")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+}
+
+func TestLoadRemote(t *testing.T) {
+	srv := &configServer{files: map[string]string{
+		"/configs/team.yaml": "include: base.toml\nprofile: team\n",
+		"/configs/base.toml": "[[tools]]\nname = \"git\"\nversion = \"latest\"\n",
+		"/configs/glob.yaml": "include: \"*.yaml\"\n",
+	}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	opts := LoadOptions{Fetcher: &Fetcher{Client: server.Client(), CacheDir: t.TempDir()}}
+	url := server.URL + "/configs/team.yaml"
+
+	cfg, err := Load(opts, url)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Profile != "team" || len(cfg.Tools) != 1 || cfg.Tools[0].Name != "git" {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if origins := cfg.Origins(); len(origins) != 1 || origins[0] != url {
+		t.Errorf("Origins() = %v, want [%s]", origins, url)
+	}
+
+	cfg, err = Load(opts, url)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if origins := cfg.Origins(); origins[0] != url+" (cached, unchanged)" {
+		t.Errorf("Origins() = %v, want cached origin", origins)
+	}
+
+	if _, err := Load(opts, server.URL+"/configs/glob.yaml"); err == nil || !strings.Contains(err.Error(), "globs are not supported") {
+		t.Errorf("Expected glob error, got %v", err)
+	}
+
+	if _, err := LoadFiles(url); err == nil || !strings.Contains(err.Error(), "remote configs are not supported") {
+		t.Errorf("Expected remote configs to be rejected without a fetcher, got %v", err)
+	}
+}
+
+func TestLoadStdin(t *testing.T) {
+	opts := LoadOptions{Format: FormatJSON, Stdin: strings.NewReader(`{"profile": "piped"}`)}
+
+	cfg, err := Load(opts, "-")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Profile != "piped" {
+		t.Errorf("Profile = %q, want piped", cfg.Profile)
+	}
+	if origins := cfg.Origins(); len(origins) != 1 || origins[0] != "stdin" {
+		t.Errorf("Origins() = %v, want [stdin]", origins)
+	}
+
+	_, err = Load(LoadOptions{Stdin: strings.NewReader("tools:\n  - nme: git\n")}, "-")
+	if err == nil || !strings.HasPrefix(err.Error(), "<stdin>:2:") {
+		t.Errorf("Expected error positioned in <stdin>, got %v", err)
+	}
+}
+
+func TestLoadSHA256Pin(t *testing.T) {
+	content := "profile: pinned\n"
+	sum := sha256.Sum256([]byte(content))
+	pin := hex.EncodeToString(sum[:])
+
+	cfg, err := Load(LoadOptions{Stdin: strings.NewReader(content), SHA256: strings.ToUpper(pin)}, "-")
+	if err != nil {
+		t.Fatalf("Load() with matching pin error: %v", err)
+	}
+	if origins := cfg.Origins(); origins[0] != "stdin, sha256 verified" {
+		t.Errorf("Origins() = %v", origins)
+	}
+
+	_, err = Load(LoadOptions{Stdin: strings.NewReader("profile: tampered\n"), SHA256: pin}, "-")
+	if err == nil || !strings.Contains(err.Error(), "sha256 mismatch") {
+		t.Errorf("Expected sha256 mismatch, got %v", err)
+	}
+
+	if _, err := Load(LoadOptions{SHA256: pin}, "a.yaml", "b.yaml"); err == nil {
+		t.Error("A pin with several configs should be rejected")
+	}
+}
+
+func TestLoadSHA256PinRejectsRemoteIncludes(t *testing.T) {
+	srv := &configServer{files: map[string]string{
+		"/configs/team.yaml": "include: base.yaml\nprofile: team\n",
+		"/configs/base.yaml": "tools:\n  - name: git\n    version: latest\n",
+	}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	sum := sha256.Sum256([]byte(srv.files["/configs/team.yaml"]))
+	opts := LoadOptions{
+		Fetcher: &Fetcher{Client: server.Client(), CacheDir: t.TempDir()},
+		SHA256:  hex.EncodeToString(sum[:]),
+	}
+
+	_, err := Load(opts, server.URL+"/configs/team.yaml")
+	if err == nil || !strings.Contains(err.Error(), server.URL+"/configs/base.yaml is not covered by the sha256 pin") {
+		t.Errorf("Expected the remote include to be rejected, got %v", err)
+	}
+	if srv.sent != 1 {
+		t.Errorf("Fetched %d files, want only the pinned one", srv.sent)
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a configuration file syntax. Every format is parsed into the
+// same YAML node tree, so includes, merging, templates and error positions
+// work identically.
+type Format string
+
+// Supported formats. FormatAuto picks the format from the file extension.
+const (
+	FormatAuto Format = ""
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+)
+
+// Formats lists the supported formats
+var Formats = []Format{FormatYAML, FormatJSON, FormatTOML}
+
+// ParseFormat converts a --format flag value. An empty value means auto.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "":
+		return FormatAuto, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "json":
+		return FormatJSON, nil
+	case "toml":
+		return FormatTOML, nil
+	default:
+		return "", fmt.Errorf("unknown format %q: expected yaml, json or toml", name)
+	}
+}
+
+// FormatFromPath picks the format for a file by its extension, defaulting
+// to YAML
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatYAML
+	}
+}
+
+// resolve returns the format to read path with
+func (f Format) resolve(path string) Format {
+	if f == FormatAuto {
+		return FormatFromPath(path)
+	}
+	return f
+}
+
+// ParseDocument parses a document into its top-level mapping node. Empty
+// documents yield nil.
+func ParseDocument(data []byte, format Format) (*yaml.Node, error) {
+	var root *yaml.Node
+	var err error
+
+	switch format {
+	case FormatJSON:
+		root, err = parseJSON(data)
+	case FormatTOML:
+		root, err = parseTOML(data)
+	default:
+		root, err = parseYAML(data)
+	}
+	if err != nil || root == nil {
+		return nil, err
+	}
+
+	if root.Kind == yaml.ScalarNode && root.Tag == "!!null" {
+		return nil, nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, &Error{Pos: Position{Line: root.Line, Column: root.Column}, Msg: "top level must be a mapping"}
+	}
+
+	return root, nil
+}
+
+// parseYAML parses YAML into its root node
+func parseYAML(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		line, msg := splitYAMLMessage(err.Error())
+		return nil, &Error{Pos: Position{Line: line}, Msg: msg}
+	}
+
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return nil, nil
+	}
+	return resolveAlias(doc.Content[0]), nil
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatAuto, false},
+		{"yaml", FormatYAML, false},
+		{"YML", FormatYAML, false},
+		{"json", FormatJSON, false},
+		{"toml", FormatTOML, false},
+		{"ini", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]Format{
+		"stackup.yaml":    FormatYAML,
+		"stackup.yml":     FormatYAML,
+		"dev.JSON":        FormatJSON,
+		"teams/dev.toml":  FormatTOML,
+		"no-extension":    FormatYAML,
+		"dev.toml.backup": FormatYAML,
+	}
+
+	for path, want := range tests {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("FormatFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+const formatYAML = `profile: dev
+tools:
+  - name: git
+    version: latest
+    requires_reboot: true
+    linux:
+      package_names:
+        apt: git
+    post_install:
+      - command: git config --global init.defaultBranch "main"
+        description: Set default branch
+        wait_for: 5
+  - name: node
+    version: "20"
+    dependencies: [git]
+`
+
+const formatJSON = `{
+  "profile": "dev",
+  "tools": [
+    {
+      "name": "git",
+      "version": "latest",
+      "requires_reboot": true,
+      "linux": {"package_names": {"apt": "git"}},
+      "post_install": [
+        {"command": "git config --global init.defaultBranch \"main\"", "description": "Set default branch", "wait_for": 5}
+      ]
+    },
+    {"name": "node", "version": "20", "dependencies": ["git"]}
+  ]
+}
+`
+
+const formatTOML = `profile = "dev"
+
+[[tools]]
+name = "git"
+version = "latest"
+requires_reboot = true
+
+[tools.linux.package_names]
+apt = "git"
+
+[[tools.post_install]]
+command = 'git config --global init.defaultBranch "main"'
+description = "Set default branch"
+wait_for = 5
+
+[[tools]]
+name = "node"
+version = "20"
+dependencies = ["git"]
+`
+
+func TestLoadFormats(t *testing.T) {
+	want, err := LoadFromBytes([]byte(formatYAML))
+	if err != nil {
+		t.Fatalf("Failed to load YAML: %v", err)
+	}
+
+	for format, content := range map[Format]string{FormatJSON: formatJSON, FormatTOML: formatTOML} {
+		got, err := LoadFromBytesAs([]byte(content), format)
+		if err != nil {
+			t.Errorf("Failed to load %s: %v", format, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Tools, want.Tools) || got.Profile != want.Profile {
+			t.Errorf("%s config differs from YAML:\ngot  %+v\nwant %+v", format, got.Tools, want.Tools)
+		}
+	}
+}
+
+func TestLoadFormatsByExtension(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"stackup.toml": "include = [\"base.json\"]\nprofile = \"dev\"\n",
+		"base.json":    `{"tools": [{"name": "git", "version": "latest"}]}`,
+	})
+
+	cfg, err := LoadFromFile(filepath.Join(dir, "stackup.toml"))
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if cfg.Profile != "dev" || len(cfg.Tools) != 1 || cfg.Tools[0].Name != "git" {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadFormatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		content string
+		line    int
+		column  int
+	}{
+		{
+			name:    "JSON syntax error",
+			format:  FormatJSON,
+			content: "{\n  \"tools\": [1,]\n}",
+			line:    2,
+			column:  15,
+		},
+		{
+			name:    "JSON unknown field",
+			format:  FormatJSON,
+			content: "{\n  \"tools\": [\n    {\"name\": \"git\", \"versoin\": \"1\"}\n  ]\n}",
+			line:    3,
+			column:  21,
+		},
+		{
+			name:    "JSON type error",
+			format:  FormatJSON,
+			content: "{\"tools\": [{\"name\": \"git\", \"requires_reboot\": \"soon\"}]}",
+			line:    1,
+		},
+		{
+			name:    "TOML syntax error",
+			format:  FormatTOML,
+			content: "profile = \"dev\"\n[[tools]\nname = \"git\"\n",
+			line:    2,
+		},
+		{
+			name:    "TOML unknown field",
+			format:  FormatTOML,
+			content: "[[tools]]\nname = \"git\"\npackge_names = {apt = \"git\"}\n",
+			line:    3,
+			column:  1,
+		},
+		{
+			name:    "TOML type error",
+			format:  FormatTOML,
+			content: "[[tools]]\nname = \"git\"\n\n[tools.linux]\npackage_names = [\"git\"]\n",
+			line:    5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFromBytesAs([]byte(tt.content), tt.format)
+
+			var errs Errors
+			if !errors.As(err, &errs) || len(errs) == 0 {
+				t.Fatalf("Expected Errors, got %v", err)
+			}
+			if errs[0].Pos.Line != tt.line || (tt.column > 0 && errs[0].Pos.Column != tt.column) {
+				t.Errorf("Got %v, want line %d column %d", errs[0], tt.line, tt.column)
+			}
+		})
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	want, err := LoadFromBytes([]byte(formatYAML))
+	if err != nil {
+		t.Fatalf("Failed to load YAML: %v", err)
+	}
+
+	data := []byte(formatYAML)
+	from := FormatYAML
+	for _, to := range []Format{FormatJSON, FormatTOML, FormatYAML} {
+		var buf bytes.Buffer
+		if err := Convert(&buf, data, "dev", from, to); err != nil {
+			t.Fatalf("Convert(%s -> %s) error: %v", from, to, err)
+		}
+		data, from = buf.Bytes(), to
+
+		got, err := LoadFromBytesAs(data, to)
+		if err != nil {
+			t.Fatalf("Failed to load converted %s:\n%s\n%v", to, data, err)
+		}
+		if !reflect.DeepEqual(got.Tools, want.Tools) {
+			t.Errorf("%s round trip differs:\n%s", to, data)
+		}
+	}
+}
+
+func TestConvertKeepsIncludes(t *testing.T) {
+	var buf bytes.Buffer
+	err := Convert(&buf, []byte("include: [base.yaml]\ntools: []\n"), "dev.yaml", FormatYAML, FormatTOML)
+	if err != nil {
+		t.Fatalf("Convert() error: %v", err)
+	}
+	if want := "include = [\"base.yaml\"]\ntools = []\n"; buf.String() != want {
+		t.Errorf("Convert() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestConvertRejectsInvalidDocuments(t *testing.T) {
+	var buf bytes.Buffer
+	err := Convert(&buf, []byte("tools:\n  - name: git\n    versoin: 1\n"), "dev.yaml", FormatYAML, FormatJSON)
+	var errs Errors
+	if !errors.As(err, &errs) || errs[0].Pos.Line != 3 {
+		t.Errorf("Expected positioned error, got %v", err)
+	}
+}
+
+func TestTOMLStrings(t *testing.T) {
+	tests := map[string]string{
+		"plain":        `"plain"`,
+		`say "hi"`:     `"say \"hi\""`,
+		"C:\\tools":    `"C:\\tools"`,
+		"line\nbreak":  `"line\nbreak"`,
+		"bell\x07":     `"bell\u0007"`,
+		"unicode ✓ ok": `"unicode ✓ ok"`,
+	}
+
+	for in, want := range tests {
+		if got := tomlString(in); got != want {
+			t.Errorf("tomlString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
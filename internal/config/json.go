@@ -0,0 +1,227 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseJSON parses JSON into a YAML node tree, keeping key order and the
+// line and column of every value
+func parseJSON(data []byte) (*yaml.Node, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	p := &jsonParser{data: data, decoder: json.NewDecoder(bytes.NewReader(data))}
+	p.decoder.UseNumber()
+
+	for i, c := range data {
+		if c == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
+	}
+
+	root, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.decoder.Token(); err != io.EOF {
+		pos := p.position(p.next())
+		return nil, &Error{Pos: pos, Msg: "unexpected data after top-level value"}
+	}
+
+	return root, nil
+}
+
+// jsonParser walks the token stream of a json.Decoder
+type jsonParser struct {
+	data    []byte
+	decoder *json.Decoder
+	lines   []int // offsets at which lines after the first start
+}
+
+// next returns the offset of the next token, skipping whitespace and the
+// separators the decoder consumes implicitly
+func (p *jsonParser) next() int {
+	offset := int(p.decoder.InputOffset())
+	for offset < len(p.data) && strings.IndexByte(" \t\r\n,:", p.data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// position converts a byte offset to a 1-based line and column
+func (p *jsonParser) position(offset int) Position {
+	line := sort.SearchInts(p.lines, offset+1)
+	start := 0
+	if line > 0 {
+		start = p.lines[line-1]
+	}
+	return Position{Line: line + 1, Column: offset - start + 1}
+}
+
+// token reads the next token along with its position
+func (p *jsonParser) token() (json.Token, Position, error) {
+	pos := p.position(p.next())
+
+	tok, err := p.decoder.Token()
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			pos = p.position(int(syntaxErr.Offset))
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, pos, &Error{Pos: pos, Msg: strings.TrimPrefix(err.Error(), "json: ")}
+	}
+	return tok, pos, nil
+}
+
+// value reads one JSON value
+func (p *jsonParser) value() (*yaml.Node, error) {
+	tok, pos, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+	return p.node(tok, pos)
+}
+
+// node converts a token, reading the rest of objects and arrays
+func (p *jsonParser) node(tok json.Token, pos Position) (*yaml.Node, error) {
+	node := &yaml.Node{Line: pos.Line, Column: pos.Column}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '{' {
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+			for p.decoder.More() {
+				keyTok, keyPos, err := p.token()
+				if err != nil {
+					return nil, err
+				}
+				key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: keyTok.(string), Line: keyPos.Line, Column: keyPos.Column}
+
+				val, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, key, val)
+			}
+		} else {
+			node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+			for p.decoder.More() {
+				item, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, item)
+			}
+		}
+
+		// Closing delimiter
+		if _, _, err := p.token(); err != nil {
+			return nil, err
+		}
+
+	case string:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!str", v
+	case json.Number:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!int", v.String()
+		if strings.ContainsAny(v.String(), ".eE") {
+			node.Tag = "!!float"
+		}
+	case bool:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!bool", fmt.Sprint(v)
+	case nil:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!null", "null"
+	}
+
+	return node, nil
+}
+
+// writeJSON writes a node tree as indented JSON, keeping key order
+func writeJSON(w io.Writer, node *yaml.Node) error {
+	var b bytes.Buffer
+	if err := jsonValue(&b, node, ""); err != nil {
+		return err
+	}
+	b.WriteByte('\n')
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func jsonValue(b *bytes.Buffer, node *yaml.Node, indent string) error {
+	node = resolveAlias(node)
+	inner := indent + "  "
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+		b.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				b.WriteString(",\n")
+			}
+			b.WriteString(inner)
+			jsonString(b, node.Content[i].Value)
+			b.WriteString(": ")
+			if err := jsonValue(b, node.Content[i+1], inner); err != nil {
+				return err
+			}
+		}
+		b.WriteString("\n" + indent + "}")
+
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			b.WriteString("[]")
+			return nil
+		}
+		b.WriteString("[\n")
+		for i, item := range node.Content {
+			if i > 0 {
+				b.WriteString(",\n")
+			}
+			b.WriteString(inner)
+			if err := jsonValue(b, item, inner); err != nil {
+				return err
+			}
+		}
+		b.WriteString("\n" + indent + "]")
+
+	default:
+		val, err := scalarValue(node)
+		if err != nil {
+			return err
+		}
+		if s, ok := val.(string); ok {
+			jsonString(b, s)
+			return nil
+		}
+		data, err := json.Marshal(val)
+		if err != nil {
+			return &Error{Pos: Position{Line: node.Line, Column: node.Column}, Msg: fmt.Sprintf("cannot represent %q in JSON", node.Value)}
+		}
+		b.Write(data)
+	}
+	return nil
+}
+
+// jsonString writes a JSON string without escaping HTML characters
+func jsonString(b *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(b)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	b.Truncate(b.Len() - 1) // trailing newline
+}
@@ -1,26 +1,155 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
 	"gopkg.in/yaml.v3"
 )
 
-// LoadFromFile loads configuration from a YAML file
+// LoadFromFile loads configuration from a YAML, JSON or TOML file, chosen by
+// extension, resolving includes
 func LoadFromFile(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+	return LoadFiles(path)
+}
+
+// LoadFiles loads several configuration files and merges them in order, so
+// later files override earlier ones. Tools are merged by name field by field,
+// presets and settings key by key. Every file is decoded strictly; all
+// unknown fields and type errors are reported together as Errors. Each
+// file's format is chosen by its extension.
+func LoadFiles(paths ...string) (*Config, error) {
+	return LoadFilesAs(FormatAuto, paths...)
+}
+
+// LoadFilesAs is LoadFiles with the format of the given files forced.
+// Included files are still read according to their extension.
+func LoadFilesAs(format Format, paths ...string) (*Config, error) {
+	return Load(LoadOptions{Format: format}, paths...)
+}
+
+// LoadOptions controls where Load reads configuration from
+type LoadOptions struct {
+	// Format forces the format of the given paths; includes are still read
+	// according to their extension
+	Format Format
+
+	// Fetcher reads http(s):// paths and includes. Remote configs are
+	// rejected when it is nil.
+	Fetcher *Fetcher
+
+	// Stdin is read for the path "-"
+	Stdin io.Reader
+
+	// SHA256 pins the content of the single top-level path, which then
+	// may not include remote configs
+	SHA256 string
+
+	// Vars override or add to the vars section
+	Vars map[string]string
+
+	// Recipes provides the recipes tools reference with use. Tools that use
+	// a recipe are rejected when it is nil.
+	Recipes RecipeSource
+}
+
+// Load is LoadFiles with paths that may also be "-" for stdin or HTTP(S)
+// URLs. Includes of a remote config are resolved relative to its URL.
+func Load(opts LoadOptions, paths ...string) (*Config, error) {
+	if opts.SHA256 != "" && len(paths) != 1 {
+		return nil, fmt.Errorf("a sha256 pin requires exactly one config, got %d", len(paths))
 	}
 
-	return LoadFromBytes(data)
+	l := newLoader()
+	l.opts = opts
+
+	var merged *yaml.Node
+	for _, path := range paths {
+		doc, err := l.loadFile(path, opts.Format.resolve(path))
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeNodes(merged, doc)
+	}
+
+	return l.decode(merged)
 }
 
-// LoadFromBytes parses configuration from byte slice
+// LoadFromBytes parses YAML configuration from byte slice. Includes are
+// resolved relative to the working directory.
 func LoadFromBytes(data []byte) (*Config, error) {
+	return LoadFromBytesAs(data, FormatYAML)
+}
+
+// LoadFromBytesAs parses configuration in the given format from byte slice
+func LoadFromBytesAs(data []byte, format Format) (*Config, error) {
+	l := newLoader()
+
+	doc, err := l.parse(data, "<input>", format.resolve(""))
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err = l.resolveIncludes(doc, ".", "<input>")
+	if err != nil {
+		return nil, err
+	}
+
+	return l.decode(doc)
+}
+
+// ErrIncludeCycle indicates that a file includes itself, directly or indirectly
+var ErrIncludeCycle = errors.New("include cycle")
+
+// loader resolves includes while tracking the include chain for cycle
+// detection, and collects strictness errors and source positions
+type loader struct {
+	opts    LoadOptions
+	stack   []includeFrame
+	errs    Errors
+	source  *sourceMap
+	files   []string
+	origins []string
+}
+
+type includeFrame struct {
+	abs  string
+	path string
+}
+
+func newLoader() *loader {
+	return &loader{source: newSourceMap()}
+}
+
+// decode expands templates, variables and recipes and converts a merged document into a Config
+func (l *loader) decode(doc *yaml.Node) (*Config, error) {
+	if err := l.errs.err(); err != nil {
+		return nil, err
+	}
+
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	if doc != nil {
+		expanded, err := l.expandTemplates(doc)
+		if err != nil {
+			return nil, err
+		}
+		expanded, err = l.interpolate(expanded)
+		if err != nil {
+			return nil, err
+		}
+		expanded, err = l.expandRecipes(expanded)
+		if err != nil {
+			return nil, err
+		}
+		if err := expanded.Decode(&config); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
 	}
 
 	// Set default display names
@@ -30,5 +159,225 @@ func LoadFromBytes(data []byte) (*Config, error) {
 		}
 	}
 
+	config.source = l.source
+	config.files = l.files
+	config.origins = l.origins
 	return &config, nil
-}
\ No newline at end of file
+}
+
+// parse parses and strictly checks one document. Syntax errors are fatal;
+// field and type errors are collected so every file can be reported.
+func (l *loader) parse(data []byte, file string, format Format) (*yaml.Node, error) {
+	doc, err := ParseDocument(data, format)
+	if err != nil {
+		syntaxErr := &Error{Pos: Position{File: file}, Msg: err.Error()}
+		var posErr *Error
+		if errors.As(err, &posErr) {
+			syntaxErr.Pos.Line, syntaxErr.Pos.Column, syntaxErr.Msg = posErr.Pos.Line, posErr.Pos.Column, posErr.Msg
+		}
+		syntaxErr.Msg = fmt.Sprintf("failed to parse %s: %s", strings.ToUpper(string(format)), syntaxErr.Msg)
+		return nil, Errors{syntaxErr}
+	}
+
+	l.errs = append(l.errs, checkDocument(doc, file)...)
+	return doc, nil
+}
+
+// loadFile reads, parses and resolves the includes of a single file
+func (l *loader) loadFile(path string, format Format) (*yaml.Node, error) {
+	key := path
+	if !isRemote(path) && path != "-" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		key = abs
+	}
+
+	for idx, frame := range l.stack {
+		if frame.abs == key {
+			var chain []string
+			for _, f := range l.stack[idx:] {
+				chain = append(chain, f.path)
+			}
+			chain = append(chain, path)
+			return nil, fmt.Errorf("%w: %s", ErrIncludeCycle, strings.Join(chain, " -> "))
+		}
+	}
+
+	// A pin only covers the pinned file, so a remote include could change
+	// the config without failing it
+	if len(l.stack) > 0 && l.opts.SHA256 != "" && isRemote(path) {
+		return nil, fmt.Errorf("%s is not covered by the sha256 pin; remote includes cannot be used with --sha256", path)
+	}
+
+	data, name, origin, err := l.read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only top-level paths are pinned and shown as the config's origin
+	if len(l.stack) == 0 {
+		if l.opts.SHA256 != "" {
+			if err := verifySHA256(data, l.opts.SHA256); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			origin += ", sha256 verified"
+		}
+		l.origins = append(l.origins, origin)
+	}
+
+	l.files = append(l.files, name)
+
+	doc, err := l.parse(data, name, format)
+	if err != nil {
+		return nil, err
+	}
+
+	l.stack = append(l.stack, includeFrame{abs: key, path: path})
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	baseDir := filepath.Dir(path)
+	switch {
+	case isRemote(path):
+		baseDir = path
+	case path == "-":
+		baseDir = "."
+	}
+	return l.resolveIncludes(doc, baseDir, name)
+}
+
+// read returns the content of a path along with the name used in errors
+// and a description of where it came from
+func (l *loader) read(path string) (data []byte, name, origin string, err error) {
+	switch {
+	case path == "-":
+		if l.opts.Stdin == nil {
+			return nil, "", "", fmt.Errorf("reading a config from stdin is not supported here")
+		}
+		data, err := io.ReadAll(l.opts.Stdin)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return data, "<stdin>", "stdin", nil
+
+	case isRemote(path):
+		if l.opts.Fetcher == nil {
+			return nil, "", "", fmt.Errorf("%s: remote configs are not supported here", path)
+		}
+		fetched, err := l.opts.Fetcher.Fetch(path)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("%s: %w", path, err)
+		}
+		origin := path
+		switch {
+		case fetched.Offline:
+			origin += fmt.Sprintf(" (offline, cached %s)", fetched.FetchedAt.Local().Format("2006-01-02 15:04"))
+		case fetched.FromCache:
+			origin += " (cached, unchanged)"
+		}
+		return fetched.Data, path, origin, nil
+
+	default:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("failed to read file: %w", err)
+		}
+		return data, path, path, nil
+	}
+}
+
+// resolveIncludes loads the files listed under include:, relative to baseDir,
+// and merges the document on top of them
+func (l *loader) resolveIncludes(doc *yaml.Node, baseDir, origin string) (*yaml.Node, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	defaultRecipeNames(doc)
+
+	includeNode := mappingValue(doc, "include")
+	if includeNode == nil {
+		l.source.record(doc, origin)
+		return doc, nil
+	}
+
+	var patterns []string
+	if err := includeNode.Decode(&patterns); err != nil {
+		var single string
+		if includeNode.Decode(&single) != nil {
+			return nil, fmt.Errorf("%s:%d:%d: include must be a path or a list of paths", origin, includeNode.Line, includeNode.Column)
+		}
+		patterns = []string{single}
+	}
+
+	var merged *yaml.Node
+	for _, pattern := range patterns {
+		var paths []string
+		var err error
+		if isRemote(baseDir) || isRemote(pattern) {
+			paths, err = resolveRemoteInclude(baseDir, pattern)
+		} else {
+			paths, err = expandInclude(baseDir, pattern)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: include %q: %w", origin, pattern, err)
+		}
+
+		for _, path := range paths {
+			included, err := l.loadFile(path, FormatFromPath(path))
+			if err != nil {
+				if errors.Is(err, ErrIncludeCycle) {
+					return nil, err
+				}
+				return nil, fmt.Errorf("%s: include %q: %w", origin, pattern, err)
+			}
+			merged = mergeNodes(merged, included)
+		}
+	}
+
+	l.source.record(doc, origin)
+	return mergeNodes(merged, removeKey(doc, "include")), nil
+}
+
+// expandInclude resolves an include pattern relative to baseDir. Globs may
+// match nothing; plain paths must exist.
+func expandInclude(baseDir, pattern string) ([]string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(baseDir, pattern)
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		if _, err := os.Stat(pattern); err != nil {
+			return nil, fmt.Errorf("file not found")
+		}
+		return []string{pattern}, nil
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// resolveRemoteInclude resolves an include of or from a remote config as a
+// URL reference. Globs cannot be listed over HTTP.
+func resolveRemoteInclude(base, pattern string) ([]string, error) {
+	if strings.ContainsAny(pattern, "*?[") {
+		return nil, fmt.Errorf("globs are not supported in remote includes")
+	}
+	if !isRemote(base) {
+		return []string{pattern}, nil
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := url.Parse(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return []string{baseURL.ResolveReference(ref).String()}, nil
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -319,6 +321,345 @@ tools:
 	}
 }
 
+func TestLoadDistroOverrides(t *testing.T) {
+	content := `tools:
+  - name: git
+    version: latest
+    linux:
+      package_names:
+        apt: git
+        dnf: git
+      ubuntu:
+        package_names:
+          apt: git-all
+      fedora:
+        package_names:
+          dnf: git-core
+`
+
+	cfg, err := LoadFromBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	linux := cfg.Tools[0].Linux
+	if len(linux.Distros) != 2 {
+		t.Fatalf("len(Distros) = %d, want 2", len(linux.Distros))
+	}
+
+	if linux.Distros["ubuntu"].PackageNames["apt"] != "git-all" {
+		t.Errorf("Distros[ubuntu].PackageNames[apt] = %q, want %q", linux.Distros["ubuntu"].PackageNames["apt"], "git-all")
+	}
+
+	merged := cfg.Tools[0].PlatformConfigFor("linux", "fedora")
+	if merged.PackageNames["dnf"] != "git-core" || merged.PackageNames["apt"] != "git" {
+		t.Errorf("Merged PackageNames = %v", merged.PackageNames)
+	}
+}
+
+func TestLoadSnapAndFlatpakOptions(t *testing.T) {
+	content := `
+tools:
+  - name: vscode
+    manager: snap
+    linux:
+      snap:
+        classic: true
+        channel: latest/stable
+      flatpak:
+        scope: user
+      fedora:
+        flatpak:
+          remote: fedora
+`
+
+	cfg, err := LoadFromBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	linux := cfg.Tools[0].Linux
+	if len(linux.Distros) != 1 {
+		t.Fatalf("Distros = %v, want only fedora", linux.Distros)
+	}
+	if linux.Snap == nil || !linux.Snap.Classic || linux.Snap.Channel != "latest/stable" {
+		t.Errorf("Snap = %+v", linux.Snap)
+	}
+
+	merged := cfg.Tools[0].PlatformConfigFor("linux", "fedora")
+	if merged.Snap == nil || merged.Flatpak == nil || merged.Flatpak.Remote != "fedora" {
+		t.Errorf("Merged Snap = %+v, Flatpak = %+v", merged.Snap, merged.Flatpak)
+	}
+}
+
+func TestLoadRepositories(t *testing.T) {
+	content := `
+tools:
+  - name: docker
+    linux:
+      package_names:
+        apt: docker-ce
+        dnf: docker-ce
+      debian:
+        repositories:
+          - name: docker
+            url: https://download.docker.com/linux/debian
+            components: [stable]
+            key: https://download.docker.com/linux/debian/gpg
+            fingerprint: 9DC8 5822 9FC7 DD38 854A  E2D8 8D81 803C 0EBF CD88
+      fedora:
+        repositories:
+          - name: docker-ce
+            url: https://download.docker.com/linux/fedora/docker-ce.repo
+`
+
+	cfg, err := LoadFromBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	debian := cfg.Tools[0].PlatformConfigFor("linux", "debian")
+	if len(debian.Repositories) != 1 {
+		t.Fatalf("Repositories = %+v, want the docker apt repository", debian.Repositories)
+	}
+	if repo := debian.Repositories[0]; repo.Name != "docker" || len(repo.Components) != 1 || repo.Fingerprint == "" {
+		t.Errorf("Repository = %+v", repo)
+	}
+
+	fedora := cfg.Tools[0].PlatformConfigFor("linux", "fedora")
+	if len(fedora.Repositories) != 1 || fedora.Repositories[0].Name != "docker-ce" || fedora.PackageNames["dnf"] != "docker-ce" {
+		t.Errorf("Fedora section = %+v", fedora)
+	}
+}
+
+// writeFiles creates files under dir from a map of relative paths to contents
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"base.yaml": `profile: base
+settings:
+  verify_installations: true
+tools:
+  - name: git
+    version: latest
+    linux:
+      package_names:
+        apt: git
+`,
+		"teams/backend.yaml": `tools:
+  - name: go
+    version: "1.22"
+    linux:
+      package_names:
+        apt: golang
+`,
+		"teams/frontend.yaml": `tools:
+  - name: node
+    version: "20"
+    linux:
+      package_names:
+        apt: nodejs
+`,
+		"stackup.yaml": `include:
+  - base.yaml
+  - teams/*.yaml
+profile: me
+tools:
+  - name: git
+    version: "2.44"
+`,
+	})
+
+	cfg, err := LoadFromFile(filepath.Join(dir, "stackup.yaml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.Profile != "me" {
+		t.Errorf("Profile = %q, want %q", cfg.Profile, "me")
+	}
+	if !cfg.Settings.VerifyInstallations {
+		t.Error("Settings from included file should be kept")
+	}
+	if len(cfg.Include) != 0 {
+		t.Errorf("Include = %v, should be cleared after resolution", cfg.Include)
+	}
+
+	expected := []string{"git", "go", "node"}
+	if len(cfg.Tools) != len(expected) {
+		t.Fatalf("len(Tools) = %d, want %d", len(cfg.Tools), len(expected))
+	}
+	for i, name := range expected {
+		if cfg.Tools[i].Name != name {
+			t.Errorf("Tools[%d].Name = %q, want %q", i, cfg.Tools[i].Name, name)
+		}
+	}
+
+	if cfg.Tools[0].Version != "2.44" || cfg.Tools[0].Linux == nil {
+		t.Errorf("git should merge the including file over the included one, got %+v", cfg.Tools[0])
+	}
+
+	var files []string
+	for _, file := range cfg.Files() {
+		rel, _ := filepath.Rel(dir, file)
+		files = append(files, filepath.ToSlash(rel))
+	}
+	expectedFiles := []string{"stackup.yaml", "base.yaml", "teams/backend.yaml", "teams/frontend.yaml"}
+	if strings.Join(files, ",") != strings.Join(expectedFiles, ",") {
+		t.Errorf("Files() = %v, want %v", files, expectedFiles)
+	}
+}
+
+func TestLoadIncludeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		entry    string
+		errorMsg []string
+		cycle    bool
+	}{
+		{
+			name: "Missing Include",
+			files: map[string]string{
+				"main.yaml": "include: [missing.yaml]\n",
+			},
+			entry:    "main.yaml",
+			errorMsg: []string{"main.yaml", `include "missing.yaml"`, "file not found"},
+		},
+		{
+			name: "Parse Error In Included File",
+			files: map[string]string{
+				"main.yaml":   "include: [broken.yaml]\n",
+				"broken.yaml": "tools: [unclosed\n",
+			},
+			entry:    "main.yaml",
+			errorMsg: []string{"main.yaml", "broken.yaml", "failed to parse YAML"},
+		},
+		{
+			name: "Direct Cycle",
+			files: map[string]string{
+				"self.yaml": "include: [self.yaml]\n",
+			},
+			entry:    "self.yaml",
+			errorMsg: []string{"include cycle", "self.yaml -> "},
+			cycle:    true,
+		},
+		{
+			name: "Indirect Cycle",
+			files: map[string]string{
+				"a.yaml": "include: [b.yaml]\n",
+				"b.yaml": "include: [c.yaml]\n",
+				"c.yaml": "include: [a.yaml]\n",
+			},
+			entry:    "a.yaml",
+			errorMsg: []string{"a.yaml -> ", "b.yaml -> ", "c.yaml -> "},
+			cycle:    true,
+		},
+		{
+			name: "Invalid Include Value",
+			files: map[string]string{
+				"main.yaml": "include: {a: b}\n",
+			},
+			entry:    "main.yaml",
+			errorMsg: []string{"main.yaml", "include must be a path"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			_, err := LoadFromFile(filepath.Join(dir, tt.entry))
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+
+			for _, msg := range tt.errorMsg {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("Error %q does not contain %q", err.Error(), msg)
+				}
+			}
+
+			if errors.Is(err, ErrIncludeCycle) != tt.cycle {
+				t.Errorf("errors.Is(err, ErrIncludeCycle) = %v, want %v", !tt.cycle, tt.cycle)
+			}
+		})
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"base.yaml": `profile: org
+settings:
+  auto_update_path: true
+  verify_installations: true
+tools:
+  - name: git
+    version: latest
+    linux:
+      package_names:
+        apt: git
+presets:
+  core:
+    tools: [git]
+`,
+		"team.yaml": `profile: team
+tools:
+  - name: docker
+    version: latest
+    linux:
+      package_names:
+        apt: docker.io
+presets:
+  core:
+    tools: [git, docker]
+`,
+		"me.yaml": `settings:
+  verify_installations: false
+tools:
+  - name: git
+    version: "2.44"
+`,
+	})
+
+	cfg, err := LoadFiles(
+		filepath.Join(dir, "base.yaml"),
+		filepath.Join(dir, "team.yaml"),
+		filepath.Join(dir, "me.yaml"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.Profile != "team" {
+		t.Errorf("Profile = %q, want %q", cfg.Profile, "team")
+	}
+	if !cfg.Settings.AutoUpdatePath || cfg.Settings.VerifyInstallations {
+		t.Errorf("Settings = %+v, want auto_update_path kept and verify_installations overridden", cfg.Settings)
+	}
+	if len(cfg.Tools) != 2 || cfg.Tools[0].Version != "2.44" || cfg.Tools[0].Linux == nil {
+		t.Errorf("Tools = %+v, want git merged and docker appended", cfg.Tools)
+	}
+	if len(cfg.Presets["core"].Tools) != 2 {
+		t.Errorf("Presets[core].Tools = %v, want team override", cfg.Presets["core"].Tools)
+	}
+}
+
 // Benchmark config parsing
 func BenchmarkLoadFromBytes(b *testing.B) {
 	content := []byte(ExampleConfig)
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// mergeNodes merges overlay on top of base and returns the result. Mappings
+// are merged key by key, sequences of mappings that carry a "name" key (tools)
+// are merged by name, and any other value in overlay replaces the one in base.
+// Neither input is modified.
+func mergeNodes(base, overlay *yaml.Node) *yaml.Node {
+	base = resolveAlias(base)
+	overlay = resolveAlias(overlay)
+
+	if base == nil {
+		return overlay
+	}
+	if overlay == nil {
+		return base
+	}
+
+	switch {
+	case base.Kind == yaml.MappingNode && overlay.Kind == yaml.MappingNode:
+		return mergeMappings(base, overlay)
+	case base.Kind == yaml.SequenceNode && overlay.Kind == yaml.SequenceNode &&
+		isNamedSequence(base) && isNamedSequence(overlay):
+		return mergeNamedSequences(base, overlay)
+	default:
+		return overlay
+	}
+}
+
+// mergeMappings merges two mapping nodes key by key, keeping base key order
+// and appending keys that only exist in overlay
+func mergeMappings(base, overlay *yaml.Node) *yaml.Node {
+	merged := *base
+	merged.Content = make([]*yaml.Node, 0, len(base.Content)+len(overlay.Content))
+	merged.Content = append(merged.Content, base.Content...)
+
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, val := overlay.Content[i], overlay.Content[i+1]
+
+		if idx := mappingIndex(&merged, key.Value); idx >= 0 {
+			merged.Content[idx+1] = mergeNodes(merged.Content[idx+1], val)
+		} else {
+			merged.Content = append(merged.Content, key, val)
+		}
+	}
+
+	return &merged
+}
+
+// mergeNamedSequences merges two sequences of mappings by their "name" key.
+// Matching entries are merged field by field; new entries are appended.
+func mergeNamedSequences(base, overlay *yaml.Node) *yaml.Node {
+	merged := *base
+	merged.Content = make([]*yaml.Node, 0, len(base.Content)+len(overlay.Content))
+	merged.Content = append(merged.Content, base.Content...)
+
+	for _, item := range overlay.Content {
+		name := nodeName(item)
+		matched := false
+
+		for idx, existing := range merged.Content {
+			if name != "" && nodeName(existing) == name {
+				merged.Content[idx] = mergeNodes(existing, item)
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			merged.Content = append(merged.Content, item)
+		}
+	}
+
+	return &merged
+}
+
+// isNamedSequence reports whether every item of a sequence is a mapping with a name
+func isNamedSequence(seq *yaml.Node) bool {
+	for _, item := range seq.Content {
+		if nodeName(item) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeName returns the scalar "name" value of a mapping node
+func nodeName(node *yaml.Node) string {
+	node = resolveAlias(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return ""
+	}
+
+	if idx := mappingIndex(node, "name"); idx >= 0 && node.Content[idx+1].Kind == yaml.ScalarNode {
+		return node.Content[idx+1].Value
+	}
+	return ""
+}
+
+// mappingIndex returns the index of a key in a mapping node's content, or -1
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mappingValue returns the value for a key in a mapping node, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if idx := mappingIndex(mapping, key); idx >= 0 {
+		return resolveAlias(mapping.Content[idx+1])
+	}
+	return nil
+}
+
+// removeKey returns a copy of a mapping node without the given key
+func removeKey(mapping *yaml.Node, key string) *yaml.Node {
+	idx := mappingIndex(mapping, key)
+	if idx < 0 {
+		return mapping
+	}
+
+	trimmed := *mapping
+	trimmed.Content = append(append([]*yaml.Node{}, mapping.Content[:idx]...), mapping.Content[idx+2:]...)
+	return &trimmed
+}
+
+// resolveAlias follows alias nodes to the node they reference
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
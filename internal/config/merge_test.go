@@ -0,0 +1,143 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func mustParse(t *testing.T, content string) *yaml.Node {
+	t.Helper()
+	doc, err := ParseDocument([]byte(content), FormatYAML)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	return doc
+}
+
+func TestMergeNodes(t *testing.T) {
+	base := mustParse(t, `profile: base
+settings:
+  auto_update_path: true
+  verify_installations: true
+tools:
+  - name: git
+    version: latest
+    linux:
+      package_names:
+        apt: git
+  - name: node
+    version: "18"
+    dependencies: [git]
+presets:
+  web:
+    description: "Web"
+    tools: [git, node]
+`)
+
+	overlay := mustParse(t, `profile: team
+settings:
+  verify_installations: false
+tools:
+  - name: node
+    version: "20"
+  - name: git
+    linux:
+      package_names:
+        dnf: git-core
+  - name: go
+    version: "1.22"
+presets:
+  go:
+    description: "Go"
+    tools: [go]
+`)
+
+	merged := mergeNodes(base, overlay)
+
+	cfg, err := newLoader().decode(merged)
+	if err != nil {
+		t.Fatalf("Failed to decode merged config: %v", err)
+	}
+
+	if cfg.Profile != "team" {
+		t.Errorf("Profile = %q, want %q", cfg.Profile, "team")
+	}
+
+	if !cfg.Settings.AutoUpdatePath {
+		t.Error("AutoUpdatePath should be kept from base")
+	}
+	if cfg.Settings.VerifyInstallations {
+		t.Error("VerifyInstallations should be overridden by overlay")
+	}
+
+	names := []string{}
+	for _, tool := range cfg.Tools {
+		names = append(names, tool.Name)
+	}
+	if len(names) != 3 || names[0] != "git" || names[1] != "node" || names[2] != "go" {
+		t.Fatalf("Tool order = %v, want [git node go]", names)
+	}
+
+	git := cfg.Tools[0]
+	if git.Version != "latest" {
+		t.Errorf("git.Version = %q, want %q", git.Version, "latest")
+	}
+	if git.Linux.PackageNames["apt"] != "git" || git.Linux.PackageNames["dnf"] != "git-core" {
+		t.Errorf("git.Linux.PackageNames = %v, want apt and dnf entries", git.Linux.PackageNames)
+	}
+
+	node := cfg.Tools[1]
+	if node.Version != "20" {
+		t.Errorf("node.Version = %q, want %q", node.Version, "20")
+	}
+	if len(node.Dependencies) != 1 {
+		t.Errorf("node.Dependencies = %v, want base dependencies kept", node.Dependencies)
+	}
+
+	if len(cfg.Presets) != 2 {
+		t.Errorf("len(Presets) = %d, want 2", len(cfg.Presets))
+	}
+
+	// Inputs must be left untouched
+	if cfg, _ := newLoader().decode(base); cfg.Tools[1].Version != "18" || len(cfg.Tools) != 2 {
+		t.Error("mergeNodes modified its base input")
+	}
+}
+
+func TestMergeNodesReplacesLists(t *testing.T) {
+	base := mustParse(t, `tools:
+  - name: tool
+    post_install:
+      - command: echo
+        args: [one]
+      - command: echo
+        args: [two]
+`)
+	overlay := mustParse(t, `tools:
+  - name: tool
+    post_install:
+      - command: echo
+        args: [three]
+`)
+
+	cfg, err := newLoader().decode(mergeNodes(base, overlay))
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	if len(cfg.Tools[0].PostInstall) != 1 || cfg.Tools[0].PostInstall[0].Args[0] != "three" {
+		t.Errorf("PostInstall = %+v, want overlay list", cfg.Tools[0].PostInstall)
+	}
+}
+
+func TestMergeNodesNil(t *testing.T) {
+	doc := mustParse(t, `profile: only`)
+
+	if mergeNodes(nil, doc) != doc {
+		t.Error("mergeNodes(nil, doc) should return doc")
+	}
+	if mergeNodes(doc, nil) != doc {
+		t.Error("mergeNodes(doc, nil) should return doc")
+	}
+}
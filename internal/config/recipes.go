@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RecipeSource provides the tool recipes that tools reference with use
+type RecipeSource interface {
+	// Recipe returns the definition of a recipe and the name of the file it
+	// was read from, used in error messages and to pick its format
+	Recipe(name string) (data []byte, file string, err error)
+}
+
+// DecodeRecipe strictly parses a recipe: a file holding a single tool
+// definition
+func DecodeRecipe(data []byte, file string) (Tool, error) {
+	var tool Tool
+	node, err := parseRecipe(data, file)
+	if err != nil {
+		return tool, err
+	}
+	if err := node.Decode(&tool); err != nil {
+		return tool, fmt.Errorf("%s: %w", file, err)
+	}
+	return tool, nil
+}
+
+// parseRecipe parses and strictly checks a recipe file. Recipes are
+// self-contained, so they cannot use other recipes or extend templates.
+func parseRecipe(data []byte, file string) (*yaml.Node, error) {
+	doc, err := ParseDocument(data, FormatFromPath(file))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if doc == nil || doc.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: a recipe must be a single tool definition", file)
+	}
+
+	// Check the tool as an entry of a config's tools so errors name it
+	wrapped := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: "tools"},
+		{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{doc}},
+	}}
+	errs := checkDocument(wrapped, file)
+	for _, key := range []string{"use", "extends", "params"} {
+		if idx := mappingIndex(doc, key); idx >= 0 {
+			k := doc.Content[idx]
+			errs.add(Position{File: file, Line: k.Line, Column: k.Column}, toolContext(doc, 0), "recipes cannot set %s", key)
+		}
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// defaultRecipeNames names every tool that uses a recipe without giving a
+// name after the recipe, so it can be merged and reported by name
+func defaultRecipeNames(doc *yaml.Node) {
+	if doc == nil {
+		return
+	}
+	tools := mappingValue(doc, "tools")
+	if tools == nil || tools.Kind != yaml.SequenceNode {
+		return
+	}
+
+	for _, tool := range tools.Content {
+		tool = resolveAlias(tool)
+		if tool == nil || tool.Kind != yaml.MappingNode || mappingIndex(tool, "name") >= 0 {
+			continue
+		}
+		use := mappingValue(tool, "use")
+		if use == nil || use.Kind != yaml.ScalarNode || use.Value == "" {
+			continue
+		}
+		name := *use
+		name.Tag = "!!str"
+		tool.Content = append([]*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: "name", Line: use.Line, Column: use.Column}, &name}, tool.Content...)
+	}
+}
+
+// expandRecipes replaces every tool that uses a recipe with the recipe
+// deep-merged under the tool. Recipes are expanded after variables, so their
+// strings are taken literally.
+func (l *loader) expandRecipes(doc *yaml.Node) (*yaml.Node, error) {
+	tools := mappingValue(doc, "tools")
+	if tools == nil || tools.Kind != yaml.SequenceNode {
+		return doc, nil
+	}
+
+	var errs Errors
+	newTools := *tools
+	newTools.Content = make([]*yaml.Node, len(tools.Content))
+
+	for idx, tool := range tools.Content {
+		tool = resolveAlias(tool)
+		newTools.Content[idx] = tool
+
+		use := mappingValue(tool, "use")
+		if use == nil {
+			continue
+		}
+
+		name := nodeName(tool)
+		pos := l.source.field(name, "use")
+		pos.Line, pos.Column = use.Line, use.Column
+		context := fmt.Sprintf("tool %q", name)
+
+		if use.Kind != yaml.ScalarNode || use.Value == "" {
+			errs.add(pos, context, "use must be a recipe name")
+			continue
+		}
+		if l.opts.Recipes == nil {
+			errs.add(pos, context, "recipe %q: no recipe catalog is available", use.Value)
+			continue
+		}
+
+		data, file, err := l.opts.Recipes.Recipe(use.Value)
+		if err != nil {
+			errs.add(pos, context, "%s", err)
+			continue
+		}
+		recipe, err := parseRecipe(data, file)
+		if err != nil {
+			errs.add(pos, context, "recipe %q: %s", use.Value, err)
+			continue
+		}
+
+		newTools.Content[idx] = mergeNodes(recipe, removeKey(tool, "use"))
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return setKey(doc, "tools", &newTools), nil
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recipeMap serves recipes from memory
+type recipeMap map[string]string
+
+func (r recipeMap) Recipe(name string) ([]byte, string, error) {
+	data, ok := r[name]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown recipe %q", name)
+	}
+	return []byte(data), "recipes/" + name + ".yaml", nil
+}
+
+var testRecipes = recipeMap{
+	"node": "name: node\ndisplay_name: Node.js\nversion: lts\nlinux:\n  package_names:\n    apt: nodejs\n    dnf: nodejs\nverify_command: node --version\n",
+	"bad":  "name: bad\nversoin: 1\nuse: node\n",
+}
+
+func TestLoadRecipes(t *testing.T) {
+	content := `vars:
+  node: "20.x"
+tools:
+  - use: node
+    version: ${node}
+    linux:
+      package_names:
+        apt: nodejs=20.*
+  - name: node-lts
+    use: node
+`
+	cfg, err := Load(LoadOptions{Stdin: strings.NewReader(content), Recipes: testRecipes}, "-")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	node := cfg.Tools[0]
+	if node.Name != "node" || node.Version != "20.x" || node.Use != "" {
+		t.Errorf("Tool = %+v, want node 20.x with use consumed", node)
+	}
+	if node.Linux.PackageNames["apt"] != "nodejs=20.*" || node.Linux.PackageNames["dnf"] != "nodejs" {
+		t.Errorf("PackageNames = %v, want the override merged over the recipe", node.Linux.PackageNames)
+	}
+	if node.VerifyCommand != "node --version" {
+		t.Errorf("VerifyCommand = %q, want the recipe's", node.VerifyCommand)
+	}
+
+	if lts := cfg.Tools[1]; lts.Name != "node-lts" || lts.Version != "lts" || lts.DisplayName != "Node.js" {
+		t.Errorf("Tool = %+v, want the recipe under its own name", lts)
+	}
+}
+
+func TestLoadRecipesMergedByName(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"base.yaml": "tools:\n  - use: node\n",
+		"team.yaml": "include: base.yaml\ntools:\n  - name: node\n    version: \"22\"\n",
+	})
+
+	cfg, err := Load(LoadOptions{Recipes: testRecipes}, filepath.Join(dir, "team.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(cfg.Tools) != 1 || cfg.Tools[0].Version != "22" || cfg.Tools[0].VerifyCommand == "" {
+		t.Errorf("Tools = %+v, want one node tool with the overlay's version", cfg.Tools)
+	}
+}
+
+func TestLoadRecipeErrors(t *testing.T) {
+	content := "tools:\n  - use: bad\n  - use: missing\n"
+
+	_, err := Load(LoadOptions{Stdin: strings.NewReader(content), Recipes: testRecipes}, "-")
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected Errors, got %v", err)
+	}
+	want := []string{
+		`<stdin>:2:10: tool "bad": recipe "bad": recipes/bad.yaml:2:1: tool "bad": unknown field "versoin", did you mean "version"?` + "\n" +
+			`recipes/bad.yaml:3:1: tool "bad": recipes cannot set use`,
+		`<stdin>:3:10: tool "missing": unknown recipe "missing"`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Got %d errors, want %d:\n%v", len(errs), len(want), err)
+	}
+	for i := range want {
+		if errs[i].Error() != want[i] {
+			t.Errorf("errs[%d] = %q, want %q", i, errs[i].Error(), want[i])
+		}
+	}
+
+	if _, err := LoadFromBytes([]byte("tools:\n  - use: node\n")); err == nil || !strings.Contains(err.Error(), "no recipe catalog") {
+		t.Errorf("Expected use without a catalog to fail, got %v", err)
+	}
+}
+
+func TestDecodeRecipe(t *testing.T) {
+	tool, err := DecodeRecipe([]byte(testRecipes["node"]), "node.yaml")
+	if err != nil {
+		t.Fatalf("DecodeRecipe() error: %v", err)
+	}
+	if tool.Name != "node" || tool.Linux == nil {
+		t.Errorf("DecodeRecipe() = %+v", tool)
+	}
+
+	if _, err := DecodeRecipe([]byte("- name: a\n"), "list.yaml"); err == nil {
+		t.Error("DecodeRecipe() should reject a file that is not a single tool")
+	}
+}
+
+func TestConvertKeepsRecipeReferences(t *testing.T) {
+	var buf strings.Builder
+	if err := Convert(&buf, []byte("tools:\n  - use: node\n"), "a.yaml", FormatYAML, FormatTOML); err != nil {
+		t.Fatalf("Convert() error: %v", err)
+	}
+	if got := buf.String(); got != "[[tools]]\nuse = \"node\"\n" {
+		t.Errorf("Convert() = %q, want the tool as written", got)
+	}
+}
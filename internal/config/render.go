@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Render writes a configuration as YAML
+func Render(w io.Writer, cfg *Config) error {
+	return RenderAs(w, cfg, FormatYAML)
+}
+
+// RenderAs writes a configuration in the given format
+func RenderAs(w io.Writer, cfg *Config, format Format) error {
+	var node yaml.Node
+	if err := node.Encode(cfg); err != nil {
+		return err
+	}
+	return encode(w, &node, format)
+}
+
+// Convert translates a single configuration document to another format.
+// The document is checked strictly, but includes and templates are kept as
+// written rather than resolved.
+func Convert(w io.Writer, data []byte, file string, from, to Format) error {
+	l := newLoader()
+	doc, err := l.parse(data, file, from.resolve(file))
+	if err != nil {
+		return err
+	}
+	if err := l.errs.err(); err != nil {
+		return err
+	}
+	if doc == nil {
+		doc = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	return encode(w, doc, to)
+}
+
+// encode writes a document node in the given format
+func encode(w io.Writer, node *yaml.Node, format Format) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, node)
+	case FormatTOML:
+		return writeTOML(w, node)
+	case FormatYAML, FormatAuto:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(node); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// scalarValue decodes a scalar node into a Go value for the JSON and TOML
+// writers. Strings and timestamps are kept as written.
+func scalarValue(node *yaml.Node) (interface{}, error) {
+	switch node.ShortTag() {
+	case "!!str", "!!timestamp", "!!binary":
+		return node.Value, nil
+	case "!!null":
+		return nil, nil
+	}
+
+	var val interface{}
+	if err := node.Decode(&val); err != nil {
+		return nil, &Error{Pos: Position{Line: node.Line, Column: node.Column}, Msg: err.Error()}
+	}
+	return val, nil
+}
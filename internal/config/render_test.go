@@ -0,0 +1,33 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	cfg, err := LoadFromBytes([]byte(ExampleConfig))
+	if err != nil {
+		t.Fatalf("Failed to load example: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, cfg); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "profile: full-stack-dev") {
+		t.Errorf("Rendered output missing profile:\n%s", buf.String())
+	}
+
+	// Rendered output must load back to the same configuration
+	roundTrip, err := LoadFromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to load rendered config: %v", err)
+	}
+
+	if len(roundTrip.Tools) != len(cfg.Tools) || roundTrip.Tools[0].Linux.PackageNames["dnf"] != "git" {
+		t.Errorf("Round trip mismatch: %+v", roundTrip.Tools)
+	}
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Secret is a value read at install time from an environment variable, a
+// file or the output of a command such as a password manager CLI. Secrets
+// are referenced as ${secret:name} in download headers and command env only,
+// are never stored in the loaded config and are redacted from all output.
+type Secret struct {
+	Env     string   `yaml:"env,omitempty"`
+	File    string   `yaml:"file,omitempty"`
+	Command string   `yaml:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty"`
+}
+
+// secretReference matches ${secret:name}
+var secretReference = regexp.MustCompile(`\$\{secret:([^}]*)\}`)
+
+// ExpandSecrets replaces every ${secret:name} reference in s with the value
+// returned by lookup
+func ExpandSecrets(s string, lookup func(name string) (string, error)) (string, error) {
+	var firstErr error
+	result := secretReference.ReplaceAllStringFunc(s, func(match string) string {
+		if firstErr != nil {
+			return match
+		}
+		val, err := lookup(strings.TrimSpace(secretReference.FindStringSubmatch(match)[1]))
+		if err != nil {
+			firstErr = err
+			return match
+		}
+		return val
+	})
+	return result, firstErr
+}
+
+// secretNames returns the secrets referenced in s
+func secretNames(s string) []string {
+	var names []string
+	for _, match := range secretReference.FindAllStringSubmatch(s, -1) {
+		names = append(names, strings.TrimSpace(match[1]))
+	}
+	return names
+}
+
+// SecretReferences returns the sorted names of the secrets the tools
+// reference
+func (c *Config) SecretReferences() []string {
+	seen := make(map[string]bool)
+	for i := range c.Tools {
+		for _, use := range secretUses(&c.Tools[i]) {
+			seen[use.name] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// secretUse is a ${secret:name} reference found in a tool
+type secretUse struct {
+	where   string // path below the tool, e.g. linux.headers.Authorization
+	name    string
+	allowed bool // whether the field may hold secrets
+}
+
+// secretFields are the map fields whose values may reference secrets
+var secretFields = map[string]bool{"headers": true, "env": true}
+
+// secretUses finds every secret reference in a tool's string fields
+func secretUses(tool *Tool) []secretUse {
+	var uses []secretUse
+	walkStrings(reflect.ValueOf(tool).Elem(), "", false, func(where, value string, allowed bool) {
+		for _, name := range secretNames(value) {
+			uses = append(uses, secretUse{where: where, name: name, allowed: allowed})
+		}
+	})
+	return uses
+}
+
+// walkStrings calls fn for every string reachable from v, with its dotted
+// yaml path and whether it sits in a field that may hold secrets
+func walkStrings(v reflect.Value, where string, allowed bool, fn func(where, value string, allowed bool)) {
+	join := func(key string) string {
+		if where == "" {
+			return key
+		}
+		return where + "." + key
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			walkStrings(v.Elem(), where, allowed, fn)
+		}
+
+	case reflect.String:
+		fn(where, v.String(), allowed)
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkStrings(v.Index(i), fmt.Sprintf("%s[%d]", where, i), allowed, fn)
+		}
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			walkStrings(v.MapIndex(key), join(key.String()), allowed, fn)
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if strings.Contains(opts, "inline") {
+				walkStrings(v.Field(i), where, allowed, fn)
+				continue
+			}
+			walkStrings(v.Field(i), join(name), secretFields[name], fn)
+		}
+	}
+}
+
+// validateSecrets checks every secret has exactly one source and that tools
+// only reference defined secrets in headers and env
+func validateSecrets(cfg *Config, errs *Errors) {
+	for _, name := range sortedKeys(cfg.Secrets) {
+		secret := cfg.Secrets[name]
+		sources := 0
+		for _, set := range []bool{secret.Env != "", secret.File != "", secret.Command != ""} {
+			if set {
+				sources++
+			}
+		}
+		if sources != 1 {
+			errs.add(cfg.source.secret(name), fmt.Sprintf("secret %q", name), "exactly one of env, file or command must be set")
+		}
+	}
+
+	for i := range cfg.Tools {
+		tool := &cfg.Tools[i]
+		context := fmt.Sprintf("tool %q", tool.Name)
+
+		for _, use := range secretUses(tool) {
+			field := use.where
+			if idx := strings.IndexAny(field, ".["); idx >= 0 {
+				field = field[:idx]
+			}
+			pos := cfg.source.field(tool.Name, field)
+
+			if !use.allowed {
+				errs.add(pos, context, "%s: secrets may only be used in headers and env", use.where)
+				continue
+			}
+			if _, ok := cfg.Secrets[use.name]; !ok {
+				errs.add(pos, context, "%s: undefined secret %q", use.where, use.name)
+			}
+		}
+	}
+}
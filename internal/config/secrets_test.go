@@ -0,0 +1,93 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const secretsConfig = `secrets:
+  artifactory:
+    env: ARTIFACTORY_TOKEN
+  npm:
+    command: op
+    args: [read, "op://dev/npm/token"]
+tools:
+  - name: internal-cli
+    version: latest
+    linux:
+      installer: https://artifacts.internal/cli.sh
+      headers:
+        Authorization: Bearer ${secret:artifactory}
+    post_install:
+      - command: npm
+        args: [login]
+        env:
+          NPM_TOKEN: ${secret:npm}
+`
+
+func TestSecretReferences(t *testing.T) {
+	cfg, err := LoadFromBytes([]byte(secretsConfig))
+	if err != nil {
+		t.Fatalf("LoadFromBytes() error: %v", err)
+	}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+
+	if got := cfg.SecretReferences(); !reflect.DeepEqual(got, []string{"artifactory", "npm"}) {
+		t.Errorf("SecretReferences() = %v", got)
+	}
+
+	// References stay unresolved in the loaded and rendered config
+	if got := cfg.Tools[0].Linux.Headers["Authorization"]; got != "Bearer ${secret:artifactory}" {
+		t.Errorf("Headers = %q", got)
+	}
+	var buf bytes.Buffer
+	if err := Render(&buf, cfg); err != nil || !strings.Contains(buf.String(), "${secret:npm}") {
+		t.Errorf("Render() should keep references, got %v:\n%s", err, buf.String())
+	}
+}
+
+func TestValidateSecrets(t *testing.T) {
+	content := `secrets:
+  none: {}
+  both:
+    env: TOKEN
+    file: ~/.token
+tools:
+  - name: tool
+    version: latest
+    linux:
+      installer: https://example.com/${secret:both}/tool.sh
+      headers:
+        Authorization: ${secret:missing}
+`
+	cfg, err := LoadFromBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("LoadFromBytes() error: %v", err)
+	}
+
+	err = Validate(cfg)
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected Errors, got %v", err)
+	}
+
+	want := []string{
+		`<input>:3:3: secret "both": exactly one of env, file or command must be set`,
+		`<input>:2:3: secret "none": exactly one of env, file or command must be set`,
+		`<input>:9:5: tool "tool": linux.installer: secrets may only be used in headers and env`,
+		`<input>:9:5: tool "tool": linux.headers.Authorization: undefined secret "missing"`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Got %d errors, want %d:\n%v", len(errs), len(want), err)
+	}
+	for i := range want {
+		if errs[i].Error() != want[i] {
+			t.Errorf("errs[%d] = %q, want %q", i, errs[i].Error(), want[i])
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// sourceMap records where tools and their fields were defined so that
+// validation errors can point at the originating file, line and column
+type sourceMap struct {
+	tools   map[string][]Position
+	fields  map[string]map[string]Position
+	presets map[string]Position
+	vars    map[string]Position
+	secrets map[string]Position
+	files   map[*yaml.Node]string // the file of every node, for errors found after merging
+}
+
+func newSourceMap() *sourceMap {
+	return &sourceMap{
+		tools:   make(map[string][]Position),
+		fields:  make(map[string]map[string]Position),
+		presets: make(map[string]Position),
+		vars:    make(map[string]Position),
+		secrets: make(map[string]Position),
+		files:   make(map[*yaml.Node]string),
+	}
+}
+
+// record notes the position of every named tool, preset, variable and
+// secret in a document. Documents are recorded in merge order, so later
+// definitions of a field win.
+func (s *sourceMap) record(doc *yaml.Node, file string) {
+	s.recordFiles(doc, file)
+
+	for section, positions := range map[string]map[string]Position{"vars": s.vars, "secrets": s.secrets} {
+		node := mappingValue(doc, section)
+		if node == nil || node.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			positions[key.Value] = Position{File: file, Line: key.Line, Column: key.Column}
+		}
+	}
+
+	if presets := mappingValue(doc, "presets"); presets != nil && presets.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(presets.Content); i += 2 {
+			key := presets.Content[i]
+			s.presets[key.Value] = Position{File: file, Line: key.Line, Column: key.Column}
+		}
+	}
+
+	tools := mappingValue(doc, "tools")
+	if tools == nil || tools.Kind != yaml.SequenceNode {
+		return
+	}
+
+	for _, tool := range tools.Content {
+		tool = resolveAlias(tool)
+		name := nodeName(tool)
+		if name == "" {
+			continue
+		}
+
+		s.tools[name] = append(s.tools[name], Position{File: file, Line: tool.Line, Column: tool.Column})
+
+		if s.fields[name] == nil {
+			s.fields[name] = make(map[string]Position)
+		}
+		for i := 0; i+1 < len(tool.Content); i += 2 {
+			key := tool.Content[i]
+			s.fields[name][key.Value] = Position{File: file, Line: key.Line, Column: key.Column}
+		}
+	}
+}
+
+// recordFiles notes the file of a node and everything under it. Merging
+// reuses the nodes it does not combine, so they can still be traced back.
+func (s *sourceMap) recordFiles(node *yaml.Node, file string) {
+	if node == nil {
+		return
+	}
+	s.files[node] = file
+	for _, child := range node.Content {
+		s.recordFiles(child, file)
+	}
+}
+
+// position returns where a node was defined. The file is unknown for nodes
+// built by merging.
+func (s *sourceMap) position(node *yaml.Node) Position {
+	if s == nil || node == nil {
+		return Position{}
+	}
+	return Position{File: s.files[node], Line: node.Line, Column: node.Column}
+}
+
+// tool returns the position of the nth definition of a tool
+func (s *sourceMap) tool(name string, occurrence int) Position {
+	if s == nil || occurrence >= len(s.tools[name]) {
+		return Position{}
+	}
+	return s.tools[name][occurrence]
+}
+
+// field returns the position of a tool field, falling back to the tool itself
+func (s *sourceMap) field(name, key string) Position {
+	if s == nil {
+		return Position{}
+	}
+	if pos, ok := s.fields[name][key]; ok {
+		return pos
+	}
+	return s.tool(name, 0)
+}
+
+// preset returns the position of the last definition of a preset
+func (s *sourceMap) preset(name string) Position {
+	if s == nil {
+		return Position{}
+	}
+	return s.presets[name]
+}
+
+// secret returns the position of the last definition of a secret
+func (s *sourceMap) secret(name string) Position {
+	if s == nil {
+		return Position{}
+	}
+	return s.secrets[name]
+}
+
+// ToolPosition returns where a tool was defined, or the zero Position when
+// the config was not loaded from YAML
+func (c *Config) ToolPosition(name string) Position {
+	return c.source.tool(name, 0)
+}
+
+// FieldPosition returns where a tool field was defined, falling back to the
+// tool itself
+func (c *Config) FieldPosition(name, key string) Position {
+	return c.source.field(name, key)
+}
+
+// PresetPosition returns where a preset was last defined
+func (c *Config) PresetPosition(name string) Position {
+	return c.source.preset(name)
+}
@@ -0,0 +1,243 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// knownDistros are os-release IDs accepted as distro override keys in a
+// platform section without further checks
+var knownDistros = map[string]bool{
+	"almalinux": true, "alpine": true, "amzn": true, "arch": true, "centos": true,
+	"debian": true, "elementary": true, "endeavouros": true, "fedora": true,
+	"gentoo": true, "kali": true, "linuxmint": true, "manjaro": true, "nixos": true,
+	"ol": true, "opensuse": true, "opensuse-leap": true, "opensuse-tumbleweed": true,
+	"pop": true, "raspbian": true, "rhel": true, "rocky": true, "sles": true,
+	"ubuntu": true, "void": true,
+}
+
+var toolType = reflect.TypeOf(Tool{})
+
+// checkDocument strictly checks a single file: unknown fields are reported
+// with a suggestion, and type mismatches are collected by decoding the
+// document. All problems are returned rather than just the first.
+func checkDocument(doc *yaml.Node, file string) Errors {
+	var errs Errors
+	if doc == nil {
+		return errs
+	}
+
+	c := &checker{file: file, errs: &errs}
+	c.walk(doc, reflect.TypeOf(Config{}), "")
+
+	// include may be a single path; its shape is checked when it is resolved
+	var cfg Config
+	err := removeKey(doc, "include").Decode(&cfg)
+
+	var typeErr *yaml.TypeError
+	if err != nil && errors.As(err, &typeErr) {
+		for _, msg := range typeErr.Errors {
+			line, text := splitYAMLMessage(msg)
+			// Values under unknown fields were already reported by the walker
+			if c.withinUnknown(line) {
+				continue
+			}
+			errs.add(Position{File: file, Line: line, Column: columnAt(doc, line)}, contextAt(doc, line), "%s", text)
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Pos.Line != errs[j].Pos.Line {
+			return errs[i].Pos.Line < errs[j].Pos.Line
+		}
+		return errs[i].Pos.Column < errs[j].Pos.Column
+	})
+
+	return errs
+}
+
+// checker walks a YAML node tree alongside the Go type it decodes into
+type checker struct {
+	file    string
+	errs    *Errors
+	unknown [][2]int // line spans of values under unknown fields
+}
+
+// withinUnknown reports whether a line belongs to an unknown field's value
+func (c *checker) withinUnknown(line int) bool {
+	for _, span := range c.unknown {
+		if line >= span[0] && line <= span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) pos(node *yaml.Node) Position {
+	return Position{File: c.file, Line: node.Line, Column: node.Column}
+}
+
+// walk checks node against type t; context names the enclosing tool
+func (c *checker) walk(node *yaml.Node, t reflect.Type, context string) {
+	node = resolveAlias(node)
+	if node == nil {
+		return
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind == yaml.MappingNode {
+			c.walkStruct(node, t, context)
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for idx, item := range node.Content {
+			itemContext := context
+			if t.Elem() == toolType {
+				itemContext = toolContext(item, idx)
+			}
+			c.walk(item, t.Elem(), itemContext)
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			itemContext := context
+			if t.Elem() == toolType {
+				itemContext = fmt.Sprintf("template %q", node.Content[i].Value)
+			}
+			c.walk(node.Content[i+1], t.Elem(), itemContext)
+		}
+	}
+}
+
+// walkStruct reports keys that do not match any field of t
+func (c *checker) walkStruct(node *yaml.Node, t reflect.Type, context string) {
+	fields, inline := yamlFields(t)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, val := node.Content[i], node.Content[i+1]
+
+		if fieldType, ok := fields[key.Value]; ok {
+			c.walk(val, fieldType, context)
+			continue
+		}
+
+		if inline != nil && isDistroKey(key.Value, resolveAlias(val), fields) {
+			c.walk(val, inline, context)
+			continue
+		}
+
+		msg := fmt.Sprintf("unknown field %q", key.Value)
+		if suggestion := closestMatch(key.Value, sortedKeys(fields)); suggestion != "" {
+			msg += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		c.errs.add(c.pos(key), context, "%s", msg)
+		c.unknown = append(c.unknown, [2]int{key.Line, lastLine(val)})
+	}
+}
+
+// isDistroKey decides whether an unknown key in a platform section is a
+// distro override rather than a misspelled field
+func isDistroKey(key string, val *yaml.Node, fields map[string]reflect.Type) bool {
+	if knownDistros[key] {
+		return true
+	}
+	return val != nil && val.Kind == yaml.MappingNode && closestMatch(key, sortedKeys(fields)) == ""
+}
+
+// toolContext labels a tool node for error messages
+func toolContext(node *yaml.Node, idx int) string {
+	if name := nodeName(node); name != "" {
+		return fmt.Sprintf("tool %q", name)
+	}
+	return fmt.Sprintf("tool at index %d", idx)
+}
+
+// yamlFields maps the yaml keys of a struct to their field types, and returns
+// the element type of an inline map field if there is one
+func yamlFields(t reflect.Type) (map[string]reflect.Type, reflect.Type) {
+	fields := make(map[string]reflect.Type)
+	var inline reflect.Type
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("yaml")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			if field.Type.Kind() == reflect.Map {
+				inline = field.Type.Elem()
+			}
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+
+	return fields, inline
+}
+
+// contextAt labels the tool that encloses a line, if any
+func contextAt(doc *yaml.Node, line int) string {
+	tools := mappingValue(doc, "tools")
+	if tools == nil || tools.Kind != yaml.SequenceNode || line > lastLine(tools) {
+		return ""
+	}
+
+	context := ""
+	for idx, tool := range tools.Content {
+		if tool.Line > line {
+			break
+		}
+		context = toolContext(tool, idx)
+	}
+	return context
+}
+
+// lastLine returns the last line covered by a node
+func lastLine(node *yaml.Node) int {
+	last := node.Line
+	for _, child := range node.Content {
+		if l := lastLine(child); l > last {
+			last = l
+		}
+	}
+	return last
+}
+
+// columnAt returns the column of the first node on the given line
+func columnAt(node *yaml.Node, line int) int {
+	if node == nil || line <= 0 {
+		return 0
+	}
+	if node.Line == line {
+		return node.Column
+	}
+	for _, child := range node.Content {
+		if col := columnAt(child, line); col > 0 {
+			return col
+		}
+	}
+	return 0
+}
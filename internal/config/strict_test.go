@@ -0,0 +1,202 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStrictUnknownFields(t *testing.T) {
+	content := `settings:
+  auto_updat_path: true
+tools:
+  - name: git
+    version: latest
+    linux:
+      packge_names:
+        apt: git
+  - name: node
+    version: latest
+    linux:
+      package_names:
+        apt: nodejs
+    post_instal:
+      - command: node --version
+`
+	_, err := LoadFromBytes([]byte(content))
+	if err == nil {
+		t.Fatal("Expected error for unknown fields")
+	}
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected Errors, got %T: %v", err, err)
+	}
+
+	expected := []string{
+		`<input>:2:3: unknown field "auto_updat_path", did you mean "auto_update_path"?`,
+		`<input>:7:7: tool "git": unknown field "packge_names", did you mean "package_names"?`,
+		`<input>:14:5: tool "node": unknown field "post_instal", did you mean "post_install"?`,
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Got %d errors, want %d:\n%v", len(errs), len(expected), err)
+	}
+	for i, want := range expected {
+		if got := errs[i].Error(); got != want {
+			t.Errorf("errs[%d] = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestStrictTypeErrors(t *testing.T) {
+	content := `tools:
+  - name: git
+    version: latest
+    linux:
+      package_names:
+        apt: git
+    post_install:
+      - command: git --version
+        wait_for: soon
+    dependencies: curl
+`
+	_, err := LoadFromBytes([]byte(content))
+	if err == nil {
+		t.Fatal("Expected error for type mismatches")
+	}
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected Errors, got %T: %v", err, err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Got %d errors, want 2:\n%v", len(errs), err)
+	}
+
+	if errs[0].Pos.Line != 9 || errs[0].Pos.Column != 9 || !strings.Contains(errs[0].Msg, "`soon` into int") {
+		t.Errorf("errs[0] = %v, want wait_for type error at 9:9", errs[0])
+	}
+	if errs[1].Pos.Line != 10 || errs[1].Context != `tool "git"` {
+		t.Errorf("errs[1] = %v, want dependencies type error in tool git at line 10", errs[1])
+	}
+}
+
+func TestStrictAcceptsDistroOverrides(t *testing.T) {
+	content := `tools:
+  - name: git
+    version: latest
+    linux:
+      package_names:
+        apt: git
+      ubuntu:
+        package_names:
+          apt: git-all
+      mycustomdistro:
+        package_names:
+          dnf: git
+`
+	cfg, err := LoadFromBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cfg.Tools[0].Linux.Distros) != 2 {
+		t.Errorf("Distros = %v, want ubuntu and mycustomdistro", cfg.Tools[0].Linux.Distros)
+	}
+}
+
+func TestStrictSkipsErrorsUnderUnknownFields(t *testing.T) {
+	content := `tools:
+  - name: git
+    version: latest
+    linux:
+      packge_names:
+        dnf: git
+`
+	_, err := LoadFromBytes([]byte(content))
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("Expected a single unknown field error, got %v", err)
+	}
+}
+
+func TestStrictErrorsAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"base.yaml": `include: [team.yaml]
+tools:
+  - name: git
+    verison: latest
+`,
+		"team.yaml": `tools:
+  - name: curl
+    linux:
+      package_names:
+        apt: curl
+    dependecies: [git]
+`,
+	})
+
+	_, err := LoadFromFile(filepath.Join(dir, "base.yaml"))
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Expected two errors, got %v", err)
+	}
+	if filepath.Base(errs[0].Pos.File) != "base.yaml" || filepath.Base(errs[1].Pos.File) != "team.yaml" {
+		t.Errorf("Errors should name their files, got %v", err)
+	}
+}
+
+func TestValidatePositions(t *testing.T) {
+	content := `tools:
+  - name: git
+    version: latest
+    linux:
+      package_names:
+        apt: git
+  - name: node
+    version: latest
+    linux:
+      package_names:
+        apt: nodejs
+    dependencies: [npmx]
+  - name: git
+    version: "2.0"
+    linux:
+      package_names:
+        apt: git
+`
+	cfg, err := LoadFromBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = Validate(cfg)
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected Errors, got %T: %v", err, err)
+	}
+
+	expected := []string{
+		`<input>:13:5: tool "git": duplicate tool name "git"`,
+		`<input>:12:5: tool "node": unknown dependency "npmx"`,
+	}
+	got := make([]string, len(errs))
+	for i, e := range errs {
+		got[i] = e.Error()
+	}
+	for _, want := range expected {
+		found := false
+		for _, g := range got {
+			if g == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Missing error %q in:\n%s", want, strings.Join(got, "\n"))
+		}
+	}
+}
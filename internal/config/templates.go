@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateParam matches {{ param }} placeholders in template fields
+var templateParam = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// expandTemplates replaces every tool that extends a template with the
+// template deep-merged under the tool, substitutes template parameters and
+// drops the templates section. The problems of every tool are reported
+// together, where the failing value is defined.
+func (l *loader) expandTemplates(doc *yaml.Node) (*yaml.Node, error) {
+	templates := mappingValue(doc, "templates")
+	tools := mappingValue(doc, "tools")
+
+	if templates == nil && tools == nil {
+		return doc, nil
+	}
+
+	if templates != nil && templates.Kind != yaml.MappingNode {
+		return nil, Errors{{Pos: l.source.position(templates), Msg: "templates must be a mapping of template names to tool definitions"}}
+	}
+
+	expanded := removeKey(doc, "templates")
+	if tools == nil || tools.Kind != yaml.SequenceNode {
+		return expanded, nil
+	}
+
+	newTools := *tools
+	newTools.Content = make([]*yaml.Node, len(tools.Content))
+
+	var errs Errors
+	for idx, tool := range tools.Content {
+		newTools.Content[idx] = l.expandTool(resolveAlias(tool), templates, &errs)
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	idx := mappingIndex(expanded, "tools")
+	expanded.Content = append([]*yaml.Node{}, expanded.Content...)
+	expanded.Content[idx+1] = &newTools
+
+	return expanded, nil
+}
+
+// expandTool applies the template a tool extends, if any. Problems are
+// added to errs and leave the tool as it is.
+func (l *loader) expandTool(tool, templates *yaml.Node, errs *Errors) *yaml.Node {
+	if tool.Kind != yaml.MappingNode {
+		return tool
+	}
+
+	extends := mappingValue(tool, "extends")
+	if extends == nil {
+		return tool
+	}
+
+	name := nodeName(tool)
+	fail := func(node *yaml.Node, format string, args ...interface{}) *yaml.Node {
+		pos := l.source.position(node)
+		if pos.File == "" {
+			pos.File = l.source.field(name, "extends").File
+		}
+		errs.add(pos, fmt.Sprintf("tool %q", name), format, args...)
+		return tool
+	}
+
+	if extends.Kind != yaml.ScalarNode || extends.Value == "" {
+		return fail(extends, "extends must be a template name")
+	}
+
+	template, err := resolveTemplate(extends.Value, templates, nil)
+	if err != nil {
+		return fail(extends, "%s", err)
+	}
+
+	params := map[string]string{"name": name}
+	if err := collectParams(template, params); err != nil {
+		return fail(mappingValue(template, "params"), "template %q: %s", extends.Value, err)
+	}
+	if err := collectParams(tool, params); err != nil {
+		return fail(mappingValue(tool, "params"), "%s", err)
+	}
+
+	if err := resolveParamReferences(params); err != nil {
+		return fail(extends, "template %q: %s", extends.Value, err)
+	}
+
+	own := removeKey(removeKey(tool, "extends"), "params")
+	merged := mergeNodes(removeKey(template, "params"), own)
+
+	failed := false
+	result := substituteParams(merged, params, func(node *yaml.Node, param string) {
+		fail(node, "template %q: undefined parameter %q", extends.Value, param)
+		failed = true
+	})
+	if failed {
+		return tool
+	}
+	return result
+}
+
+// resolveTemplate looks up a template and flattens any template it extends
+func resolveTemplate(name string, templates *yaml.Node, chain []string) (*yaml.Node, error) {
+	for _, seen := range chain {
+		if seen == name {
+			return nil, fmt.Errorf("template cycle: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+	}
+
+	var template *yaml.Node
+	if templates != nil {
+		template = mappingValue(templates, name)
+	}
+	if template == nil {
+		return nil, fmt.Errorf("unknown template %q", name)
+	}
+	if template.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("template %q must be a mapping", name)
+	}
+
+	parent := mappingValue(template, "extends")
+	if parent == nil {
+		return template, nil
+	}
+
+	base, err := resolveTemplate(parent.Value, templates, append(chain, name))
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeNodes(base, removeKey(template, "extends")), nil
+}
+
+// collectParams adds the params mapping of a node to params, overriding
+// existing values
+func collectParams(node *yaml.Node, params map[string]string) error {
+	paramsNode := mappingValue(node, "params")
+	if paramsNode == nil {
+		return nil
+	}
+
+	var values map[string]string
+	if err := paramsNode.Decode(&values); err != nil {
+		return fmt.Errorf("params must be a mapping of names to strings")
+	}
+
+	for key, val := range values {
+		params[key] = val
+	}
+	return nil
+}
+
+// resolveParamReferences expands placeholders inside parameter values so
+// template defaults can be derived from other parameters
+func resolveParamReferences(params map[string]string) error {
+	for pass := 0; pass <= len(params); pass++ {
+		changed := false
+
+		for key, val := range params {
+			var missing string
+			resolved := templateParam.ReplaceAllStringFunc(val, func(match string) string {
+				ref := templateParam.FindStringSubmatch(match)[1]
+				refVal, ok := params[ref]
+				if !ok {
+					missing = ref
+					return match
+				}
+				return refVal
+			})
+			if missing != "" {
+				return fmt.Errorf("parameter %q: undefined parameter %q", key, missing)
+			}
+			if resolved != val {
+				params[key] = resolved
+				changed = true
+			}
+		}
+
+		if !changed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("parameters reference each other in a cycle")
+}
+
+// substituteParams returns a deep copy of node with {{ param }} placeholders
+// replaced in every scalar. Each scalar using an undefined parameter is
+// reported with the first one it uses.
+func substituteParams(node *yaml.Node, params map[string]string, report func(node *yaml.Node, param string)) *yaml.Node {
+	node = resolveAlias(node)
+	copied := *node
+
+	if node.Kind == yaml.ScalarNode {
+		var missing string
+		copied.Value = templateParam.ReplaceAllStringFunc(node.Value, func(match string) string {
+			key := templateParam.FindStringSubmatch(match)[1]
+			val, ok := params[key]
+			if !ok && missing == "" {
+				missing = key
+			}
+			return val
+		})
+		if missing != "" {
+			report(node, missing)
+		}
+		return &copied
+	}
+
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for idx, child := range node.Content {
+		copied.Content[idx] = substituteParams(child, params, report)
+	}
+
+	return &copied
+}
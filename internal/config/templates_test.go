@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandTemplates(t *testing.T) {
+	content := `templates:
+  cli:
+    version: latest
+    params:
+      brew_name: "{{ package }}"
+    linux:
+      package_names:
+        apt: "{{ package }}"
+        dnf: "{{ package }}"
+    macos:
+      brew: "{{ brew_name }}"
+    windows:
+      package_names:
+        winget: "{{ winget_id }}"
+    verify_command: "{{ name }} --version"
+
+  gui:
+    extends: cli
+    requires_reboot: true
+
+tools:
+  - name: rg
+    extends: cli
+    params:
+      package: ripgrep
+      winget_id: BurntSushi.ripgrep.MSVC
+
+  - name: jq
+    extends: cli
+    description: "JSON processor"
+    params:
+      package: jq
+      winget_id: jqlang.jq
+    linux:
+      package_names:
+        pacman: jq
+    verify_command: "jq -V"
+
+  - name: slack
+    extends: gui
+    params:
+      package: slack
+      brew_name: slack-desktop
+      winget_id: SlackTechnologies.Slack
+
+  - name: git
+    version: latest
+    linux:
+      package_names:
+        apt: git
+`
+
+	cfg, err := LoadFromBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(cfg.Templates) != 0 {
+		t.Error("Templates should be cleared after expansion")
+	}
+
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("Expanded config should validate: %v", err)
+	}
+
+	rg := cfg.Tools[0]
+	if rg.Extends != "" || len(rg.Params) != 0 {
+		t.Error("extends and params should be consumed")
+	}
+	if rg.Version != "latest" {
+		t.Errorf("rg.Version = %q, want %q", rg.Version, "latest")
+	}
+	if rg.Linux.PackageNames["apt"] != "ripgrep" || rg.Linux.PackageNames["dnf"] != "ripgrep" {
+		t.Errorf("rg.Linux.PackageNames = %v", rg.Linux.PackageNames)
+	}
+	if rg.MacOS.Brew != "ripgrep" {
+		t.Errorf("rg.MacOS.Brew = %q, want default param from package", rg.MacOS.Brew)
+	}
+	if rg.Windows.PackageNames["winget"] != "BurntSushi.ripgrep.MSVC" {
+		t.Errorf("rg.Windows.PackageNames = %v", rg.Windows.PackageNames)
+	}
+	if rg.VerifyCommand != "rg --version" {
+		t.Errorf("rg.VerifyCommand = %q, want built-in name param", rg.VerifyCommand)
+	}
+
+	jq := cfg.Tools[1]
+	if jq.Description != "JSON processor" || jq.VerifyCommand != "jq -V" {
+		t.Errorf("jq should keep its own fields, got %+v", jq)
+	}
+	if jq.Linux.PackageNames["pacman"] != "jq" || jq.Linux.PackageNames["apt"] != "jq" {
+		t.Errorf("jq.Linux.PackageNames = %v, want template and own entries", jq.Linux.PackageNames)
+	}
+
+	slack := cfg.Tools[2]
+	if !slack.RequiresReboot || slack.MacOS.Brew != "slack-desktop" {
+		t.Errorf("slack should inherit through gui -> cli, got %+v", slack)
+	}
+
+	if cfg.Tools[3].Name != "git" || cfg.Tools[3].Linux.PackageNames["apt"] != "git" {
+		t.Errorf("Tools without extends should be unchanged, got %+v", cfg.Tools[3])
+	}
+}
+
+func TestExpandTemplatesFromInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"templates.yaml": `templates:
+  apt:
+    linux:
+      package_names:
+        apt: "{{ name }}"
+`,
+		"main.yaml": `include: [templates.yaml]
+tools:
+  - name: htop
+    extends: apt
+`,
+	})
+
+	cfg, err := LoadFromFile(dir + "/main.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.Tools[0].Linux.PackageNames["apt"] != "htop" {
+		t.Errorf("PackageNames = %v, want template from included file", cfg.Tools[0].Linux.PackageNames)
+	}
+}
+
+func TestExpandTemplatesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		errorMsg string
+	}{
+		{
+			name: "Unknown Template",
+			content: `tools:
+  - name: rg
+    extends: cli
+`,
+			errorMsg: `unknown template "cli"`,
+		},
+		{
+			name: "Undefined Parameter",
+			content: `templates:
+  cli:
+    linux:
+      package_names:
+        apt: "{{ package }}"
+tools:
+  - name: rg
+    extends: cli
+`,
+			errorMsg: `undefined parameter "package"`,
+		},
+		{
+			name: "Template Cycle",
+			content: `templates:
+  a:
+    extends: b
+  b:
+    extends: a
+tools:
+  - name: rg
+    extends: a
+`,
+			errorMsg: "template cycle",
+		},
+		{
+			name: "Invalid Params",
+			content: `templates:
+  cli:
+    version: latest
+tools:
+  - name: rg
+    extends: cli
+    params: [package]
+`,
+			errorMsg: "cannot unmarshal !!seq into map[string]string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFromBytes([]byte(tt.content))
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Error %q does not contain %q", err.Error(), tt.errorMsg)
+			}
+			if !strings.Contains(err.Error(), `tool "rg"`) {
+				t.Errorf("Error %q should name the tool", err.Error())
+			}
+		})
+	}
+}
+
+func TestExpandTemplatesErrorPositions(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"templates.yaml": `templates:
+  cli:
+    linux:
+      package_names:
+        apt: "{{ desc }}"
+`,
+		"main.yaml": `include: [templates.yaml]
+tools:
+  - name: rg
+    extends: cli
+  - name: fd
+    extends: missing
+`,
+	})
+
+	_, err := LoadFromFile(filepath.Join(dir, "main.yaml"))
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected Errors, got %v", err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "templates.yaml") + `:5:14: tool "rg": template "cli": undefined parameter "desc"`,
+		filepath.Join(dir, "main.yaml") + `:6:14: tool "fd": unknown template "missing"`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Errors = %v, want %d", errs, len(want))
+	}
+	for i, e := range errs {
+		if e.Error() != want[i] {
+			t.Errorf("Error %d = %q, want %q", i, e.Error(), want[i])
+		}
+	}
+}
@@ -0,0 +1,445 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// parseTOML parses TOML into a YAML node tree. Tables become mappings and
+// arrays of tables ([[tools]]) become sequences of mappings, so
+// `[[tools]]` sections map onto the same model as YAML's `tools:` list.
+func parseTOML(data []byte) (*yaml.Node, error) {
+	// The AST parser does not check table redefinitions or duplicate keys,
+	// so let the decoder validate the document first
+	var check map[string]interface{}
+	if err := toml.Unmarshal(data, &check); err != nil {
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			line, col := decodeErr.Position()
+			return nil, &Error{Pos: Position{Line: line, Column: col}, Msg: strings.TrimPrefix(decodeErr.Error(), "toml: ")}
+		}
+		return nil, errors.New(strings.TrimPrefix(err.Error(), "toml: "))
+	}
+
+	b := &tomlBuilder{root: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}}
+	b.current = b.root
+
+	b.parser.Reset(data)
+	for b.parser.NextExpression() {
+		if err := b.expression(b.parser.Expression()); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.parser.Error(); err != nil {
+		return nil, err
+	}
+
+	if len(b.root.Content) == 0 {
+		return nil, nil
+	}
+	return b.root, nil
+}
+
+// tomlBuilder assembles YAML nodes from TOML expressions
+type tomlBuilder struct {
+	parser  unstable.Parser
+	root    *yaml.Node
+	current *yaml.Node // table that key/value pairs are added to
+}
+
+// position returns where a TOML node starts, or fallback when the parser
+// did not record a range for it
+func (b *tomlBuilder) position(n *unstable.Node, fallback Position) Position {
+	if n.Raw.Length == 0 {
+		return fallback
+	}
+	start := b.parser.Shape(n.Raw).Start
+	return Position{Line: start.Line, Column: start.Column}
+}
+
+func (b *tomlBuilder) expression(expr *unstable.Node) error {
+	switch expr.Kind {
+	case unstable.KeyValue:
+		return b.keyValue(b.current, expr)
+
+	case unstable.Table:
+		table, err := b.table(expr.Key(), false)
+		if err != nil {
+			return err
+		}
+		b.current = table
+
+	case unstable.ArrayTable:
+		table, err := b.table(expr.Key(), true)
+		if err != nil {
+			return err
+		}
+		b.current = table
+	}
+	return nil
+}
+
+// table walks a [dotted.key] header from the root, creating tables as
+// needed. For [[array.tables]] a new mapping is appended to the sequence.
+func (b *tomlBuilder) table(keys unstable.Iterator, array bool) (*yaml.Node, error) {
+	node := b.root
+	for keys.Next() {
+		key := keys.Node()
+		pos := b.position(key, Position{})
+		last := keys.IsLast()
+
+		child := mappingValue(node, string(key.Data))
+		switch {
+		case child == nil && last && array:
+			child = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: pos.Line, Column: pos.Column}
+			b.append(node, key, pos, child)
+		case child == nil:
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: pos.Line, Column: pos.Column}
+			b.append(node, key, pos, child)
+		}
+
+		if child.Kind == yaml.SequenceNode {
+			if last && array {
+				item := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: pos.Line, Column: pos.Column}
+				child.Content = append(child.Content, item)
+				return item, nil
+			}
+			if len(child.Content) == 0 {
+				return nil, &Error{Pos: pos, Msg: fmt.Sprintf("%q is not a table", key.Data)}
+			}
+			child = child.Content[len(child.Content)-1]
+		}
+		if child.Kind != yaml.MappingNode {
+			return nil, &Error{Pos: pos, Msg: fmt.Sprintf("%q is not a table", key.Data)}
+		}
+		node = child
+	}
+	return node, nil
+}
+
+// keyValue adds `dotted.key = value` to a table
+func (b *tomlBuilder) keyValue(table *yaml.Node, expr *unstable.Node) error {
+	keys := expr.Key()
+	var key *unstable.Node
+	var pos Position
+	for keys.Next() {
+		key = keys.Node()
+		pos = b.position(key, pos)
+		if keys.IsLast() {
+			break
+		}
+
+		child := mappingValue(table, string(key.Data))
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: pos.Line, Column: pos.Column}
+			b.append(table, key, pos, child)
+		}
+		table = child
+	}
+
+	value, err := b.value(expr.Value(), pos)
+	if err != nil {
+		return err
+	}
+	b.append(table, key, pos, value)
+	return nil
+}
+
+func (b *tomlBuilder) append(table *yaml.Node, key *unstable.Node, pos Position, value *yaml.Node) {
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(key.Data), Line: pos.Line, Column: pos.Column}
+	table.Content = append(table.Content, keyNode, value)
+}
+
+// value converts a TOML value; values without a recorded range take the
+// position of their key
+func (b *tomlBuilder) value(n *unstable.Node, keyPos Position) (*yaml.Node, error) {
+	pos := b.position(n, keyPos)
+	node := &yaml.Node{Kind: yaml.ScalarNode, Line: pos.Line, Column: pos.Column}
+	raw := string(n.Data)
+
+	switch n.Kind {
+	case unstable.String:
+		node.Tag, node.Value = "!!str", raw
+
+	case unstable.Bool:
+		node.Tag, node.Value = "!!bool", raw
+
+	case unstable.Integer:
+		i, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 0, 64)
+		if err != nil {
+			return nil, &Error{Pos: pos, Msg: fmt.Sprintf("invalid integer %q", raw)}
+		}
+		node.Tag, node.Value = "!!int", strconv.FormatInt(i, 10)
+
+	case unstable.Float:
+		node.Tag, node.Value = "!!float", tomlFloat(strings.ReplaceAll(raw, "_", ""))
+
+	case unstable.LocalDate, unstable.LocalTime, unstable.LocalDateTime, unstable.DateTime:
+		node.Tag, node.Value = "!!str", raw
+
+	case unstable.Array:
+		node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+		for it := n.Children(); it.Next(); {
+			item, err := b.value(it.Node(), pos)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+		}
+
+	case unstable.InlineTable:
+		node.Kind, node.Tag = yaml.MappingNode, "!!map"
+		for it := n.Children(); it.Next(); {
+			if err := b.keyValue(node, it.Node()); err != nil {
+				return nil, err
+			}
+		}
+
+	default:
+		return nil, &Error{Pos: pos, Msg: fmt.Sprintf("unsupported TOML value %s", n.Kind)}
+	}
+
+	return node, nil
+}
+
+// tomlFloat converts TOML's special float values to their YAML spelling
+func tomlFloat(raw string) string {
+	switch raw {
+	case "inf", "+inf":
+		return ".inf"
+	case "-inf":
+		return "-.inf"
+	case "nan", "+nan", "-nan":
+		return ".nan"
+	}
+	return raw
+}
+
+// writeTOML writes a node tree as TOML. Mappings become [tables] and
+// sequences of mappings become [[array.tables]], so a YAML `tools:` list is
+// written as `[[tools]]` sections. Null values are omitted.
+func writeTOML(w io.Writer, node *yaml.Node) error {
+	var b bytes.Buffer
+	if err := tomlBody(&b, nil, resolveAlias(node)); err != nil {
+		return err
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// tomlBody writes the key/value pairs of a table followed by its sub-tables,
+// since TOML requires plain keys to come before any table header
+func tomlBody(b *bytes.Buffer, path []string, table *yaml.Node) error {
+	for i := 0; i+1 < len(table.Content); i += 2 {
+		key, value := table.Content[i].Value, resolveAlias(table.Content[i+1])
+		if isNull(value) || isTOMLTable(value) || isTOMLArrayTable(value) {
+			continue
+		}
+		b.WriteString(tomlKey(key) + " = ")
+		if err := tomlInline(b, value); err != nil {
+			return err
+		}
+		b.WriteByte('\n')
+	}
+
+	for i := 0; i+1 < len(table.Content); i += 2 {
+		key, value := table.Content[i].Value, resolveAlias(table.Content[i+1])
+		sub := append(append([]string{}, path...), key)
+
+		switch {
+		case isTOMLTable(value):
+			if hasTOMLPairs(value) {
+				tomlHeader(b, "["+tomlPath(sub)+"]")
+			}
+			if err := tomlBody(b, sub, value); err != nil {
+				return err
+			}
+
+		case isTOMLArrayTable(value):
+			for _, item := range value.Content {
+				tomlHeader(b, "[["+tomlPath(sub)+"]]")
+				if err := tomlBody(b, sub, resolveAlias(item)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func tomlHeader(b *bytes.Buffer, header string) {
+	if b.Len() > 0 {
+		b.WriteByte('\n')
+	}
+	b.WriteString(header + "\n")
+}
+
+// tomlInline writes a value on a single line, using inline tables for
+// mappings
+func tomlInline(b *bytes.Buffer, node *yaml.Node) error {
+	node = resolveAlias(node)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		b.WriteByte('{')
+		first := true
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if isNull(resolveAlias(node.Content[i+1])) {
+				continue
+			}
+			if !first {
+				b.WriteByte(',')
+			}
+			first = false
+			b.WriteString(" " + tomlKey(node.Content[i].Value) + " = ")
+			if err := tomlInline(b, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		if !first {
+			b.WriteByte(' ')
+		}
+		b.WriteByte('}')
+
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := tomlInline(b, item); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+
+	default:
+		val, err := scalarValue(node)
+		if err != nil {
+			return err
+		}
+		switch v := val.(type) {
+		case nil:
+			return &Error{Pos: Position{Line: node.Line, Column: node.Column}, Msg: "null cannot be represented in TOML"}
+		case string:
+			b.WriteString(tomlString(v))
+		case float64:
+			b.WriteString(tomlFloatString(v))
+		default:
+			fmt.Fprint(b, v)
+		}
+	}
+	return nil
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+}
+
+// isTOMLTable reports whether a value is written as a [table]. Empty
+// mappings are written inline as {}.
+func isTOMLTable(node *yaml.Node) bool {
+	return node.Kind == yaml.MappingNode && len(node.Content) > 0
+}
+
+// isTOMLArrayTable reports whether a value is written as [[array.tables]]
+func isTOMLArrayTable(node *yaml.Node) bool {
+	if node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+		return false
+	}
+	for _, item := range node.Content {
+		if resolveAlias(item).Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTOMLPairs reports whether a table has values of its own, so its header
+// cannot be left implicit
+func hasTOMLPairs(table *yaml.Node) bool {
+	for i := 1; i < len(table.Content); i += 2 {
+		value := resolveAlias(table.Content[i])
+		if !isTOMLTable(value) && !isTOMLArrayTable(value) {
+			return true
+		}
+	}
+	return false
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlKey quotes a key unless it is a valid bare key
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+func tomlPath(keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = tomlKey(key)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// tomlString writes a TOML basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlFloatString formats a float so it reads back as a float
+func tomlFloatString(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
@@ -2,42 +2,175 @@ package config
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/condition"
+	"github.com/araldhafeeri/stackup/internal/domain"
 )
 
-// Validate checks if the configuration is valid
+// Validate checks if the configuration is valid. It reports every problem
+// it finds as Errors, positioned at the originating file when the config
+// was loaded from disk.
 func Validate(cfg *Config) error {
+	var errs Errors
+
 	if len(cfg.Tools) == 0 {
-		return fmt.Errorf("no tools defined in configuration")
+		errs.add(Position{}, "", "no tools defined in configuration")
+		return errs
 	}
 
-	toolNames := make(map[string]bool)
+	occurrences := make(map[string]int)
 
 	for i, tool := range cfg.Tools {
 		// Check for required fields
 		if tool.Name == "" {
-			return fmt.Errorf("tool at index %d missing name", i)
+			errs.add(Position{}, "", "tool at index %d missing name", i)
+			continue
 		}
 
+		context := fmt.Sprintf("tool %q", tool.Name)
+
 		// Check for duplicate tool names
-		if toolNames[tool.Name] {
-			return fmt.Errorf("duplicate tool name: %s", tool.Name)
+		occurrences[tool.Name]++
+		if n := occurrences[tool.Name]; n > 1 {
+			errs.add(cfg.source.tool(tool.Name, n-1), context, "duplicate tool name %q", tool.Name)
+			continue
 		}
-		toolNames[tool.Name] = true
 
 		// Validate dependencies exist
 		for _, dep := range tool.Dependencies {
-			if !toolNames[dep] && !hasTool(cfg.Tools, dep) {
-				return fmt.Errorf("tool %s has unknown dependency: %s", tool.Name, dep)
+			if !hasTool(cfg.Tools, dep) {
+				errs.add(cfg.source.field(tool.Name, "dependencies"), context, "unknown dependency %q", dep)
 			}
 		}
 
 		// Check that at least one platform is configured
-		if tool.Windows == nil && tool.Linux == nil && tool.MacOS == nil && len(tool.CustomInstall) == 0 {
-			return fmt.Errorf("tool %s has no platform configuration", tool.Name)
+		if tool.Windows == nil && tool.Linux == nil && tool.MacOS == nil && len(tool.CustomInstall) == 0 &&
+			!domain.IsLanguagePackageManager(tool.Manager) {
+			errs.add(cfg.source.tool(tool.Name, 0), context, "no platform configuration")
+		}
+
+		// Distro overrides cannot have overrides of their own
+		for _, platform := range tool.platformSections() {
+			if platform.cfg == nil {
+				continue
+			}
+			for _, distro := range sortedKeys(platform.cfg.Distros) {
+				if override := platform.cfg.Distros[distro]; override != nil && len(override.Distros) > 0 {
+					errs.add(cfg.source.field(tool.Name, platform.name), context,
+						"%s.%s: nested distro overrides are not supported", platform.name, distro)
+				}
+			}
+		}
+
+		// Check flatpak scopes, which otherwise install system-wide
+		for _, platform := range tool.platformSections() {
+			if where := invalidFlatpakScope(platform.name, platform.cfg); where != "" {
+				errs.add(cfg.source.field(tool.Name, platform.name), context,
+					"%s.flatpak.scope must be one of %s", where, strings.Join(FlatpakScopes, ", "))
+			}
+		}
+
+		// Check repositories, whose names become file names
+		for _, platform := range tool.platformSections() {
+			for _, problem := range invalidRepositories(platform.name, tool.Manager, platform.cfg) {
+				errs.add(cfg.source.field(tool.Name, platform.name), context, "%s", problem)
+			}
+		}
+
+		// Check that all when expressions compile
+		if where, err := validateConditions(&tool); err != nil {
+			field := where
+			if idx := strings.IndexAny(where, ".["); idx >= 0 {
+				field = where[:idx]
+			}
+			errs.add(cfg.source.field(tool.Name, field), context, "invalid when expression in %s: %v", where, err)
+		}
+	}
+
+	for _, manager := range cfg.Settings.ManagerPriority {
+		if !slices.Contains(SystemPackageManagers(), manager) {
+			errs.add(Position{}, "settings", "manager_priority: unknown system package manager %q; expected one of %s",
+				manager, strings.Join(SystemPackageManagers(), ", "))
+		}
+	}
+
+	if cfg.Settings.IndexMaxAge < 0 {
+		errs.add(Position{}, "settings", "index_max_age: must be a number of minutes, got %d", cfg.Settings.IndexMaxAge)
+	}
+
+	validateSecrets(cfg, &errs)
+
+	return errs.err()
+}
+
+// invalidFlatpakScope returns the path of a section, or of one of its
+// distro overrides, with an unknown flatpak scope
+func invalidFlatpakScope(where string, p *PlatformConfig) string {
+	if p == nil {
+		return ""
+	}
+	if p.Flatpak != nil && p.Flatpak.Scope != "" && !slices.Contains(FlatpakScopes, p.Flatpak.Scope) {
+		return where
+	}
+	for _, distro := range sortedKeys(p.Distros) {
+		if found := invalidFlatpakScope(where+"."+distro, p.Distros[distro]); found != "" {
+			return found
+		}
+	}
+	return ""
+}
+
+// repositoryName matches repository names, which are file names, or
+// user/repo for brew taps
+var repositoryName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(/[A-Za-z0-9][A-Za-z0-9._-]*)?$`)
+
+// fingerprint matches OpenPGP v4 and v6 fingerprints once spaces are removed
+var fingerprint = regexp.MustCompile(`^([0-9A-Fa-f]{40}|[0-9A-Fa-f]{64})$`)
+
+// invalidRepositories describes the problems of the repositories of a
+// section and of its distro overrides. Repositories without a manager are
+// for the tool's manager, when it names one.
+func invalidRepositories(where, toolManager string, p *PlatformConfig) []string {
+	if p == nil {
+		return nil
+	}
+
+	var problems []string
+	for idx, repo := range p.Repositories {
+		at := fmt.Sprintf("%s.repositories[%d]", where, idx)
+		tap := strings.Contains(repo.Name, "/")
+		switch {
+		case repo.Name == "":
+			problems = append(problems, at+": name is required")
+		case !repositoryName.MatchString(repo.Name) || tap && repo.Manager != "" && repo.Manager != domain.PackageManagerBrew:
+			problems = append(problems, fmt.Sprintf("%s: name %q must be a file name, or user/repo for a brew tap", at, repo.Name))
+		}
+		manager := repo.Manager
+		if manager == "" {
+			manager = toolManager
+		}
+		if manager != "" && !slices.Contains(RepositoryManagers, manager) {
+			problems = append(problems, fmt.Sprintf("%s: manager must be one of %s", at, strings.Join(RepositoryManagers, ", ")))
+		}
+		if repo.URL == "" && !tap && repo.Manager != domain.PackageManagerBrew {
+			problems = append(problems, at+": url is required")
+		}
+		if (repo.Key == "") != (repo.Fingerprint == "") {
+			problems = append(problems, at+": key and fingerprint must be set together, so the key is pinned")
+		}
+		if repo.Fingerprint != "" && !fingerprint.MatchString(strings.Join(strings.Fields(repo.Fingerprint), "")) {
+			problems = append(problems, fmt.Sprintf("%s: fingerprint %q must be 40 or 64 hexadecimal digits", at, repo.Fingerprint))
 		}
 	}
 
-	return nil
+	for _, distro := range sortedKeys(p.Distros) {
+		problems = append(problems, invalidRepositories(where+"."+distro, toolManager, p.Distros[distro])...)
+	}
+	return problems
 }
 
 func hasTool(tools []Tool, name string) bool {
@@ -47,4 +180,82 @@ func hasTool(tools []Tool, name string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// validateConditions parses every when expression attached to a tool, its
+// platform sections and its commands, returning where the first bad one is
+func validateConditions(tool *Tool) (string, error) {
+	check := func(expr string) error {
+		if expr == "" {
+			return nil
+		}
+		if _, err := condition.Parse(expr); err != nil {
+			return fmt.Errorf("%q: %w", expr, err)
+		}
+		return nil
+	}
+
+	checkCommands := func(where string, commands []Command) (string, error) {
+		for idx, cmd := range commands {
+			if err := check(cmd.When); err != nil {
+				return fmt.Sprintf("%s[%d]", where, idx), err
+			}
+		}
+		return "", nil
+	}
+
+	if err := check(tool.When); err != nil {
+		return "when", err
+	}
+
+	for _, stage := range []struct {
+		name     string
+		commands []Command
+	}{
+		{"pre_install", tool.PreInstall},
+		{"custom_install", tool.CustomInstall},
+		{"post_install", tool.PostInstall},
+	} {
+		if where, err := checkCommands(stage.name, stage.commands); err != nil {
+			return where, err
+		}
+	}
+
+	for _, platform := range tool.platformSections() {
+		if platform.cfg == nil {
+			continue
+		}
+		if err := check(platform.cfg.When); err != nil {
+			return platform.name + ".when", err
+		}
+		if where, err := checkCommands(platform.name+".custom_commands", platform.cfg.CustomCommands); err != nil {
+			return where, err
+		}
+
+		for _, distro := range sortedKeys(platform.cfg.Distros) {
+			override := platform.cfg.Distros[distro]
+			if override == nil {
+				continue
+			}
+			where := platform.name + "." + distro
+			if err := check(override.When); err != nil {
+				return where + ".when", err
+			}
+			if where, err := checkCommands(where+".custom_commands", override.CustomCommands); err != nil {
+				return where, err
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -104,6 +104,189 @@ func TestValidate(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "Valid When Expressions",
+			config: &Config{
+				Tools: []Tool{
+					{
+						Name:  "tool",
+						When:  `arch == "arm64"`,
+						Linux: &PlatformConfig{When: `distro == "ubuntu" && distro_version >= "22.04"`},
+						PostInstall: []Command{
+							{Command: "echo", When: `env.WSL_DISTRO_NAME != ""`},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid Tool When",
+			config: &Config{
+				Tools: []Tool{
+					{Name: "tool", When: `arch = "arm64"`, Linux: &PlatformConfig{}},
+				},
+			},
+			expectError: true,
+			errorMsg:    "invalid when expression",
+		},
+		{
+			name: "Invalid Platform When",
+			config: &Config{
+				Tools: []Tool{
+					{Name: "tool", Linux: &PlatformConfig{When: `kernel == "6"`}},
+				},
+			},
+			expectError: true,
+			errorMsg:    "linux.when",
+		},
+		{
+			name: "Invalid Command When",
+			config: &Config{
+				Tools: []Tool{
+					{
+						Name: "tool",
+						CustomInstall: []Command{
+							{Command: "echo"},
+							{Command: "echo", When: `file_exists(/tmp)`},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "custom_install[1]",
+		},
+		{
+			name: "Invalid Distro Override When",
+			config: &Config{
+				Tools: []Tool{
+					{
+						Name: "tool",
+						Linux: &PlatformConfig{
+							Distros: map[string]*PlatformConfig{
+								"ubuntu": {When: `distro_version >>= 22`},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "linux.ubuntu.when",
+		},
+		{
+			name: "Nested Distro Override",
+			config: &Config{
+				Tools: []Tool{
+					{
+						Name: "tool",
+						Linux: &PlatformConfig{
+							Distros: map[string]*PlatformConfig{
+								"ubuntu": {Distros: map[string]*PlatformConfig{"debian": {}}},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "nested distro overrides",
+		},
+		{
+			name: "Flatpak Scope",
+			config: &Config{
+				Tools: []Tool{
+					{Name: "slack", Linux: &PlatformConfig{Flatpak: &FlatpakOptions{Scope: "user"}}},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid Flatpak Scope",
+			config: &Config{
+				Tools: []Tool{
+					{
+						Name: "slack",
+						Linux: &PlatformConfig{
+							Distros: map[string]*PlatformConfig{
+								"fedora": {Flatpak: &FlatpakOptions{Scope: "global"}},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "linux.fedora.flatpak.scope must be one of system, user",
+		},
+		{
+			name: "Repositories",
+			config: &Config{
+				Tools: []Tool{
+					{
+						Name: "terraform",
+						Linux: &PlatformConfig{Repositories: []Repository{{
+							Name:        "hashicorp",
+							URL:         "https://apt.releases.hashicorp.com",
+							Key:         "https://apt.releases.hashicorp.com/gpg",
+							Fingerprint: "798A EC65 4E5C 1542 8C8E  42EE AA16 FCBC A621 E701",
+						}}},
+						MacOS: &PlatformConfig{Repositories: []Repository{{Name: "hashicorp/tap"}}},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Unpinned Repository Key",
+			config: &Config{
+				Tools: []Tool{
+					{
+						Name: "gh",
+						Linux: &PlatformConfig{Distros: map[string]*PlatformConfig{
+							"ubuntu": {Repositories: []Repository{{Name: "github-cli", URL: "https://cli.github.com/packages", Key: "https://cli.github.com/packages/githubcli-archive-keyring.gpg"}}},
+						}},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "linux.ubuntu.repositories[0]: key and fingerprint must be set together",
+		},
+		{
+			name: "Manager Priority",
+			config: &Config{
+				Settings: Settings{ManagerPriority: []string{"flatpak", "cargo"}},
+				Tools:    []Tool{{Name: "git", Linux: &PlatformConfig{}}},
+			},
+			expectError: true,
+			errorMsg:    `manager_priority: unknown system package manager "cargo"`,
+		},
+		{
+			name: "Negative Index Max Age",
+			config: &Config{
+				Settings: Settings{IndexMaxAge: -5},
+				Tools:    []Tool{{Name: "git", Linux: &PlatformConfig{}}},
+			},
+			expectError: true,
+			errorMsg:    "index_max_age: must be a number of minutes, got -5",
+		},
+		{
+			name: "Invalid Repository",
+			config: &Config{
+				Tools: []Tool{
+					{Name: "tool", Linux: &PlatformConfig{Repositories: []Repository{{Name: "../sources", Manager: "pacman"}}}},
+				},
+			},
+			expectError: true,
+			errorMsg:    `name "../sources" must be a file name`,
+		},
+		{
+			name: "Repository For The Tool's Manager",
+			config: &Config{
+				Tools: []Tool{
+					{Name: "tool", Manager: "gem", Linux: &PlatformConfig{Repositories: []Repository{{Name: "vendor", URL: "https://gems.example.com"}}}},
+				},
+			},
+			expectError: true,
+			errorMsg:    "linux.repositories[0]: manager must be one of apt, dnf, yum, zypper, brew",
+		},
 	}
 
 	for _, tt := range tests {
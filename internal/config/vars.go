@@ -0,0 +1,259 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// varReference matches ${name} and ${env:NAME}. A doubled $ escapes the
+// reference, so shell expansions can be written as $${HOME}.
+var varReference = regexp.MustCompile(`\$(\$?)\{([^}]*)\}`)
+
+// varName is the syntax of a variable name
+var varName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// interpolate resolves the vars section, applying overrides, and substitutes
+// ${...} references in every string of the tools section. All undefined
+// references are reported together.
+func (l *loader) interpolate(doc *yaml.Node) (*yaml.Node, error) {
+	raw := make(map[string]string)
+	varsNode := mappingValue(doc, "vars")
+	if varsNode != nil {
+		if err := varsNode.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("vars must be a mapping of names to strings")
+		}
+	}
+	for name, val := range l.opts.Vars {
+		raw[name] = val
+	}
+
+	if len(raw) == 0 && !hasReference(mappingValue(doc, "tools")) {
+		return doc, nil
+	}
+
+	r := &varResolver{raw: raw, resolved: make(map[string]string)}
+	var errs Errors
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err := r.resolve(name, nil); err != nil {
+			pos := l.source.vars[name]
+			if varsNode != nil {
+				if val := mappingValue(varsNode, name); val != nil {
+					pos.Line, pos.Column = val.Line, val.Column
+				}
+			}
+			errs.add(pos, fmt.Sprintf("var %q", name), "%s", err)
+		}
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	result := doc
+	if len(r.resolved) > 0 {
+		resolvedVars := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if varsNode != nil {
+			resolvedVars.Line, resolvedVars.Column = varsNode.Line, varsNode.Column
+		}
+		for _, name := range names {
+			resolvedVars.Content = append(resolvedVars.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: r.resolved[name]})
+		}
+		result = setKey(result, "vars", resolvedVars)
+	}
+
+	tools := mappingValue(doc, "tools")
+	if tools == nil || tools.Kind != yaml.SequenceNode {
+		return result, nil
+	}
+
+	newTools := *tools
+	newTools.Content = make([]*yaml.Node, len(tools.Content))
+	for idx, tool := range tools.Content {
+		tool = resolveAlias(tool)
+		name := nodeName(tool)
+		if tool.Kind != yaml.MappingNode {
+			newTools.Content[idx] = tool
+			continue
+		}
+
+		copied := *tool
+		copied.Content = make([]*yaml.Node, len(tool.Content))
+		for i := 0; i+1 < len(tool.Content); i += 2 {
+			key := tool.Content[i]
+			file := l.source.field(name, key.Value).File
+			copied.Content[i] = key
+			copied.Content[i+1] = r.substitute(tool.Content[i+1], func(node *yaml.Node, err error) {
+				errs.add(Position{File: file, Line: node.Line, Column: node.Column}, fmt.Sprintf("tool %q", name), "%s", err)
+			})
+		}
+		newTools.Content[idx] = &copied
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	return setKey(result, "tools", &newTools), nil
+}
+
+// varResolver expands variables that may reference each other
+type varResolver struct {
+	raw      map[string]string
+	resolved map[string]string
+}
+
+// resolve returns the value of a variable with its references expanded
+func (r *varResolver) resolve(name string, chain []string) (string, error) {
+	if val, ok := r.resolved[name]; ok {
+		return val, nil
+	}
+
+	for _, seen := range chain {
+		if seen == name {
+			return "", fmt.Errorf("variable cycle: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+	}
+
+	raw, ok := r.raw[name]
+	if !ok {
+		return "", r.undefined(name)
+	}
+
+	val, err := r.expand(raw, append(chain, name))
+	if err != nil {
+		return "", err
+	}
+	r.resolved[name] = val
+	return val, nil
+}
+
+// expand substitutes every reference in s. chain lists the variables being
+// resolved, for cycle detection.
+func (r *varResolver) expand(s string, chain []string) (string, error) {
+	var firstErr error
+	result := varReference.ReplaceAllStringFunc(s, func(match string) string {
+		parts := varReference.FindStringSubmatch(match)
+		if parts[1] != "" {
+			return match[1:]
+		}
+		if firstErr != nil {
+			return match
+		}
+
+		ref := strings.TrimSpace(parts[2])
+		if strings.HasPrefix(ref, "secret:") {
+			// Secrets are resolved at install time, never while loading
+			return match
+		}
+		if env, ok := strings.CutPrefix(ref, "env:"); ok {
+			val, found := os.LookupEnv(env)
+			if !found {
+				firstErr = fmt.Errorf("undefined environment variable %q", env)
+			}
+			return val
+		}
+
+		if !varName.MatchString(ref) {
+			firstErr = fmt.Errorf("invalid variable reference %q", match)
+			return match
+		}
+
+		val, err := r.resolve(ref, chain)
+		if err != nil {
+			firstErr = err
+		}
+		return val
+	})
+	return result, firstErr
+}
+
+// undefined reports an unknown variable with the closest defined name
+func (r *varResolver) undefined(name string) error {
+	names := make([]string, 0, len(r.raw))
+	for n := range r.raw {
+		names = append(names, n)
+	}
+	if suggestion := closestMatch(name, names); suggestion != "" {
+		return fmt.Errorf("undefined variable %q, did you mean %q?", name, suggestion)
+	}
+	return fmt.Errorf("undefined variable %q", name)
+}
+
+// substitute returns a copy of node with references expanded in every
+// string value. Mapping keys are left untouched.
+func (r *varResolver) substitute(node *yaml.Node, report func(*yaml.Node, error)) *yaml.Node {
+	node = resolveAlias(node)
+	copied := *node
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.ShortTag() != "!!str" {
+			return node
+		}
+		val, err := r.expand(node.Value, nil)
+		if err != nil {
+			report(node, err)
+			return node
+		}
+		copied.Value = val
+
+	case yaml.MappingNode:
+		copied.Content = make([]*yaml.Node, len(node.Content))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			copied.Content[i] = node.Content[i]
+			copied.Content[i+1] = r.substitute(node.Content[i+1], report)
+		}
+
+	case yaml.SequenceNode:
+		copied.Content = make([]*yaml.Node, len(node.Content))
+		for i, item := range node.Content {
+			copied.Content[i] = r.substitute(item, report)
+		}
+	}
+
+	return &copied
+}
+
+// hasReference reports whether any string below node contains a ${ reference
+func hasReference(node *yaml.Node) bool {
+	node = resolveAlias(node)
+	if node == nil {
+		return false
+	}
+	if node.Kind == yaml.ScalarNode {
+		return strings.Contains(node.Value, "${")
+	}
+	for _, child := range node.Content {
+		if hasReference(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// setKey returns a copy of mapping with key set to value
+func setKey(mapping *yaml.Node, key string, value *yaml.Node) *yaml.Node {
+	copied := *mapping
+	copied.Content = append([]*yaml.Node{}, mapping.Content...)
+
+	if idx := mappingIndex(&copied, key); idx >= 0 {
+		copied.Content[idx+1] = value
+		return &copied
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	copied.Content = append(copied.Content, keyNode, value)
+	return &copied
+}
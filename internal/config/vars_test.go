@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInterpolateVars(t *testing.T) {
+	t.Setenv("STACKUP_TEST_PREFIX", "/opt/tools")
+
+	content := `vars:
+  node_version: "20.11"
+  mirror: https://mirror.internal
+  node_url: ${mirror}/node/v${node_version}
+tools:
+  - name: node
+    version: ${node_version}
+    linux:
+      installer: ${node_url}/node.tar.gz
+      package_names:
+        apt: nodejs=${node_version}
+    post_install:
+      - command: ln
+        args: ["-s", "${env:STACKUP_TEST_PREFIX}/node", "$${HOME}/bin/node"]
+        wait_for: 5
+`
+	cfg, err := LoadFromBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("LoadFromBytes() error: %v", err)
+	}
+
+	node := cfg.Tools[0]
+	if node.Version != "20.11" {
+		t.Errorf("Version = %q", node.Version)
+	}
+	if node.Linux.Installer != "https://mirror.internal/node/v20.11/node.tar.gz" {
+		t.Errorf("Installer = %q", node.Linux.Installer)
+	}
+	if node.Linux.PackageNames["apt"] != "nodejs=20.11" {
+		t.Errorf("PackageNames = %v", node.Linux.PackageNames)
+	}
+	if args := node.PostInstall[0].Args; args[1] != "/opt/tools/node" || args[2] != "${HOME}/bin/node" {
+		t.Errorf("Args = %v", args)
+	}
+	if cfg.Vars["node_url"] != "https://mirror.internal/node/v20.11" {
+		t.Errorf("Vars should be stored resolved, got %v", cfg.Vars)
+	}
+}
+
+func TestInterpolateOverrides(t *testing.T) {
+	content := "vars:\n  version: \"1.0\"\ntools:\n  - name: tool\n    version: v${version}-${channel}\n"
+
+	cfg, err := Load(LoadOptions{Stdin: strings.NewReader(content), Vars: map[string]string{"version": "2.0", "channel": "beta"}}, "-")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Tools[0].Version != "v2.0-beta" {
+		t.Errorf("Version = %q, want v2.0-beta", cfg.Tools[0].Version)
+	}
+}
+
+func TestInterpolateAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"base.yaml": "vars:\n  host: base.example.com\ntools:\n  - name: git\n    version: latest\n    linux:\n      installer: https://${host}/git.deb\n",
+		"team.yaml": "include: base.yaml\nvars:\n  host: team.example.com\n",
+	})
+
+	cfg, err := LoadFromFile(filepath.Join(dir, "team.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFromFile() error: %v", err)
+	}
+	if got := cfg.Tools[0].Linux.Installer; got != "https://team.example.com/git.deb" {
+		t.Errorf("Installer = %q, want the overriding host", got)
+	}
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "undefined variables are all reported",
+			content: "vars:\n  version: \"1\"\ntools:\n  - name: a\n    version: ${verison}\n  - name: b\n    version: ${missing}\n",
+			want: []string{
+				`<input>:5:14: tool "a": undefined variable "verison", did you mean "version"?`,
+				`<input>:7:14: tool "b": undefined variable "missing"`,
+			},
+		},
+		{
+			name:    "undefined environment variable",
+			content: "tools:\n  - name: a\n    version: ${env:STACKUP_TEST_UNSET}\n",
+			want:    []string{`<input>:3:14: tool "a": undefined environment variable "STACKUP_TEST_UNSET"`},
+		},
+		{
+			name:    "cycle",
+			content: "vars:\n  a: ${b}\n  b: ${a}\n",
+			want: []string{
+				`<input>:2:6: var "a": variable cycle: a -> b -> a`,
+				`<input>:3:6: var "b": variable cycle: b -> a -> b`,
+			},
+		},
+		{
+			name:    "invalid reference",
+			content: "tools:\n  - name: a\n    version: ${not valid}\n",
+			want:    []string{`<input>:3:14: tool "a": invalid variable reference "${not valid}"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFromBytes([]byte(tt.content))
+
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Expected Errors, got %v", err)
+			}
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Got %d errors, want %d:\n%v", len(errs), len(tt.want), err)
+			}
+			for i, want := range tt.want {
+				if errs[i].Error() != want {
+					t.Errorf("errs[%d] = %q, want %q", i, errs[i].Error(), want)
+				}
+			}
+		})
+	}
+}
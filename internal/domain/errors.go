@@ -15,6 +15,9 @@ var (
 	// ErrDependencyNotFound indicates a dependency was not found
 	ErrDependencyNotFound = errors.New("dependency not found")
 
+	// ErrDependencyCycle indicates tools that depend on each other
+	ErrDependencyCycle = errors.New("dependency cycle")
+
 	// ErrVerificationFailed indicates installation verification failed
 	ErrVerificationFailed = errors.New("verification failed")
 )
@@ -25,5 +28,6 @@ func IsPreflightError(err error) bool {
 		errors.Is(err, ErrNoPlatformConfig) ||
 		errors.Is(err, ErrNoInstallMethod) ||
 		errors.Is(err, ErrDependencyNotFound) ||
+		errors.Is(err, ErrDependencyCycle) ||
 		errors.Is(err, ErrVerificationFailed)
 }
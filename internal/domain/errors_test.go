@@ -30,6 +30,11 @@ func TestPreflightChecks(t *testing.T) {
 			error:    ErrDependencyNotFound,
 			expected: true,
 		},
+		{
+			name:     "ErrDependencyCycle",
+			error:    ErrDependencyCycle,
+			expected: true,
+		},
 		{
 			name:     "ErrVerificationFailed",
 			error:    ErrVerificationFailed,
@@ -1,23 +1,103 @@
 // Package domain contains core domain models
 package domain
 
+import "slices"
+
 // System represents the detected system information
 type System struct {
-	OS             string
-	Arch           string
-	PackageManager string
+	OS              string
+	Arch            string
+	PackageManager  string   // the default package manager
+	PackageManagers []string // every system package manager available, in order of preference
+	Distro          string   // Linux distribution ID, e.g. "ubuntu"
+	DistroVersion   string   // Linux distribution version, e.g. "22.04"
+	DistroCodename  string   // Linux distribution codename, e.g. "jammy"
+	DistroLike      []string // Parent distributions from ID_LIKE, e.g. ["debian"]
+	KernelVersion   string
+	Libc            string // "glibc" or "musl" on Linux
+	IsWSL           bool
+	IsContainer     bool
 }
 
+// Libc flavors
+const (
+	LibcGlibc = "glibc"
+	LibcMusl  = "musl"
+)
+
 // PackageManager types
 const (
-	PackageManagerAPT    = "apt"
-	PackageManagerDNF    = "dnf"
-	PackageManagerPacman = "pacman"
-	PackageManagerBrew   = "brew"
-	PackageManagerWinget = "winget"
-	PackageManagerChoco  = "choco"
+	PackageManagerAPT     = "apt"
+	PackageManagerDNF     = "dnf"
+	PackageManagerYum     = "yum"
+	PackageManagerPacman  = "pacman"
+	PackageManagerZypper  = "zypper"
+	PackageManagerAPK     = "apk"
+	PackageManagerXBPS    = "xbps"
+	PackageManagerEmerge  = "emerge"
+	PackageManagerSnap    = "snap"
+	PackageManagerFlatpak = "flatpak"
+	PackageManagerBrew    = "brew"
+	PackageManagerNix     = "nix"
+	PackageManagerWinget  = "winget"
+	PackageManagerChoco   = "choco"
+
+	PackageManagerPipx  = "pipx"
+	PackageManagerNPM   = "npm"
+	PackageManagerCargo = "cargo"
+	PackageManagerGo    = "go"
+	PackageManagerGem   = "gem"
+	PackageManagerMise  = "mise"
+	PackageManagerAsdf  = "asdf"
 )
 
+// PackageManagers lists every supported package manager: the system
+// managers in detection order, then the language and version managers
+var PackageManagers = []string{
+	PackageManagerAPT,
+	PackageManagerDNF,
+	PackageManagerYum,
+	PackageManagerPacman,
+	PackageManagerZypper,
+	PackageManagerAPK,
+	PackageManagerXBPS,
+	PackageManagerEmerge,
+	PackageManagerSnap,
+	PackageManagerFlatpak,
+	PackageManagerBrew,
+	PackageManagerNix,
+	PackageManagerWinget,
+	PackageManagerChoco,
+	PackageManagerPipx,
+	PackageManagerNPM,
+	PackageManagerCargo,
+	PackageManagerGo,
+	PackageManagerGem,
+	PackageManagerMise,
+	PackageManagerAsdf,
+}
+
+// LanguageRuntimes maps the language package managers, and the version
+// managers installing language runtimes, to the tool that provides them.
+// They install into the user's environment on every OS and are only used
+// for tools that name them, never as the system's manager.
+var LanguageRuntimes = map[string]string{
+	PackageManagerPipx:  "pipx",
+	PackageManagerNPM:   "node",
+	PackageManagerCargo: "rust",
+	PackageManagerGo:    "go",
+	PackageManagerGem:   "ruby",
+	PackageManagerMise:  "mise",
+	PackageManagerAsdf:  "asdf",
+}
+
+// IsLanguagePackageManager reports whether a package manager installs the
+// packages or runtimes of a language ecosystem
+func IsLanguagePackageManager(name string) bool {
+	_, ok := LanguageRuntimes[name]
+	return ok
+}
+
 // IsLinux returns true if the system is Linux
 func (s *System) IsLinux() bool {
 	return s.OS == "linux"
@@ -36,4 +116,56 @@ func (s *System) IsMacOS() bool {
 // HasPackageManager returns true if a package manager is available
 func (s *System) HasPackageManager() bool {
 	return s.PackageManager != ""
-}
\ No newline at end of file
+}
+
+// AvailablePackageManagers returns the system package managers available,
+// in order of preference. Systems built without detection only have their
+// default manager.
+func (s *System) AvailablePackageManagers() []string {
+	if len(s.PackageManagers) == 0 && s.PackageManager != "" {
+		return []string{s.PackageManager}
+	}
+	return s.PackageManagers
+}
+
+// PreferPackageManagers moves the available managers named in priority to
+// the front, in its order, and makes the first the default. Managers that
+// are not available are ignored.
+func (s *System) PreferPackageManagers(priority []string) {
+	available := s.AvailablePackageManagers()
+	ordered := make([]string, 0, len(available))
+	for _, manager := range priority {
+		if slices.Contains(available, manager) && !slices.Contains(ordered, manager) {
+			ordered = append(ordered, manager)
+		}
+	}
+	for _, manager := range available {
+		if !slices.Contains(ordered, manager) {
+			ordered = append(ordered, manager)
+		}
+	}
+
+	s.PackageManagers = ordered
+	if len(ordered) > 0 {
+		s.PackageManager = ordered[0]
+	}
+}
+
+// DistroIDs returns the distribution ID followed by its ID_LIKE parents,
+// most specific first
+func (s *System) DistroIDs() []string {
+	if s.Distro == "" {
+		return s.DistroLike
+	}
+	return append([]string{s.Distro}, s.DistroLike...)
+}
+
+// IsDistro returns true if the system is the given distribution or derives from it
+func (s *System) IsDistro(id string) bool {
+	for _, candidate := range s.DistroIDs() {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
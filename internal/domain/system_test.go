@@ -33,3 +33,31 @@ func TestSystemChecks(t *testing.T) {
 		})
 	}
 }
+
+func TestDistroIDs(t *testing.T) {
+	sys := &System{OS: "linux", Distro: "rocky", DistroLike: []string{"rhel", "centos", "fedora"}}
+
+	assert.Equal(t, []string{"rocky", "rhel", "centos", "fedora"}, sys.DistroIDs())
+	assert.True(t, sys.IsDistro("rocky"))
+	assert.True(t, sys.IsDistro("fedora"))
+	assert.False(t, sys.IsDistro("debian"))
+
+	empty := &System{OS: "darwin"}
+	assert.Empty(t, empty.DistroIDs())
+	assert.False(t, empty.IsDistro(""))
+}
+
+func TestPreferPackageManagers(t *testing.T) {
+	sys := &System{OS: "linux", PackageManager: "apt", PackageManagers: []string{"apt", "snap", "flatpak", "brew"}}
+
+	sys.PreferPackageManagers([]string{"nix", "brew", "flatpak", "brew"})
+	assert.Equal(t, []string{"brew", "flatpak", "apt", "snap"}, sys.PackageManagers)
+	assert.Equal(t, "brew", sys.PackageManager)
+
+	sys.PreferPackageManagers(nil)
+	assert.Equal(t, []string{"brew", "flatpak", "apt", "snap"}, sys.PackageManagers)
+
+	// Without detection, the default manager is the only one available
+	assert.Equal(t, []string{"dnf"}, (&System{PackageManager: "dnf"}).AvailablePackageManagers())
+	assert.Empty(t, (&System{}).AvailablePackageManagers())
+}
@@ -4,15 +4,20 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/araldhafeeri/stackup/internal/condition"
 	"github.com/araldhafeeri/stackup/internal/config"
 	"github.com/araldhafeeri/stackup/internal/domain"
+	"github.com/araldhafeeri/stackup/internal/secret"
 )
 
 // CommandRunner handles execution of shell commands
 type CommandRunner struct {
-	system *domain.System
+	system  *domain.System
+	secrets *secret.Store
 }
 
 // NewCommandRunner creates a new command runner
@@ -29,11 +34,19 @@ func (r *CommandRunner) Run(commands []config.Command, stage string) error {
 	fmt.Printf("   Running %s commands...\n", stage)
 
 	for _, cmdDef := range commands {
+		if !r.conditionMet(cmdDef) {
+			fmt.Printf("   → %s skipped: condition false\n", cmdDef.Command)
+			continue
+		}
+
 		if cmdDef.Description != "" {
 			fmt.Printf("   → %s\n", cmdDef.Description)
 		}
 
-		cmd := r.buildCommand(cmdDef)
+		cmd, err := r.buildCommand(cmdDef)
+		if err != nil {
+			return fmt.Errorf("command '%s': %w", cmdDef.Command, err)
+		}
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
@@ -54,12 +67,50 @@ func (r *CommandRunner) Run(commands []config.Command, stage string) error {
 	return nil
 }
 
-// buildCommand creates an exec.Cmd from a Command definition
-func (r *CommandRunner) buildCommand(cmdDef config.Command) *exec.Cmd {
+// buildCommand creates an exec.Cmd from a Command definition, adding its
+// env with secrets resolved
+func (r *CommandRunner) buildCommand(cmdDef config.Command) (*exec.Cmd, error) {
+	var cmd *exec.Cmd
 	if cmdDef.Sudo && !r.system.IsWindows() {
 		args := append([]string{cmdDef.Command}, cmdDef.Args...)
-		return exec.Command("sudo", args...)
+		if len(cmdDef.Env) > 0 {
+			// sudo resets the environment unless asked to keep these
+			args = append([]string{"--preserve-env=" + strings.Join(sortedEnvKeys(cmdDef.Env), ",")}, args...)
+		}
+		cmd = exec.Command("sudo", args...)
+	} else {
+		cmd = exec.Command(cmdDef.Command, cmdDef.Args...)
+	}
+
+	if len(cmdDef.Env) > 0 {
+		cmd.Env = os.Environ()
+		for _, key := range sortedEnvKeys(cmdDef.Env) {
+			val, err := config.ExpandSecrets(cmdDef.Env[key], r.secrets.Resolve)
+			if err != nil {
+				return nil, fmt.Errorf("env %s: %w", key, err)
+			}
+			cmd.Env = append(cmd.Env, key+"="+val)
+		}
+	}
+
+	return cmd, nil
+}
+
+func sortedEnvKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// conditionMet evaluates the command's when expression against the system
+func (r *CommandRunner) conditionMet(cmdDef config.Command) bool {
+	if cmdDef.When == "" {
+		return true
 	}
 
-	return exec.Command(cmdDef.Command, cmdDef.Args...)
+	ok, err := condition.Evaluate(cmdDef.When, condition.NewFacts(r.system))
+	return err == nil && ok
 }
@@ -1,10 +1,13 @@
 package executor
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/araldhafeeri/stackup/internal/config"
 	"github.com/araldhafeeri/stackup/internal/domain"
+	"github.com/araldhafeeri/stackup/internal/secret"
 )
 
 func TestCommandRunner(t *testing.T) {
@@ -58,10 +61,9 @@ func TestBuildCommand(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			runner := NewCommandRunner(tt.system)
-			cmd := runner.buildCommand(tt.cmdDef)
-
-			if cmd == nil {
-				t.Fatal("buildCommand returned nil")
+			cmd, err := runner.buildCommand(tt.cmdDef)
+			if err != nil {
+				t.Fatalf("buildCommand() error: %v", err)
 			}
 
 			// Check if sudo is in the command path
@@ -101,3 +103,55 @@ func TestRunCommandsIgnoreError(t *testing.T) {
 		t.Errorf("Run should not error with ignore_error=true: %v", err)
 	}
 }
+
+func TestRunCommandsSkipsFalseCondition(t *testing.T) {
+	sys := &domain.System{OS: "linux", Arch: "amd64"}
+	runner := NewCommandRunner(sys)
+
+	commands := []config.Command{
+		{
+			Command: "false", // Would fail if it ran
+			When:    `arch == "arm64"`,
+		},
+		{
+			Command: "true",
+			When:    `arch == "amd64"`,
+		},
+	}
+
+	if err := runner.Run(commands, "test"); err != nil {
+		t.Errorf("Run should skip commands whose condition is false: %v", err)
+	}
+}
+
+func TestBuildCommandEnv(t *testing.T) {
+	t.Setenv("STACKUP_TEST_TOKEN", "s3cret-value")
+
+	runner := NewCommandRunner(&domain.System{OS: "linux"})
+	runner.secrets = secret.NewStore(map[string]config.Secret{"token": {Env: "STACKUP_TEST_TOKEN"}}, secret.NewRedactor())
+
+	cmd, err := runner.buildCommand(config.Command{
+		Command: "npm",
+		Args:    []string{"publish"},
+		Sudo:    true,
+		Env:     map[string]string{"NPM_TOKEN": "${secret:token}", "CI": "true"},
+	})
+	if err != nil {
+		t.Fatalf("buildCommand() error: %v", err)
+	}
+
+	if !slices.Contains(cmd.Env, "NPM_TOKEN=s3cret-value") || !slices.Contains(cmd.Env, "CI=true") {
+		t.Errorf("Env missing command variables: %v", cmd.Env[len(cmd.Env)-2:])
+	}
+	if cmd.Args[1] != "--preserve-env=CI,NPM_TOKEN" {
+		t.Errorf("sudo should preserve the command env, got %v", cmd.Args)
+	}
+	if strings.Contains(strings.Join(cmd.Args, " "), "s3cret-value") {
+		t.Errorf("Secret leaked into arguments: %v", cmd.Args)
+	}
+
+	_, err = runner.buildCommand(config.Command{Command: "true", Env: map[string]string{"X": "${secret:missing}"}})
+	if err == nil || !strings.Contains(err.Error(), `undefined secret "missing"`) {
+		t.Errorf("Expected undefined secret error, got %v", err)
+	}
+}
@@ -1,20 +1,25 @@
 package executor
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/araldhafeeri/stackup/internal/config"
 	"github.com/araldhafeeri/stackup/internal/domain"
+	"github.com/araldhafeeri/stackup/internal/secret"
 )
 
 // DownloadInstaller handles installation by downloading installers
 type DownloadInstaller struct {
-	system *domain.System
+	system  *domain.System
+	secrets *secret.Store
 }
 
 // NewDownloadInstaller creates a new download installer
@@ -34,7 +39,7 @@ func (d *DownloadInstaller) Install(tool *config.Tool, cfg *config.PlatformConfi
 	defer os.RemoveAll(tempDir)
 
 	// Download file
-	filePath, err := d.downloadFile(cfg.Installer, tempDir, tool.Name, cfg.Type)
+	filePath, err := d.downloadFile(cfg.Installer, cfg.Headers, tempDir, tool.Name, cfg.Type, cfg.SHA256)
 	if err != nil {
 		return fmt.Errorf("download failed: %w", err)
 	}
@@ -49,10 +54,24 @@ func (d *DownloadInstaller) Install(tool *config.Tool, cfg *config.PlatformConfi
 	return nil
 }
 
-// downloadFile downloads a file from a URL to the specified directory
-func (d *DownloadInstaller) downloadFile(url, destDir, toolName, fileType string) (string, error) {
+// downloadFile downloads a file from a URL to the specified directory,
+// sending the given headers with secrets resolved and verifying the
+// checksum when one is given
+func (d *DownloadInstaller) downloadFile(url string, headers map[string]string, destDir, toolName, fileType, checksum string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to download: %w", err)
+	}
+	for key, val := range headers {
+		expanded, err := config.ExpandSecrets(val, d.secrets.Resolve)
+		if err != nil {
+			return "", fmt.Errorf("header %s: %w", key, err)
+		}
+		req.Header.Set(key, expanded)
+	}
+
 	// Make HTTP request
-	resp, err := http.Get(url)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to download: %w", err)
 	}
@@ -74,17 +93,36 @@ func (d *DownloadInstaller) downloadFile(url, destDir, toolName, fileType string
 	}
 	defer out.Close()
 
-	// Copy content
-	written, err := io.Copy(out, resp.Body)
+	// Copy content, hashing as we go
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(out, hash), resp.Body)
 	if err != nil {
 		return "", fmt.Errorf("failed to write file: %w", err)
 	}
 
 	fmt.Printf("   Downloaded %d bytes\n", written)
 
+	if err := verifyChecksum(hash.Sum(nil), checksum); err != nil {
+		return "", err
+	}
+
 	return filePath, nil
 }
 
+// verifyChecksum compares a SHA-256 digest with the expected hex checksum.
+// An empty checksum is not verified.
+func verifyChecksum(sum []byte, expected string) error {
+	if expected == "" {
+		return nil
+	}
+
+	actual := hex.EncodeToString(sum)
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("checksum mismatch: expected sha256 %s, got %s", expected, actual)
+	}
+	return nil
+}
+
 // determineFilename creates an appropriate filename for the downloaded installer
 func (d *DownloadInstaller) determineFilename(url, toolName, fileType string) string {
 	// If type is specified, use it
@@ -1,10 +1,16 @@
 package executor
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/araldhafeeri/stackup/internal/config"
 	"github.com/araldhafeeri/stackup/internal/domain"
+	"github.com/araldhafeeri/stackup/internal/secret"
 )
 
 func TestDetermineFilename(t *testing.T) {
@@ -150,3 +156,56 @@ func TestBuildWindowsInstallerCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyChecksum(t *testing.T) {
+	sum := sha256.Sum256([]byte("installer"))
+	digest := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name        string
+		expected    string
+		expectError bool
+	}{
+		{name: "No Checksum", expected: "", expectError: false},
+		{name: "Match", expected: digest, expectError: false},
+		{name: "Match Upper Case", expected: strings.ToUpper(digest), expectError: false},
+		{name: "Mismatch", expected: strings.Repeat("0", 64), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyChecksum(sum[:], tt.expected)
+			if tt.expectError && err == nil {
+				t.Error("Expected checksum error")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDownloadFileHeaders(t *testing.T) {
+	t.Setenv("STACKUP_TEST_TOKEN", "artifact-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer artifact-token" || r.Header.Get("X-Team") != "platform" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("installer"))
+	}))
+	defer server.Close()
+
+	d := NewDownloadInstaller(&domain.System{OS: "linux"})
+	d.secrets = secret.NewStore(map[string]config.Secret{"token": {Env: "STACKUP_TEST_TOKEN"}}, secret.NewRedactor())
+
+	headers := map[string]string{"Authorization": "Bearer ${secret:token}", "X-Team": "platform"}
+	if _, err := d.downloadFile(server.URL+"/tool.sh", headers, t.TempDir(), "tool", "sh", ""); err != nil {
+		t.Errorf("downloadFile() with headers error: %v", err)
+	}
+
+	if _, err := d.downloadFile(server.URL+"/tool.sh", nil, t.TempDir(), "tool", "sh", ""); err == nil {
+		t.Error("downloadFile() without headers should be rejected by the server")
+	}
+}
@@ -2,8 +2,12 @@
 package executor
 
 import (
+	"time"
+
+	"github.com/araldhafeeri/stackup/internal/backend"
 	"github.com/araldhafeeri/stackup/internal/config"
 	"github.com/araldhafeeri/stackup/internal/domain"
+	"github.com/araldhafeeri/stackup/internal/secret"
 )
 
 // Executor handles execution of commands and installations
@@ -14,14 +18,26 @@ type Executor struct {
 	downloadInstaller *DownloadInstaller
 }
 
-// New creates a new Executor
-func New(sys *domain.System) *Executor {
-	return &Executor{
+// New creates a new Executor. Secrets referenced by download headers and
+// command env are resolved from secrets, which may be nil when the config
+// defines none. Package managers ask before changing anything only when
+// interactive.
+func New(sys *domain.System, secrets *secret.Store, interactive bool) *Executor {
+	e := &Executor{
 		system:            sys,
 		commandRunner:     NewCommandRunner(sys),
-		packageManager:    NewPackageManager(sys),
+		packageManager:    NewPackageManager(sys, interactive),
 		downloadInstaller: NewDownloadInstaller(sys),
 	}
+	e.commandRunner.secrets = secrets
+	e.downloadInstaller.secrets = secrets
+	return e
+}
+
+// SetRunner makes package managers run their commands through r, so tests
+// can record them instead of changing the system
+func (e *Executor) SetRunner(r backend.Runner) {
+	e.packageManager.runner = r
 }
 
 // RunCommands executes a list of commands
@@ -34,6 +50,48 @@ func (e *Executor) InstallViaPackageManager(tool *config.Tool, cfg *config.Platf
 	return e.packageManager.Install(tool, cfg)
 }
 
+// BatchManager returns the package manager that installs a tool when it can
+// install several tools in one transaction, or ""
+func (e *Executor) BatchManager(tool *config.Tool, cfg *config.PlatformConfig) string {
+	return e.packageManager.BatchManager(tool, cfg)
+}
+
+// InstallBatchViaPackageManager installs several tools sharing a package
+// manager in one transaction; cfgs holds the platform config of each tool
+func (e *Executor) InstallBatchViaPackageManager(tools []*config.Tool, cfgs []*config.PlatformConfig) error {
+	return e.packageManager.InstallAll(tools, cfgs)
+}
+
+// PackageManager returns the package manager that installs a tool, or ""
+// when none is available
+func (e *Executor) PackageManager(tool *config.Tool, cfg *config.PlatformConfig) string {
+	return e.packageManager.Manager(tool, cfg)
+}
+
+// RefreshIndex refreshes the package index of a manager unless it was
+// refreshed within maxAge, and reports whether it refreshed it
+func (e *Executor) RefreshIndex(manager string, maxAge time.Duration) (bool, error) {
+	return e.packageManager.RefreshIndex(manager, maxAge)
+}
+
+// AddRepositories adds the third-party repositories a tool's package comes
+// from
+func (e *Executor) AddRepositories(tool *config.Tool, cfg *config.PlatformConfig) error {
+	return e.packageManager.AddRepositories(tool, cfg)
+}
+
+// IsInstalled reports whether a tool's package is already installed, in
+// its pinned version for language package managers
+func (e *Executor) IsInstalled(tool *config.Tool, cfg *config.PlatformConfig) (bool, error) {
+	return e.packageManager.IsInstalled(tool, cfg)
+}
+
+// ShimDir returns the directory of the shims a version manager runs a tool
+// through, or "" when the tool's manager has none
+func (e *Executor) ShimDir(tool *config.Tool, cfg *config.PlatformConfig) string {
+	return e.packageManager.ShimDir(tool, cfg)
+}
+
 // InstallViaDownload installs a tool by downloading an installer
 func (e *Executor) InstallViaDownload(tool *config.Tool, cfg *config.PlatformConfig) error {
 	return e.downloadInstaller.Install(tool, cfg)
@@ -1,48 +1,230 @@
 package executor
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/araldhafeeri/stackup/internal/backend"
 	"github.com/araldhafeeri/stackup/internal/config"
 	"github.com/araldhafeeri/stackup/internal/domain"
 )
 
-// PackageManager handles installation via system package managers
+// PackageManager handles installation via system package managers. The
+// commands of each manager come from its backend.
 type PackageManager struct {
-	system      *domain.System
-	interactive bool
+	system  *domain.System
+	runner  backend.Runner
+	options backend.Options
 }
 
-// NewPackageManager creates a new package manager installer
-func NewPackageManager(sys *domain.System) *PackageManager {
-	return &PackageManager{system: sys, interactive: true}
+// NewPackageManager creates a new package manager installer. Managers
+// prompt for confirmation only when interactive.
+func NewPackageManager(sys *domain.System, interactive bool) *PackageManager {
+	return &PackageManager{
+		system:  sys,
+		runner:  backend.ExecRunner{},
+		options: backend.Options{Interactive: interactive, AsRoot: os.Geteuid() == 0},
+	}
 }
 
 // Install installs a tool using the appropriate package manager
 func (pm *PackageManager) Install(tool *config.Tool, cfg *config.PlatformConfig) error {
-	if pm.system.PackageManager == "" {
-		return fmt.Errorf("no package manager available")
+	b, pkg, err := pm.backend(tool, cfg)
+	if err != nil {
+		return err
+	}
+	return b.Install(pkg)
+}
+
+// BatchManager returns the manager that installs a tool when it can install
+// several packages in one transaction, or "" when it cannot
+func (pm *PackageManager) BatchManager(tool *config.Tool, cfg *config.PlatformConfig) string {
+	b, _, err := pm.backend(tool, cfg)
+	if err != nil {
+		return ""
+	}
+	if _, ok := b.(backend.Batcher); !ok {
+		return ""
+	}
+	return b.Name()
+}
+
+// InstallAll installs the packages of several tools, each with its platform
+// config in cfgs, in one transaction of their shared manager
+func (pm *PackageManager) InstallAll(tools []*config.Tool, cfgs []*config.PlatformConfig) error {
+	var batcher backend.Batcher
+	var manager string
+	pkgs := make([]backend.Package, len(tools))
+	for idx, tool := range tools {
+		b, pkg, err := pm.backend(tool, cfgs[idx])
+		if err != nil {
+			return err
+		}
+		if manager != "" && b.Name() != manager {
+			return fmt.Errorf("cannot install %s with %s in one transaction with %s packages", tool.Name, b.Name(), manager)
+		}
+		var ok bool
+		if batcher, ok = b.(backend.Batcher); !ok {
+			return fmt.Errorf("%s cannot install several packages at once", b.Name())
+		}
+		manager, pkgs[idx] = b.Name(), pkg
+	}
+	if batcher == nil {
+		return nil
+	}
+	return batcher.InstallAll(pkgs)
+}
+
+// Manager returns the package manager that installs a tool, or "" when none
+// is available
+func (pm *PackageManager) Manager(tool *config.Tool, cfg *config.PlatformConfig) string {
+	b, _, err := pm.backend(tool, cfg)
+	if err != nil {
+		return ""
+	}
+	return b.Name()
+}
+
+// RefreshIndex refreshes the package index of a manager unless it reports
+// a refresh within maxAge, or within the interval its mirrors allow. It
+// returns whether the index was refreshed.
+func (pm *PackageManager) RefreshIndex(manager string, maxAge time.Duration) (bool, error) {
+	b, err := backend.New(manager, pm.runner, pm.options)
+	if err != nil {
+		return false, err
+	}
+	if throttled, ok := b.(backend.Throttled); ok {
+		maxAge = max(maxAge, throttled.MinRefreshInterval())
+	}
+	if indexed, ok := b.(backend.Indexed); ok {
+		updated, err := indexed.IndexUpdated()
+		if err == nil && time.Since(updated) < maxAge {
+			return false, nil
+		}
+	}
+	if err := b.RefreshIndex(); err != nil {
+		return false, fmt.Errorf("failed to refresh %s package index: %w", manager, err)
+	}
+	return true, nil
+}
+
+// AddRepositories adds the repositories a tool's package comes from.
+// Repositories already in place are left as they are.
+func (pm *PackageManager) AddRepositories(tool *config.Tool, cfg *config.PlatformConfig) error {
+	for _, repo := range cfg.Repositories {
+		b, err := pm.repositoryBackend(tool, cfg, repo)
+		if err != nil {
+			return err
+		}
+		if err := b.AddRepository(pm.repository(repo)); err != nil {
+			return fmt.Errorf("failed to add %s repository %s: %w", b.Name(), repo.Name, err)
+		}
+	}
+	return nil
+}
+
+// repositoryBackend returns the backend of the manager a repository is
+// for. It defaults to the one installing the tool, which must be one of
+// the managers config.RepositoryManagers lists, as the validator requires
+func (pm *PackageManager) repositoryBackend(tool *config.Tool, cfg *config.PlatformConfig, repo config.Repository) (backend.Backend, error) {
+	if repo.Manager != "" {
+		return backend.New(repo.Manager, pm.runner, pm.options)
+	}
+	b, _, err := pm.backend(tool, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(config.RepositoryManagers, b.Name()) {
+		return nil, fmt.Errorf("%s cannot add repository %s", b.Name(), repo.Name)
+	}
+	return b, nil
+}
+
+// repository converts a configured repository, taking apt suites from the
+// distribution's codename when none are set
+func (pm *PackageManager) repository(repo config.Repository) backend.Repository {
+	suites := repo.Suites
+	if len(suites) == 0 && pm.system.DistroCodename != "" {
+		suites = []string{pm.system.DistroCodename}
+	}
+	return backend.Repository{
+		Name:        repo.Name,
+		URL:         repo.URL,
+		Suites:      suites,
+		Components:  repo.Components,
+		Key:         repo.Key,
+		Fingerprint: repo.Fingerprint,
 	}
+}
 
-	manager, packageName := pm.getPackageManagerAndName(tool, cfg)
+// IsInstalled reports whether a tool's package is installed. System
+// managers install the version of their repositories, so any version
+// counts; language managers must have one matching the tool's version.
+func (pm *PackageManager) IsInstalled(tool *config.Tool, cfg *config.PlatformConfig) (bool, error) {
+	b, pkg, err := pm.backend(tool, cfg)
+	if err != nil {
+		return false, err
+	}
+	if tool.Version == "" || tool.Version == "latest" || !domain.IsLanguagePackageManager(b.Name()) {
+		return b.IsInstalled(pkg)
+	}
 
-	cmd := pm.buildInstallCommand(packageName, manager)
+	version, err := b.InstalledVersion(pkg)
+	if errors.Is(err, backend.ErrNotInstalled) {
+		return false, nil
+	}
+	return err == nil && versionMatches(version, tool.Version), err
+}
 
-	if cmd == nil {
-		return fmt.Errorf("unsupported package manager: %s", pm.system.PackageManager)
+// ShimDir returns the shims directory of the version manager installing a
+// tool, or "" when its manager runs tools without shims
+func (pm *PackageManager) ShimDir(tool *config.Tool, cfg *config.PlatformConfig) string {
+	b, _, err := pm.backend(tool, cfg)
+	if err != nil {
+		return ""
 	}
+	if shimmed, ok := b.(backend.Shimmed); ok {
+		return shimmed.ShimDir()
+	}
+	return ""
+}
 
-	// Setup stdio - this is crucial for interactive commands
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+// backend returns the backend and package for a tool
+func (pm *PackageManager) backend(tool *config.Tool, cfg *config.PlatformConfig) (backend.Backend, backend.Package, error) {
+	manager, packageName, err := pm.getPackageManagerAndName(tool, cfg)
+	if err != nil {
+		return nil, backend.Package{}, err
+	}
+	// Language managers are installed with their runtime, not the system
+	if pm.system.PackageManager == "" && !domain.IsLanguagePackageManager(manager) {
+		return nil, backend.Package{}, fmt.Errorf("no package manager available")
+	}
 
-	// Important: Inherit environment to ensure proper execution
-	cmd.Env = os.Environ()
+	b, err := backend.New(manager, pm.runner, pm.options)
+	if err != nil {
+		return nil, backend.Package{}, err
+	}
+	return b, Package(tool, packageName, cfg), nil
+}
 
-	return cmd.Run()
+// Package returns a tool's package with its pinned version and the snap
+// and flatpak options of its platform section
+func Package(tool *config.Tool, name string, cfg *config.PlatformConfig) backend.Package {
+	pkg := backend.Package{Name: name, Version: pinnedVersion(tool.Version)}
+	if cfg.Snap != nil {
+		pkg.Classic = cfg.Snap.Classic
+		pkg.Channel = cfg.Snap.Channel
+	}
+	if cfg.Flatpak != nil {
+		pkg.Remote = cfg.Flatpak.Remote
+		pkg.User = cfg.Flatpak.Scope == "user"
+	}
+	return pkg
 }
 
 // getPackageName determines the correct package name for the current package manager
@@ -63,81 +245,41 @@ func (pm *PackageManager) getPackageName(tool *config.Tool, cfg *config.Platform
 	return tool.Name
 }
 
-func (pm *PackageManager) getPackageManagerAndName(tool *config.Tool, cfg *config.PlatformConfig) (string, string) {
-	// First, check if a specific manager is configured for this tool
+// getPackageManagerAndName picks the manager and package of a tool: its own
+// manager, else the first available manager, in order of preference, that
+// the platform section maps. A section without package names installs the
+// tool's name with the default manager.
+func (pm *PackageManager) getPackageManagerAndName(tool *config.Tool, cfg *config.PlatformConfig) (string, string, error) {
 	if tool.Manager != "" {
-		// If manager is specified, use it with the package names mapping
-		if cfg.PackageNames != nil {
-			if name, ok := cfg.PackageNames[tool.Manager]; ok {
-				return tool.Manager, name
-			}
+		if name, ok := cfg.PackageNames[tool.Manager]; ok {
+			return tool.Manager, name, nil
 		}
-		// If no package name mapping, use tool name
-		return tool.Manager, tool.Name
+		return tool.Manager, tool.Name, nil
 	}
 
-	// If no tool-specific manager, check package names for the system's package manager
-	if cfg.PackageNames != nil {
-		// First, try the system's detected package manager
-		if name, ok := cfg.PackageNames[pm.system.PackageManager]; ok {
-			return pm.system.PackageManager, name
+	available := pm.system.AvailablePackageManagers()
+	for _, manager := range available {
+		if name, ok := cfg.PackageNames[manager]; ok {
+			return manager, name, nil
 		}
-
-		// If system package manager not in package names, use the first available
-		for manager, name := range cfg.PackageNames {
-			return manager, name
+		if manager == domain.PackageManagerBrew && cfg.Brew != "" {
+			return manager, cfg.Brew, nil
 		}
 	}
 
-	// Check for brew-specific name (macOS only)
-	if cfg.Brew != "" && pm.system.PackageManager == domain.PackageManagerBrew {
-		return domain.PackageManagerBrew, cfg.Brew
+	if len(cfg.PackageNames) == 0 {
+		return pm.system.PackageManager, tool.Name, nil
 	}
 
-	// Default to system package manager with tool name
-	return pm.system.PackageManager, tool.Name
-}
-
-// buildInstallCommand creates the install command for the package manager
-func (pm *PackageManager) buildInstallCommand(packageName string, manager string) *exec.Cmd {
-
-	switch manager {
-	case domain.PackageManagerAPT:
-		if pm.interactive {
-			// Interactive mode - let user respond to prompts
-			return exec.Command("sudo", "apt-get", "install", packageName)
-		}
-		return exec.Command("sudo", "apt-get", "install", "-y", packageName)
-
-	case domain.PackageManagerDNF:
-		if pm.interactive {
-			return exec.Command("sudo", "dnf", "install", packageName)
-		}
-		return exec.Command("sudo", "dnf", "install", "-y", packageName)
-
-	case domain.PackageManagerPacman:
-		if pm.interactive {
-			return exec.Command("sudo", "pacman", "-S", packageName)
-		}
-		return exec.Command("sudo", "pacman", "-S", "--noconfirm", packageName)
-
-	case domain.PackageManagerBrew:
-		// Homebrew doesn't typically require interactive confirmation
-		return exec.Command("brew", "install", packageName)
-
-	case domain.PackageManagerWinget:
-		if pm.interactive {
-			return exec.Command("sudo", "winget", "install", "-e", "--id", packageName)
-		}
-		return exec.Command("sudo", "winget", "install", "-e", "--id", packageName, "--accept-package-agreements", "--accept-source-agreements")
-
-	case domain.PackageManagerChoco:
-		if pm.interactive {
-			return exec.Command("sudo", "choco", "install", packageName)
-		}
-		return exec.Command("sudo", "choco", "install", packageName, "-y")
-
-	default:
-		return nil
+	mapped := make([]string, 0, len(cfg.PackageNames))
+	for manager := range cfg.PackageNames {
+		mapped = append(mapped, manager)
+	}
+	sort.Strings(mapped)
+	tried := "none are available"
+	if len(available) > 0 {
+		tried = "tried " + strings.Join(available, ", ")
 	}
+	return "", "", fmt.Errorf("no available package manager has a package for %s: package_names has %s; %s",
+		tool.Name, strings.Join(mapped, ", "), tried)
 }
@@ -1,8 +1,13 @@
 package executor
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/araldhafeeri/stackup/internal/backend"
 	"github.com/araldhafeeri/stackup/internal/config"
 	"github.com/araldhafeeri/stackup/internal/domain"
 )
@@ -48,7 +53,7 @@ func TestGetPackageName(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			sys := &domain.System{PackageManager: tt.pkgManager}
-			pm := NewPackageManager(sys)
+			pm := NewPackageManager(sys, true)
 
 			result := pm.getPackageName(tt.tool, tt.platformCfg)
 			if result != tt.expected {
@@ -58,59 +63,405 @@ func TestGetPackageName(t *testing.T) {
 	}
 }
 
-func TestBuildInstallCommand(t *testing.T) {
+// recordingRunner records the commands backends run. Queries print the
+// configured output; others exit with status 1.
+type recordingRunner struct {
+	outputs map[string]string
+	calls   []string
+}
+
+func (r *recordingRunner) Run(name string, args ...string) error {
+	r.calls = append(r.calls, strings.Join(append([]string{name}, args...), " "))
+	return nil
+}
+
+func (r *recordingRunner) Output(name string, args ...string) ([]byte, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	r.calls = append(r.calls, line)
+	if out, ok := r.outputs[line]; ok {
+		return []byte(out), nil
+	}
+	return nil, &backend.ExitError{Command: name, Code: 1}
+}
+
+func (r *recordingRunner) LookPath(string) bool { return true }
+
+func TestInstall(t *testing.T) {
 	tests := []struct {
-		name        string
-		pkgManager  string
-		packageName string
-		expectNil   bool
+		name       string
+		pkgManager string
+		tool       *config.Tool
+		cfg        *config.PlatformConfig
+		expected   string
+		err        string
 	}{
 		{
-			name:        "APT",
-			pkgManager:  domain.PackageManagerAPT,
-			packageName: "git",
-			expectNil:   false,
+			name:       "APT",
+			pkgManager: domain.PackageManagerAPT,
+			tool:       &config.Tool{Name: "git"},
+			cfg:        &config.PlatformConfig{},
+			expected:   "sudo apt-get install git",
+		},
+		{
+			name:       "DNF Package Name",
+			pkgManager: domain.PackageManagerDNF,
+			tool:       &config.Tool{Name: "docker"},
+			cfg:        &config.PlatformConfig{PackageNames: map[string]string{"dnf": "moby-engine"}},
+			expected:   "sudo dnf install moby-engine",
+		},
+		{
+			name:       "Brew",
+			pkgManager: domain.PackageManagerBrew,
+			tool:       &config.Tool{Name: "vscode"},
+			cfg:        &config.PlatformConfig{Brew: "visual-studio-code"},
+			expected:   "brew install visual-studio-code",
+		},
+		{
+			name:       "Tool Manager",
+			pkgManager: domain.PackageManagerWinget,
+			tool:       &config.Tool{Name: "git", Manager: "choco"},
+			cfg:        &config.PlatformConfig{},
+			expected:   "choco install git",
+		},
+		{
+			name:       "Snap Options",
+			pkgManager: domain.PackageManagerAPT,
+			tool:       &config.Tool{Name: "vscode", Manager: "snap"},
+			cfg: &config.PlatformConfig{
+				PackageNames: map[string]string{"snap": "code"},
+				Snap:         &config.SnapOptions{Classic: true},
+			},
+			expected: "sudo snap install code --classic",
+		},
+		{
+			name:       "Flatpak User Scope",
+			pkgManager: domain.PackageManagerDNF,
+			tool:       &config.Tool{Name: "slack", Manager: "flatpak"},
+			cfg: &config.PlatformConfig{
+				PackageNames: map[string]string{"flatpak": "com.slack.Slack"},
+				Flatpak:      &config.FlatpakOptions{Remote: "flathub", Scope: "user"},
+			},
+			expected: "flatpak remote-add --user --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo; " +
+				"flatpak install --user flathub com.slack.Slack",
+		},
+		{
+			name:     "Language Manager Without System Manager",
+			tool:     &config.Tool{Name: "ripgrep", Version: "14.1.0", Manager: "cargo"},
+			cfg:      &config.PlatformConfig{},
+			expected: "cargo install ripgrep --version 14.1.0",
 		},
 		{
-			name:        "DNF",
-			pkgManager:  domain.PackageManagerDNF,
-			packageName: "docker",
-			expectNil:   false,
+			name:       "Latest Is Not Pinned",
+			pkgManager: domain.PackageManagerAPT,
+			tool:       &config.Tool{Name: "black", Version: "latest", Manager: "pipx"},
+			cfg:        &config.PlatformConfig{},
+			expected:   "pipx install black",
 		},
 		{
-			name:        "Brew",
-			pkgManager:  domain.PackageManagerBrew,
-			packageName: "node",
-			expectNil:   false,
+			name:       "Range Is Not Pinned",
+			pkgManager: domain.PackageManagerAPT,
+			tool:       &config.Tool{Name: "black", Version: "24.x", Manager: "pipx"},
+			cfg:        &config.PlatformConfig{},
+			expected:   "pipx install black",
+		},
+		{
+			name:       "Version Manager",
+			pkgManager: domain.PackageManagerBrew,
+			tool:       &config.Tool{Name: "node", Version: "20", Manager: "mise"},
+			cfg:        &config.PlatformConfig{},
+			expected:   "mise use --global node@20",
 		},
 		{
-			name:        "Winget",
-			pkgManager:  domain.PackageManagerWinget,
-			packageName: "Git.Git",
-			expectNil:   false,
+			name:       "Unsupported",
+			pkgManager: "unsupported",
+			tool:       &config.Tool{Name: "tool"},
+			cfg:        &config.PlatformConfig{},
+			err:        "unsupported package manager: unsupported",
 		},
 		{
-			name:        "Unsupported",
-			pkgManager:  "unsupported",
-			packageName: "tool",
-			expectNil:   true,
+			name: "No Package Manager",
+			tool: &config.Tool{Name: "tool"},
+			cfg:  &config.PlatformConfig{},
+			err:  "no package manager available",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sys := &domain.System{PackageManager: tt.pkgManager}
-			pm := NewPackageManager(sys)
+			runner := &recordingRunner{}
+			pm := NewPackageManager(&domain.System{PackageManager: tt.pkgManager}, true)
+			pm.runner = runner
+			pm.options.AsRoot = false
+
+			err := pm.Install(tt.tool, tt.cfg)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Install() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil || strings.Join(runner.calls, "; ") != tt.expected {
+				t.Errorf("Install() ran %q, %v; want %q", runner.calls, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsInstalled(t *testing.T) {
+	runner := &recordingRunner{outputs: map[string]string{
+		"pacman -Q git":        "git 2.44.0-1\n",
+		"cargo install --list": "ripgrep v14.1.0:\n    rg\n",
+	}}
+	pm := NewPackageManager(&domain.System{PackageManager: domain.PackageManagerPacman}, true)
+	pm.runner = runner
+
+	for _, tt := range []struct {
+		tool *config.Tool
+		want bool
+	}{
+		// System managers install what their repositories have
+		{&config.Tool{Name: "git", Version: "2.43"}, true},
+		{&config.Tool{Name: "curl", Version: "latest"}, false},
+		// Language managers must have the pinned version
+		{&config.Tool{Name: "ripgrep", Version: "14.1.0", Manager: "cargo"}, true},
+		{&config.Tool{Name: "ripgrep", Version: "13.0.0", Manager: "cargo"}, false},
+		{&config.Tool{Name: "ripgrep", Version: "latest", Manager: "cargo"}, true},
+		// Ranges match the installed version
+		{&config.Tool{Name: "ripgrep", Version: "14.x", Manager: "cargo"}, true},
+		{&config.Tool{Name: "ripgrep", Version: "^14.0", Manager: "cargo"}, true},
+		{&config.Tool{Name: "ripgrep", Version: "13.x", Manager: "cargo"}, false},
+	} {
+		installed, err := pm.IsInstalled(tt.tool, &config.PlatformConfig{})
+		if err != nil || installed != tt.want {
+			t.Errorf("IsInstalled(%s %s) = %v, %v; want %v", tt.tool.Name, tt.tool.Version, installed, err, tt.want)
+		}
+	}
+}
+
+func TestInstallAll(t *testing.T) {
+	runner := &recordingRunner{}
+	pm := NewPackageManager(&domain.System{PackageManager: domain.PackageManagerDNF}, true)
+	pm.runner = runner
+	pm.options.AsRoot = false
+
+	tools := []*config.Tool{{Name: "git"}, {Name: "docker"}, {Name: "slack", Manager: "flatpak"}}
+	cfgs := []*config.PlatformConfig{{}, {PackageNames: map[string]string{"dnf": "moby-engine"}}, {}}
+
+	for idx, want := range []string{"dnf", "dnf", ""} {
+		if got := pm.BatchManager(tools[idx], cfgs[idx]); got != want {
+			t.Errorf("BatchManager(%s) = %q, want %q", tools[idx].Name, got, want)
+		}
+	}
+
+	if err := pm.InstallAll(tools[:2], cfgs[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(runner.calls, "; "), "sudo dnf install git moby-engine"; got != want {
+		t.Errorf("InstallAll() ran %q, want %q", got, want)
+	}
+
+	if err := pm.InstallAll(tools, cfgs); err == nil {
+		t.Error("InstallAll() with a flatpak succeeded, want an error")
+	}
+}
+
+func TestRefreshIndex(t *testing.T) {
+	repository := t.TempDir()
+	fetchHead := filepath.Join(repository, ".git", "FETCH_HEAD")
+	if err := os.MkdirAll(filepath.Dir(fetchHead), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fetchHead, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &recordingRunner{outputs: map[string]string{"brew --repository": repository + "\n"}}
+	pm := NewPackageManager(&domain.System{PackageManager: domain.PackageManagerBrew}, true)
+	pm.runner = runner
+	pm.options.AsRoot = false
+
+	if tool := (&config.Tool{Name: "git"}); pm.Manager(tool, &config.PlatformConfig{}) != "brew" {
+		t.Errorf("Manager(git) = %q, want brew", pm.Manager(tool, &config.PlatformConfig{}))
+	}
+
+	// Fetched just now, so the index is fresh
+	if refreshed, err := pm.RefreshIndex("brew", time.Hour); refreshed || err != nil {
+		t.Errorf("RefreshIndex(fresh) = %v, %v; want false", refreshed, err)
+	}
+
+	stale := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(fetchHead, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	if refreshed, err := pm.RefreshIndex("brew", time.Hour); !refreshed || err != nil {
+		t.Errorf("RefreshIndex(stale) = %v, %v; want true", refreshed, err)
+	}
+
+	// dnf cannot tell when its index was refreshed, so it always refreshes
+	if refreshed, err := pm.RefreshIndex("dnf", time.Hour); !refreshed || err != nil {
+		t.Errorf("RefreshIndex(dnf) = %v, %v; want true", refreshed, err)
+	}
+
+	want := "brew --repository; brew --repository; brew update; sudo dnf makecache"
+	if got := strings.Join(runner.calls, "; "); got != want {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+}
+
+func TestRepositories(t *testing.T) {
+	runner := &recordingRunner{}
+	pm := NewPackageManager(&domain.System{PackageManager: domain.PackageManagerBrew}, true)
+	pm.runner = runner
+	pm.options.AsRoot = false
+
+	tool := &config.Tool{Name: "terraform"}
+	cfg := &config.PlatformConfig{
+		Brew: "hashicorp/tap/terraform",
+		Repositories: []config.Repository{
+			{Name: "hashicorp/tap"},
+			{Name: "git-core", Manager: "apt", URL: "ppa:git-core/ppa"},
+		},
+	}
+
+	if err := pm.AddRepositories(tool, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "brew tap hashicorp/tap; sudo add-apt-repository ppa:git-core/ppa"
+	if got := strings.Join(runner.calls, "; "); got != want {
+		t.Errorf("ran %q\nwant %q", got, want)
+	}
+
+	if err := pm.AddRepositories(tool, &config.PlatformConfig{Repositories: []config.Repository{{Name: "x", Manager: "portage"}}}); err == nil {
+		t.Error("AddRepositories() with an unknown manager succeeded")
+	}
+
+	runner.calls = nil
+	gemTool := &config.Tool{Name: "rubocop", Manager: domain.PackageManagerGem}
+	if err := pm.AddRepositories(gemTool, &config.PlatformConfig{Repositories: []config.Repository{{Name: "vendor", URL: "https://gems.example.com"}}}); err == nil {
+		t.Error("AddRepositories() for a gem succeeded")
+	}
+	if len(runner.calls) > 0 {
+		t.Errorf("ran %q for a manager that cannot add repositories", runner.calls)
+	}
+}
+
+func TestRepositorySuites(t *testing.T) {
+	pm := NewPackageManager(&domain.System{PackageManager: domain.PackageManagerAPT, DistroCodename: "noble"}, true)
+
+	if got := pm.repository(config.Repository{Name: "docker"}).Suites; len(got) != 1 || got[0] != "noble" {
+		t.Errorf("Suites = %v, want the codename", got)
+	}
+	if got := pm.repository(config.Repository{Name: "nodesource", Suites: []string{"nodistro"}}).Suites; len(got) != 1 || got[0] != "nodistro" {
+		t.Errorf("Suites = %v, want nodistro", got)
+	}
+}
+
+func TestNonInteractive(t *testing.T) {
+	for _, tt := range []struct {
+		interactive bool
+		want        string
+	}{
+		{true, "sudo apt-get install git"},
+		{false, "sudo env DEBIAN_FRONTEND=noninteractive apt-get install -y git"},
+	} {
+		runner := &recordingRunner{}
+		pm := NewPackageManager(&domain.System{PackageManager: domain.PackageManagerAPT}, tt.interactive)
+		pm.runner = runner
+		pm.options.AsRoot = false
+
+		if err := pm.Install(&config.Tool{Name: "git"}, &config.PlatformConfig{}); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(runner.calls, "; "); got != tt.want {
+			t.Errorf("interactive %v: ran %q, want %q", tt.interactive, got, tt.want)
+		}
+	}
+}
 
-			cmd := pm.buildInstallCommand(tt.packageName, tt.pkgManager)
+func TestGetPackageManagerAndName(t *testing.T) {
+	linux := &domain.System{OS: "linux", PackageManager: "apt", PackageManagers: []string{"apt", "snap", "flatpak", "brew"}}
+	tests := []struct {
+		name    string
+		system  *domain.System
+		tool    *config.Tool
+		cfg     *config.PlatformConfig
+		manager string
+		pkg     string
+		err     string
+	}{
+		{
+			name:    "Default Manager",
+			system:  linux,
+			tool:    &config.Tool{Name: "git"},
+			cfg:     &config.PlatformConfig{},
+			manager: "apt", pkg: "git",
+		},
+		{
+			name:    "Default Manager Mapped",
+			system:  linux,
+			tool:    &config.Tool{Name: "vscode"},
+			cfg:     &config.PlatformConfig{PackageNames: map[string]string{"snap": "code", "apt": "code-apt"}},
+			manager: "apt", pkg: "code-apt",
+		},
+		{
+			name:    "First Available Mapped",
+			system:  linux,
+			tool:    &config.Tool{Name: "slack"},
+			cfg:     &config.PlatformConfig{PackageNames: map[string]string{"winget": "SlackTechnologies.Slack", "flatpak": "com.slack.Slack", "snap": "slack", "dnf": "slack"}},
+			manager: "snap", pkg: "slack",
+		},
+		{
+			name:    "Brew Name",
+			system:  linux,
+			tool:    &config.Tool{Name: "vscode"},
+			cfg:     &config.PlatformConfig{Brew: "visual-studio-code", PackageNames: map[string]string{"nix": "vscode"}},
+			manager: "brew", pkg: "visual-studio-code",
+		},
+		{
+			name:    "Priority",
+			system:  &domain.System{OS: "linux", PackageManager: "flatpak", PackageManagers: []string{"flatpak", "apt", "snap"}},
+			tool:    &config.Tool{Name: "slack"},
+			cfg:     &config.PlatformConfig{PackageNames: map[string]string{"snap": "slack", "flatpak": "com.slack.Slack"}},
+			manager: "flatpak", pkg: "com.slack.Slack",
+		},
+		{
+			name:    "Tool Manager",
+			system:  linux,
+			tool:    &config.Tool{Name: "ripgrep", Manager: "cargo"},
+			cfg:     &config.PlatformConfig{PackageNames: map[string]string{"apt": "ripgrep"}},
+			manager: "cargo", pkg: "ripgrep",
+		},
+		{
+			name:   "None Available",
+			system: linux,
+			tool:   &config.Tool{Name: "docker"},
+			cfg:    &config.PlatformConfig{PackageNames: map[string]string{"pacman": "docker", "dnf": "moby-engine", "npm": "docker"}},
+			err:    "no available package manager has a package for docker: package_names has dnf, npm, pacman; tried apt, snap, flatpak, brew",
+		},
+		{
+			name:   "No Manager Detected",
+			system: &domain.System{OS: "linux"},
+			tool:   &config.Tool{Name: "docker"},
+			cfg:    &config.PlatformConfig{PackageNames: map[string]string{"apt": "docker.io"}},
+			err:    "no available package manager has a package for docker: package_names has apt; none are available",
+		},
+	}
 
-			if tt.expectNil {
-				if cmd != nil {
-					t.Error("Expected nil command for unsupported package manager")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm := NewPackageManager(tt.system, true)
+			// Run each case several times: map order must not matter
+			for range 10 {
+				manager, pkg, err := pm.getPackageManagerAndName(tt.tool, tt.cfg)
+				if tt.err != "" {
+					if err == nil || err.Error() != tt.err {
+						t.Fatalf("error = %v, want %q", err, tt.err)
+					}
+					continue
 				}
-			} else {
-				if cmd == nil {
-					t.Error("Expected non-nil command")
+				if err != nil || manager != tt.manager || pkg != tt.pkg {
+					t.Fatalf("= %q, %q, %v; want %q, %q", manager, pkg, err, tt.manager, tt.pkg)
 				}
 			}
 		})
@@ -0,0 +1,83 @@
+package executor
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/condition"
+)
+
+// exactVersion matches versions naming a single release, such as 14.1.0,
+// v1.2 or 2.0.0-rc.1, as opposed to latest or ranges like 20.x and ^1.2
+var exactVersion = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+)*([-+][0-9A-Za-z.+-]+)?$`)
+
+// pinnedVersion returns the version a package is pinned to. Only exact
+// versions are pinned; latest and ranges install the newest release.
+func pinnedVersion(version string) string {
+	if exactVersion.MatchString(version) {
+		return version
+	}
+	return ""
+}
+
+// versionMatches reports whether an installed version satisfies a tool's
+// version. Exact versions and wildcards such as 20.x match by prefix, so
+// 20 and 20.x both match 20.11.0; ^ keeps the major version, ~ the minor
+// one, and comparison operators compare versions.
+func versionMatches(installed, wanted string) bool {
+	installed = strings.TrimPrefix(installed, "v")
+	wanted = strings.TrimSpace(wanted)
+
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if bound, ok := strings.CutPrefix(wanted, op); ok {
+			cmp := condition.CompareVersions(installed, strings.TrimPrefix(strings.TrimSpace(bound), "v"))
+			switch op {
+			case ">=":
+				return cmp >= 0
+			case "<=":
+				return cmp <= 0
+			case ">":
+				return cmp > 0
+			default:
+				return cmp < 0
+			}
+		}
+	}
+
+	keep := 0
+	switch {
+	case strings.HasPrefix(wanted, "^"):
+		keep = 1
+	case strings.HasPrefix(wanted, "~"):
+		keep = 2
+	}
+	if keep > 0 {
+		base := strings.TrimPrefix(wanted[1:], "v")
+		return sameRelease(installed, base, keep) && condition.CompareVersions(installed, base) >= 0
+	}
+
+	prefix := strings.TrimPrefix(wanted, "v")
+	for _, wildcard := range []string{".x", ".X", ".*"} {
+		for strings.HasSuffix(prefix, wildcard) {
+			prefix = strings.TrimSuffix(prefix, wildcard)
+		}
+	}
+	switch prefix {
+	case "", "x", "X", "*":
+		return true
+	}
+	return installed == prefix || strings.HasPrefix(installed, prefix+".") || strings.HasPrefix(installed, prefix+"-")
+}
+
+// sameRelease reports whether two versions agree on their first n
+// segments, or on every segment of base when it has fewer
+func sameRelease(version, base string, n int) bool {
+	vs := strings.Split(version, ".")
+	bs := strings.Split(base, ".")
+	for i := 0; i < n && i < len(bs); i++ {
+		if i >= len(vs) || vs[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
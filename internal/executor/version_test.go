@@ -0,0 +1,51 @@
+package executor
+
+import "testing"
+
+func TestPinnedVersion(t *testing.T) {
+	for version, want := range map[string]string{
+		"14.1.0":     "14.1.0",
+		"20":         "20",
+		"v1.2":       "v1.2",
+		"2.0.0-rc.1": "2.0.0-rc.1",
+		"latest":     "",
+		"20.x":       "",
+		"3.*":        "",
+		"^1.2":       "",
+		"~1.2.3":     "",
+		">=1.0":      "",
+	} {
+		if got := pinnedVersion(version); got != want {
+			t.Errorf("pinnedVersion(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
+
+func TestVersionMatches(t *testing.T) {
+	for _, tt := range []struct {
+		installed, wanted string
+		want              bool
+	}{
+		{"14.1.0", "14.1.0", true},
+		{"v14.1.0", "14.1.0", true},
+		{"14.1.0", "14.1", true},
+		{"14.10.0", "14.1", false},
+		{"20.11.0", "20", true},
+		{"20.11.0", "20.x", true},
+		{"20.11.0", "20.11.x", true},
+		{"21.0.0", "20.x", false},
+		{"3.12.4", "3.*", true},
+		{"1.4.0", "^1.2", true},
+		{"1.1.0", "^1.2", false},
+		{"2.0.0", "^1.2", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"1.10.0", ">=1.9", true},
+		{"1.8.0", ">= 1.9", false},
+		{"1.8.0", "<1.9", true},
+	} {
+		if got := versionMatches(tt.installed, tt.wanted); got != tt.want {
+			t.Errorf("versionMatches(%q, %q) = %v, want %v", tt.installed, tt.wanted, got, tt.want)
+		}
+	}
+}
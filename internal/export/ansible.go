@@ -0,0 +1,179 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+// packageModules are the Ansible modules that install packages of a
+// manager. Other managers, such as brew with its casks and winget, run as
+// commands.
+var packageModules = map[string]string{
+	domain.PackageManagerAPT:    "ansible.builtin.apt",
+	domain.PackageManagerDNF:    "ansible.builtin.dnf",
+	domain.PackageManagerPacman: "community.general.pacman",
+	domain.PackageManagerChoco:  "chocolatey.chocolatey.win_chocolatey",
+}
+
+// play is an Ansible play
+type play struct {
+	Name  string `yaml:"name"`
+	Hosts string `yaml:"hosts"`
+	Tasks []task `yaml:"tasks"`
+}
+
+// task is an Ansible task; Module holds the module name and its arguments
+type task struct {
+	Name         string                 `yaml:"name"`
+	Module       map[string]interface{} `yaml:",inline"`
+	Become       bool                   `yaml:"become,omitempty"`
+	Environment  map[string]string      `yaml:"environment,omitempty"`
+	IgnoreErrors bool                   `yaml:"ignore_errors,omitempty"`
+	ChangedWhen  *bool                  `yaml:"changed_when,omitempty"`
+}
+
+// playbook converts plan steps to tasks
+type playbook struct {
+	windows bool
+	tasks   []task
+}
+
+func (p *playbook) add(name, module string, args interface{}) *task {
+	p.tasks = append(p.tasks, task{Name: name, Module: map[string]interface{}{module: args}})
+	return &p.tasks[len(p.tasks)-1]
+}
+
+func (p *playbook) tool(tp ToolPlan) {
+	for _, step := range tp.Steps {
+		name := tp.DisplayName + ": " + step.Description
+		switch {
+		case step.Download != nil:
+			p.download(tp.DisplayName, step.Download)
+			continue
+		case step.Package != nil && packageModules[step.Package.Manager] != "":
+			p.add(fmt.Sprintf("%s: install %s with %s", tp.DisplayName, step.Package.Name, step.Package.Manager),
+				packageModules[step.Package.Manager],
+				map[string]string{"name": step.Package.Name, "state": "present"}).Become = step.Sudo
+			continue
+		case step.Verify:
+			name = tp.DisplayName + ": verify"
+		case step.Description == "":
+			name = tp.DisplayName + ": run " + step.Args[0]
+		}
+
+		t := p.command(name, step)
+		if step.Verify {
+			changed := false
+			t.ChangedWhen = &changed
+			t.IgnoreErrors = true
+		}
+
+		if step.WaitFor > 0 {
+			p.add(fmt.Sprintf("%s: wait %d seconds", tp.DisplayName, step.WaitFor), "ansible.builtin.pause",
+				map[string]int{"seconds": step.WaitFor})
+		}
+	}
+}
+
+// command runs a step as a command task
+func (p *playbook) command(name string, step Step) *task {
+	var t *task
+	if p.windows {
+		t = p.add(name, "ansible.windows.win_command", windowsCommandLine(step.Args))
+	} else {
+		t = p.add(name, "ansible.builtin.command", map[string][]string{"argv": step.Args})
+	}
+	t.Become = step.Sudo
+	t.Environment = step.Env
+	t.IgnoreErrors = step.IgnoreError
+	return t
+}
+
+// download fetches an installer with its checksum and runs it
+func (p *playbook) download(toolName string, d *Download) {
+	name := fmt.Sprintf("%s: download %s", toolName, d.File)
+
+	var file string
+	if p.windows {
+		file = `C:\Windows\Temp\` + d.File
+		args := map[string]interface{}{"url": d.URL, "dest": file}
+		if len(d.Headers) > 0 {
+			args["headers"] = d.Headers
+		}
+		if d.SHA256 != "" {
+			args["checksum"] = strings.ToLower(d.SHA256)
+			args["checksum_algorithm"] = "sha256"
+		}
+		p.add(name, "ansible.windows.win_get_url", args)
+	} else {
+		file = downloadDir + "/" + d.File
+		p.add(toolName+": create "+downloadDir, "ansible.builtin.file",
+			map[string]string{"path": downloadDir, "state": "directory"})
+
+		args := map[string]interface{}{"url": d.URL, "dest": file, "mode": "0755"}
+		if len(d.Headers) > 0 {
+			args["headers"] = d.Headers
+		}
+		if d.SHA256 != "" {
+			args["checksum"] = "sha256:" + strings.ToLower(d.SHA256)
+		}
+		p.add(name, "ansible.builtin.get_url", args)
+	}
+
+	for _, step := range d.InstallSteps(file) {
+		p.command(fmt.Sprintf("%s: run %s", toolName, step.Args[0]), step)
+	}
+}
+
+// windowsCommandLine joins arguments, quoting those with spaces
+func windowsCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = arg
+		if arg == "" || strings.ContainsAny(arg, " \t\"") {
+			quoted[i] = `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+func writeAnsible(w io.Writer, plan *Plan) error {
+	p := &playbook{windows: plan.Target.OS == "windows"}
+	var notes []string
+	for _, tp := range plan.Tools {
+		switch {
+		case tp.Skipped != "":
+			notes = append(notes, toolComment(tp))
+			continue
+		case tp.RequiresReboot:
+			notes = append(notes, tp.DisplayName+" requires a reboot after installing")
+		}
+		p.tool(tp)
+	}
+
+	name := "Install tools"
+	if plan.Profile != "" {
+		name = "Install " + plan.Profile + " tools"
+	}
+
+	var b strings.Builder
+	for _, line := range append(header(plan), notes...) {
+		fmt.Fprintf(&b, "# %s\n", line)
+	}
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode([]play{{Name: name, Hosts: "all", Tasks: p.tasks}}); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is an output format of stackup export
+type Format string
+
+// Supported export formats
+const (
+	FormatShell        Format = "sh"
+	FormatDockerfile   Format = "dockerfile"
+	FormatAnsible      Format = "ansible"
+	FormatDevcontainer Format = "devcontainer"
+)
+
+// Formats lists every supported export format
+var Formats = []Format{FormatShell, FormatDockerfile, FormatAnsible, FormatDevcontainer}
+
+// ParseFormat checks an export format name
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return "", fmt.Errorf("unknown export format %q: expected %s", name, strings.Join(names, ", "))
+}
+
+// Write renders a plan in a format. Formats that run inside a container
+// only support linux targets, and shell scripts do not run on windows.
+func Write(w io.Writer, plan *Plan, format Format) error {
+	switch format {
+	case FormatShell:
+		if plan.Target.OS == "windows" {
+			return fmt.Errorf("sh export does not support windows targets; use ansible")
+		}
+		return writeShell(w, plan)
+	case FormatDockerfile, FormatDevcontainer:
+		if plan.Target.OS != "linux" {
+			return fmt.Errorf("%s export only supports linux targets", format)
+		}
+		if plan.Target.Image == "" {
+			return fmt.Errorf("no container image known for %s; pass --image", plan.Target.Distro)
+		}
+		if format == FormatDockerfile {
+			return writeDockerfile(w, plan)
+		}
+		return writeDevcontainer(w, plan)
+	case FormatAnsible:
+		return writeAnsible(w, plan)
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
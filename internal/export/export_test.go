@@ -0,0 +1,240 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const exportConfig = `
+profile: dev
+settings:
+  verify_installations: true
+tools:
+  - name: git
+    version: latest
+    linux:
+      package_names: {apt: git}
+    windows:
+      package_names: {choco: git}
+  - name: tool
+    version: latest
+    linux:
+      installer: https://example.com/tool.deb
+      type: deb
+      sha256: ABCDEF
+      headers: {Accept: application/octet-stream}
+    windows:
+      installer: https://example.com/tool.exe
+      type: exe
+      sha256: ABCDEF
+      silent_flags: [/quiet]
+    post_install:
+      - command: sh
+        args: [-c, "echo 'done' > /tmp/tool"]
+        sudo: true
+        env: {MODE: fast}
+        ignore_error: true
+        wait_for: 3
+  - name: gui
+    version: latest
+    macos:
+      brew: gui
+`
+
+func render(t *testing.T, osName, distro string, format Format) string {
+	t.Helper()
+	plan, err := Build(load(t, exportConfig), mustTarget(t, osName, distro))
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, plan, format); err != nil {
+		t.Fatalf("Write(%s) error: %v", format, err)
+	}
+	return buf.String()
+}
+
+func contains(t *testing.T, out string, wants ...string) {
+	t.Helper()
+	for _, want := range wants {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteShell(t *testing.T) {
+	out := render(t, "linux", "debian:12", FormatShell)
+	contains(t, out,
+		"#!/bin/sh\n# Generated by stackup export for linux/debian:12 (amd64), profile dev\nset -eu\n",
+		"\n# git\nsudo env DEBIAN_FRONTEND=noninteractive apt-get install -y git\ngit --version || echo 'warning: git verification failed' >&2\n",
+		"mkdir -p /tmp/stackup\ncurl -fsSL -H 'Accept: application/octet-stream' -o /tmp/stackup/tool.deb https://example.com/tool.deb\n",
+		"echo 'abcdef  /tmp/stackup/tool.deb' | sha256sum -c -\nsudo dpkg -i /tmp/stackup/tool.deb\n",
+		`sudo env MODE=fast sh -c 'echo '\''done'\'' > /tmp/tool' || true`+"\nsleep 3\n",
+		"# gui: skipped, no linux configuration\n",
+	)
+}
+
+func TestWriteDockerfile(t *testing.T) {
+	out := render(t, "linux", "debian:12", FormatDockerfile)
+	contains(t, out,
+		"FROM --platform=linux/amd64 debian:12\nARG DEBIAN_FRONTEND=noninteractive\n",
+		"RUN apt-get update && apt-get install -y curl ca-certificates\n",
+		"RUN DEBIAN_FRONTEND=noninteractive apt-get install -y git && \\\n    { git --version || echo 'warning: git verification failed' >&2; }\n",
+		"    dpkg -i /tmp/stackup/tool.deb && \\\n",
+		`    { MODE=fast sh -c 'echo '\''done'\'' > /tmp/tool' || true; } && \`,
+	)
+	if strings.Contains(out, "sudo") {
+		t.Errorf("Dockerfile uses sudo:\n%s", out)
+	}
+}
+
+func TestWriteDockerfileAlpine(t *testing.T) {
+	cfg := load(t, `
+tools:
+  - name: git
+    version: latest
+    linux:
+      package_names: {apt: git, apk: git}
+  - name: rustup
+    version: latest
+    linux:
+      installer: https://sh.rustup.rs
+      type: sh
+`)
+	plan, err := Build(cfg, mustTarget(t, "linux", "alpine:3.20"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, plan, FormatDockerfile); err != nil {
+		t.Fatal(err)
+	}
+	contains(t, buf.String(),
+		"FROM --platform=linux/amd64 alpine:3.20\nRUN apk update && apk add curl\n",
+		"RUN apk add git\n",
+	)
+}
+
+func TestWriteDevcontainer(t *testing.T) {
+	plan, err := Build(load(t, exportConfig), Target{OS: "linux", Arch: "arm64", Distro: "ubuntu", PackageManager: "apt", Image: "ubuntu:24.04"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, plan, FormatDevcontainer); err != nil {
+		t.Fatal(err)
+	}
+
+	var dc devcontainer
+	if err := json.Unmarshal(buf.Bytes(), &dc); err != nil {
+		t.Fatalf("Output is not JSON: %v\n%s", err, buf.String())
+	}
+	if dc.Name != "dev" || dc.Image != "ubuntu:24.04" || strings.Join(dc.RunArgs, " ") != "--platform=linux/arm64" {
+		t.Errorf("devcontainer = %+v", dc)
+	}
+	contains(t, dc.OnCreateCommand, "set -eu\nexport DEBIAN_FRONTEND=noninteractive\napt-get update\n", "\nDEBIAN_FRONTEND=noninteractive apt-get install -y git\n")
+}
+
+func TestWriteAnsible(t *testing.T) {
+	var plays []struct {
+		Hosts string                   `yaml:"hosts"`
+		Tasks []map[string]interface{} `yaml:"tasks"`
+	}
+
+	out := render(t, "linux", "ubuntu", FormatAnsible)
+	contains(t, out, "# gui: skipped, no linux configuration\n")
+	if err := yaml.Unmarshal([]byte(out), &plays); err != nil {
+		t.Fatalf("Output is not YAML: %v\n%s", err, out)
+	}
+	if len(plays) != 1 || plays[0].Hosts != "all" {
+		t.Fatalf("plays = %+v", plays)
+	}
+
+	var modules []string
+	for _, task := range plays[0].Tasks {
+		for key := range task {
+			if strings.Contains(key, ".") {
+				modules = append(modules, key)
+			}
+		}
+	}
+	want := "ansible.builtin.apt ansible.builtin.command ansible.builtin.file ansible.builtin.get_url ansible.builtin.command ansible.builtin.command ansible.builtin.pause ansible.builtin.command"
+	if got := strings.Join(modules, " "); got != want {
+		t.Errorf("modules = %s\nwant %s", got, want)
+	}
+
+	download := plays[0].Tasks[3]["ansible.builtin.get_url"].(map[string]interface{})
+	if download["checksum"] != "sha256:abcdef" || download["dest"] != "/tmp/stackup/tool.deb" {
+		t.Errorf("get_url = %v", download)
+	}
+	post := plays[0].Tasks[5]
+	if post["become"] != true || post["ignore_errors"] != true || post["environment"].(map[string]interface{})["MODE"] != "fast" {
+		t.Errorf("post_install task = %v", post)
+	}
+
+	out = render(t, "windows", "", FormatAnsible)
+	contains(t, out,
+		"chocolatey.chocolatey.win_chocolatey:\n",
+		"ansible.windows.win_get_url:\n",
+		"checksum_algorithm",
+		`ansible.windows.win_command: C:\Windows\Temp\tool.exe /quiet`,
+		`ansible.windows.win_command: sh -c "echo 'done' > /tmp/tool"`,
+	)
+	if strings.Contains(out, "become") {
+		t.Errorf("Windows playbook uses become:\n%s", out)
+	}
+}
+
+func TestWriteUnsupportedTargets(t *testing.T) {
+	plan := &Plan{Target: Target{OS: "windows"}}
+	tests := []struct {
+		target Target
+		format Format
+		want   string
+	}{
+		{Target{OS: "windows"}, FormatShell, "sh export does not support windows targets"},
+		{Target{OS: "darwin"}, FormatDockerfile, "dockerfile export only supports linux targets"},
+		{Target{OS: "darwin"}, FormatDevcontainer, "devcontainer export only supports linux targets"},
+		{Target{OS: "linux", Distro: "manjaro"}, FormatDockerfile, "no container image known for manjaro"},
+	}
+
+	for _, tt := range tests {
+		plan.Target = tt.target
+		err := Write(&bytes.Buffer{}, plan, tt.format)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Write(%s, %s) error = %v, want %q", tt.target.OS, tt.format, err, tt.want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := ParseFormat("Dockerfile"); err != nil || format != FormatDockerfile {
+		t.Errorf("ParseFormat(Dockerfile) = %q, %v", format, err)
+	}
+	if _, err := ParseFormat("puppet"); err == nil || !strings.Contains(err.Error(), "expected sh, dockerfile, ansible, devcontainer") {
+		t.Errorf("ParseFormat(puppet) error = %v", err)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"git":             "git",
+		"--id=Git.Git":    "--id=Git.Git",
+		"":                "''",
+		"two words":       "'two words'",
+		"it's":            `'it'\''s'`,
+		"$HOME":           "'$HOME'",
+		"https://a.b/c?d": "'https://a.b/c?d'",
+	}
+	for in, want := range tests {
+		if got := quote(in); got != want {
+			t.Errorf("quote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
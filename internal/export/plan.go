@@ -0,0 +1,395 @@
+// Package export turns a config into the commands that would install it on
+// a chosen platform, written as a shell script, Dockerfile, Ansible playbook
+// or dev container definition
+package export
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/backend"
+	"github.com/araldhafeeri/stackup/internal/condition"
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/domain"
+	"github.com/araldhafeeri/stackup/internal/executor"
+	"github.com/araldhafeeri/stackup/internal/installer"
+)
+
+// staticFacts are the facts known from the target alone. Conditions on
+// anything else depend on the machine the export eventually runs on.
+var staticFacts = []string{"os", "arch", "distro", "distro_version", "package_manager", "libc"}
+
+// Plan is what installing a config on a target would run, tool by tool in
+// install order
+type Plan struct {
+	Target  Target
+	Profile string
+	Tools   []ToolPlan
+}
+
+// ToolPlan is the steps that install one tool
+type ToolPlan struct {
+	Name           string
+	DisplayName    string
+	Skipped        string // why the tool is not installed on the target
+	Steps          []Step
+	RequiresReboot bool
+}
+
+// Step is a single command. Package installs and downloads keep their
+// details so formats with dedicated modules can use them.
+type Step struct {
+	Description string
+	Args        []string
+	Sudo        bool
+	Env         map[string]string
+	IgnoreError bool
+	WaitFor     int  // seconds to wait afterwards
+	Verify      bool // a failure only warns, like the installer's verification
+
+	Package  *Package  // set when Args installs a package
+	Download *Download // set when the step downloads and runs an installer; Args is empty
+}
+
+// Package is a package installed by a package manager
+type Package struct {
+	Manager string
+	Name    string
+}
+
+// Download is an installer downloaded and run on the target
+type Download struct {
+	URL         string
+	Headers     map[string]string
+	SHA256      string
+	File        string // file name to save the download as
+	Type        string
+	SilentFlags []string
+}
+
+// Build resolves the plan for installing cfg on target. Everything that
+// cannot be expressed without running on the target, such as secrets and
+// conditions that probe the host, is reported together.
+func Build(cfg *config.Config, target Target) (*Plan, error) {
+	// The target's managers are preferred like the installer prefers the
+	// host's
+	sys := target.System()
+	sys.PreferPackageManagers(cfg.Settings.ManagerPriority)
+
+	tools, err := installer.Order(cfg.Tools, sys)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &builder{target: target, system: sys, facts: condition.NewFacts(sys)}
+	plan := &Plan{Target: target, Profile: cfg.Profile}
+	for _, tool := range tools {
+		b.tool = tool
+		plan.Tools = append(plan.Tools, b.build(tool, cfg.Settings.VerifyInstallations))
+	}
+
+	if len(b.errs) > 0 {
+		return nil, errors.Join(b.errs...)
+	}
+	return plan, nil
+}
+
+// builder collects the steps of each tool and the problems found
+type builder struct {
+	target Target
+	system *domain.System // the target's system, with its managers in order of preference
+	facts  *condition.Facts
+	tool   *config.Tool
+	errs   []error
+}
+
+func (b *builder) fail(format string, args ...interface{}) {
+	b.errs = append(b.errs, fmt.Errorf("tool %q: %s", b.tool.Name, fmt.Sprintf(format, args...)))
+}
+
+// build mirrors Installer.installTool for the target
+func (b *builder) build(tool *config.Tool, verify bool) ToolPlan {
+	tp := ToolPlan{Name: tool.Name, DisplayName: tool.GetDisplayName(), RequiresReboot: tool.RequiresReboot}
+
+	if !b.evaluate(tool.When, "when") {
+		tp.Skipped = "condition false"
+		return tp
+	}
+
+	var platform *config.PlatformConfig
+	if len(tool.CustomInstall) == 0 {
+		platform = tool.PlatformConfigFor(b.target.OS, b.system.DistroIDs()...)
+		if platform == nil {
+			tp.Skipped = fmt.Sprintf("no %s configuration", platformName(b.target.OS))
+			return tp
+		}
+		if !b.evaluate(platform.When, platformName(b.target.OS)+".when") {
+			tp.Skipped = "condition false"
+			return tp
+		}
+	}
+
+	tp.Steps = append(tp.Steps, b.commands(tool.PreInstall, "pre_install")...)
+
+	switch {
+	case len(tool.CustomInstall) > 0:
+		tp.Steps = append(tp.Steps, b.commands(tool.CustomInstall, "custom_install")...)
+	case len(platform.CustomCommands) > 0:
+		tp.Steps = append(tp.Steps, b.commands(platform.CustomCommands, "custom_commands")...)
+	default:
+		tp.Steps = append(tp.Steps, b.install(tool, platform)...)
+	}
+
+	tp.Steps = append(tp.Steps, b.commands(tool.PostInstall, "post_install")...)
+
+	if verify {
+		args := strings.Fields(tool.VerifyCommand)
+		if len(args) == 0 {
+			args = []string{tool.Name, "--version"}
+		}
+		tp.Steps = append(tp.Steps, Step{Args: args, Verify: true})
+	}
+
+	return tp
+}
+
+// evaluate decides a when condition for the target
+func (b *builder) evaluate(expr, field string) bool {
+	if expr == "" {
+		return true
+	}
+
+	parsed, err := condition.Parse(expr)
+	if err != nil {
+		b.fail("invalid %s expression: %v", field, err)
+		return false
+	}
+	if !parsed.DependsOnlyOn(staticFacts...) {
+		b.fail("%s %q depends on the machine it runs on and cannot be exported; only %s are known", field, expr, strings.Join(staticFacts, ", "))
+		return false
+	}
+	return parsed.Eval(b.facts)
+}
+
+// commands converts configured commands, dropping those whose condition is
+// false on the target
+func (b *builder) commands(commands []config.Command, field string) []Step {
+	var steps []Step
+	for _, cmd := range commands {
+		if !b.evaluate(cmd.When, field+".when") {
+			continue
+		}
+		for key, val := range cmd.Env {
+			if hasSecret(val) {
+				b.fail("%s: env %s uses a secret, which is only read at install time and cannot be exported", field, key)
+			}
+		}
+
+		steps = append(steps, Step{
+			Description: cmd.Description,
+			Args:        append([]string{cmd.Command}, cmd.Args...),
+			Sudo:        cmd.Sudo && b.target.OS != "windows",
+			Env:         cmd.Env,
+			IgnoreError: cmd.IgnoreError,
+			WaitFor:     cmd.WaitFor,
+		})
+	}
+	return steps
+}
+
+// install picks a package or a download, like the installer does when a
+// tool has no custom commands
+func (b *builder) install(tool *config.Tool, platform *config.PlatformConfig) []Step {
+	manager, name := b.packageFor(tool, platform)
+	if manager != "" {
+		// Keys are fetched and checked while installing, which a static
+		// export cannot do
+		if len(platform.Repositories) > 0 {
+			b.fail("repositories cannot be exported; add them with pre_install commands")
+			return nil
+		}
+		return b.packageSteps(manager, executor.Package(tool, name, platform))
+	}
+
+	if platform.Installer == "" {
+		b.fail("no %s package and no installer for %s", b.system.PackageManager, platformName(b.target.OS))
+		return nil
+	}
+
+	for key, val := range platform.Headers {
+		if hasSecret(val) {
+			b.fail("header %s uses a secret, which is only read at install time and cannot be exported", key)
+		}
+	}
+
+	d := &Download{
+		URL:         platform.Installer,
+		Headers:     platform.Headers,
+		SHA256:      platform.SHA256,
+		Type:        platform.Type,
+		SilentFlags: platform.SilentFlags,
+		File:        downloadFile(tool.Name, platform),
+	}
+	if os := installerOS(d.Type); os != "" && os != b.target.OS {
+		b.fail("a %s installer cannot run on %s", d.Type, platformName(b.target.OS))
+	}
+	return []Step{{Description: "Download " + d.URL, Download: d}}
+}
+
+// packageFor returns the manager and package to install, or no manager when
+// the tool should be downloaded instead. Managers are tried in the order of
+// settings.manager_priority, like the installer does. Unlike the installer,
+// which tries the package manager first and downloads on failure, a tool
+// whose packages are all for other managers is downloaded, or installed
+// with another manager the target can have.
+func (b *builder) packageFor(tool *config.Tool, platform *config.PlatformConfig) (string, string) {
+	if tool.Manager != "" {
+		if name, ok := platform.PackageNames[tool.Manager]; ok {
+			return tool.Manager, name
+		}
+		return tool.Manager, tool.Name
+	}
+
+	// Only the default manager is sure to be on the target, so the others
+	// are used when the tool has no installer to fall back on
+	managers := b.system.AvailablePackageManagers()
+	for idx, manager := range managers {
+		if idx > 0 && platform.Installer != "" {
+			break
+		}
+		if name, ok := platform.PackageNames[manager]; ok {
+			return manager, name
+		}
+		if platform.Brew != "" && manager == domain.PackageManagerBrew {
+			return manager, platform.Brew
+		}
+	}
+	if len(platform.PackageNames) > 0 || platform.Installer != "" {
+		return "", ""
+	}
+	return b.system.PackageManager, tool.Name
+}
+
+// packageSteps install a package without prompting, as exports run
+// unattended. The last step installs the package; managers such as flatpak
+// prepare a source first.
+func (b *builder) packageSteps(manager string, pkg backend.Package) []Step {
+	rec := &recorder{}
+	pm, err := backend.New(manager, rec, backend.Options{})
+	if err != nil {
+		b.fail("%v", err)
+		return nil
+	}
+	if err := pm.Install(pkg); err != nil || len(rec.commands) == 0 {
+		b.fail("%s cannot be exported", manager)
+		return nil
+	}
+
+	steps := make([]Step, len(rec.commands))
+	for i, args := range rec.commands {
+		if args[0] == "sudo" {
+			args, steps[i].Sudo = args[1:], true
+		}
+		// Variables set with env, such as apt's DEBIAN_FRONTEND, become the
+		// step's environment
+		if args[0] == "env" {
+			steps[i].Env = make(map[string]string)
+			for args = args[1:]; len(args) > 1 && strings.Contains(args[0], "="); args = args[1:] {
+				key, val, _ := strings.Cut(args[0], "=")
+				steps[i].Env[key] = val
+			}
+		}
+		steps[i].Args = args
+	}
+	steps[len(steps)-1].Package = &Package{Manager: manager, Name: pkg.Name}
+	return steps
+}
+
+// recorder captures the commands of a backend instead of running them
+type recorder struct {
+	commands [][]string
+}
+
+func (r *recorder) Run(name string, args ...string) error {
+	r.commands = append(r.commands, append([]string{name}, args...))
+	return nil
+}
+
+// Output fails: nothing can be queried on the target while exporting
+func (r *recorder) Output(name string, args ...string) ([]byte, error) {
+	return nil, fmt.Errorf("%s cannot be queried when exporting", name)
+}
+
+func (r *recorder) LookPath(string) bool { return false }
+
+// InstallSteps returns the commands that run a downloaded installer saved
+// at file, matching the installer's handling of each type
+func (d *Download) InstallSteps(file string) []Step {
+	switch d.Type {
+	case "exe", "msi":
+		if len(d.SilentFlags) > 0 {
+			return []Step{{Args: append([]string{file}, d.SilentFlags...)}}
+		}
+		if d.Type == "msi" {
+			return []Step{{Args: []string{"msiexec", "/i", file, "/quiet", "/norestart"}}}
+		}
+		return []Step{{Args: []string{file, "/S"}}}
+	case "sh", "bash":
+		return []Step{{Args: []string{"bash", file}}}
+	case "deb":
+		return []Step{{Args: []string{"dpkg", "-i", file}, Sudo: true}}
+	case "rpm":
+		return []Step{{Args: []string{"rpm", "-i", file}, Sudo: true}}
+	case "dmg":
+		return []Step{{Args: []string{"hdiutil", "attach", file}}}
+	case "pkg":
+		return []Step{{Args: []string{"installer", "-pkg", file, "-target", "/"}, Sudo: true}}
+	case "appimage":
+		return []Step{{Args: []string{"chmod", "+x", file}}}
+	default:
+		return []Step{{Args: []string{"chmod", "+x", file}}, {Args: []string{file}}}
+	}
+}
+
+// downloadFile names a download like the installer does
+func downloadFile(toolName string, platform *config.PlatformConfig) string {
+	if platform.Type != "" {
+		return toolName + "." + platform.Type
+	}
+	if base := path.Base(platform.Installer); base != "" && base != "." && base != "/" {
+		return base
+	}
+	return toolName + ".installer"
+}
+
+// installerOS returns the only OS an installer type runs on, if any
+func installerOS(installerType string) string {
+	switch installerType {
+	case "exe", "msi":
+		return "windows"
+	case "dmg", "pkg":
+		return "darwin"
+	case "deb", "rpm", "appimage":
+		return "linux"
+	}
+	return ""
+}
+
+// platformName returns the config section name of an OS
+func platformName(osName string) string {
+	if osName == "darwin" {
+		return "macos"
+	}
+	return osName
+}
+
+func hasSecret(s string) bool {
+	_, err := config.ExpandSecrets(s, func(string) (string, error) {
+		return "", errSecret
+	})
+	return err != nil
+}
+
+var errSecret = errors.New("secret")
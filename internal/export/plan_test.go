@@ -0,0 +1,282 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+// load reads a YAML config
+func load(t *testing.T, yaml string) *config.Config {
+	t.Helper()
+	cfg, err := config.Load(config.LoadOptions{Stdin: strings.NewReader(yaml)}, "-")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	return cfg
+}
+
+func mustTarget(t *testing.T, osName, distro string) Target {
+	t.Helper()
+	target, err := NewTarget(osName, distro, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return target
+}
+
+// steps renders a tool's steps as one line each, marking sudo and verify
+func steps(tp ToolPlan) []string {
+	var lines []string
+	for _, step := range tp.Steps {
+		line := strings.Join(step.Args, " ")
+		if step.Download != nil {
+			line = "download " + step.Download.URL + " as " + step.Download.File
+		}
+		if step.Sudo {
+			line = "sudo " + line
+		}
+		if step.Verify {
+			line = "verify " + line
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+const planConfig = `
+profile: dev
+settings:
+  verify_installations: true
+tools:
+  - name: git
+    version: latest
+    linux:
+      package_names: {apt: git, dnf: git-core}
+    macos:
+      brew: git
+    windows:
+      package_names: {winget: Git.Git}
+  - name: app
+    version: "1.0"
+    dependencies: [git]
+    verify_command: app version
+    requires_reboot: true
+    pre_install:
+      - command: echo
+        args: [ubuntu only]
+        when: distro == "ubuntu"
+    linux:
+      installer: https://example.com/app.sh
+      type: sh
+      sha256: ABC
+      fedora:
+        custom_commands:
+          - command: dnf
+            args: [copr, enable, app/app]
+            sudo: true
+          - command: dnf
+            args: [install, -y, app]
+            sudo: true
+    windows:
+      installer: https://example.com/app-setup.msi
+  - name: tray
+    version: latest
+    when: os != "linux"
+    macos:
+      brew: tray
+`
+
+func TestBuild(t *testing.T) {
+	cfg := load(t, planConfig)
+
+	tests := []struct {
+		os, distro string
+		want       map[string][]string
+		skipped    map[string]string
+	}{
+		{
+			os: "linux", distro: "ubuntu:24.04",
+			want: map[string][]string{
+				"git": {"sudo apt-get install -y git", "verify git --version"},
+				"app": {"echo ubuntu only", "download https://example.com/app.sh as app.sh", "verify app version"},
+			},
+			skipped: map[string]string{"tray": "condition false"},
+		},
+		{
+			os: "linux", distro: "fedora",
+			want: map[string][]string{
+				"git": {"sudo dnf install -y git-core", "verify git --version"},
+				"app": {"sudo dnf copr enable app/app", "sudo dnf install -y app", "verify app version"},
+			},
+			skipped: map[string]string{"tray": "condition false"},
+		},
+		{
+			os: "macos",
+			want: map[string][]string{
+				"git":  {"brew install git", "verify git --version"},
+				"tray": {"brew install tray", "verify tray --version"},
+			},
+			skipped: map[string]string{"app": "no macos configuration"},
+		},
+		{
+			os: "windows",
+			want: map[string][]string{
+				"git": {"winget install -e --id Git.Git --accept-package-agreements --accept-source-agreements", "verify git --version"},
+				"app": {"download https://example.com/app-setup.msi as app-setup.msi", "verify app version"},
+			},
+			skipped: map[string]string{"tray": "no windows configuration"},
+		},
+	}
+
+	for _, tt := range tests {
+		target := mustTarget(t, tt.os, tt.distro)
+		plan, err := Build(cfg, target)
+		if err != nil {
+			t.Errorf("Build(%s) error: %v", target, err)
+			continue
+		}
+
+		var order []string
+		for _, tp := range plan.Tools {
+			order = append(order, tp.Name)
+			if tp.Skipped != tt.skipped[tp.Name] {
+				t.Errorf("%s: %s skipped = %q, want %q", target, tp.Name, tp.Skipped, tt.skipped[tp.Name])
+			}
+			if got, want := strings.Join(steps(tp), "\n"), strings.Join(tt.want[tp.Name], "\n"); got != want {
+				t.Errorf("%s: %s steps:\n%s\nwant:\n%s", target, tp.Name, got, want)
+			}
+		}
+		if strings.Join(order, ",") != "git,app,tray" {
+			t.Errorf("%s: order = %v, want git, app, tray", target, order)
+		}
+		if !plan.Tools[1].RequiresReboot {
+			t.Errorf("%s: app does not require a reboot", target)
+		}
+	}
+}
+
+func TestBuildUniversalPackages(t *testing.T) {
+	cfg := load(t, `
+tools:
+  - name: vscode
+    version: latest
+    manager: snap
+    linux:
+      package_names: {snap: code}
+      snap: {classic: true}
+  - name: slack
+    version: latest
+    manager: flatpak
+    linux:
+      package_names: {flatpak: com.slack.Slack}
+      flatpak: {scope: user}
+`)
+
+	plan, err := Build(cfg, mustTarget(t, "linux", "ubuntu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"vscode": {"sudo snap install code --classic"},
+		"slack": {
+			"flatpak remote-add --user --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo",
+			"flatpak install --user -y flathub com.slack.Slack",
+		},
+	}
+	for _, tp := range plan.Tools {
+		if got, want := strings.Join(steps(tp), "\n"), strings.Join(want[tp.Name], "\n"); got != want {
+			t.Errorf("%s steps:\n%s\nwant:\n%s", tp.Name, got, want)
+		}
+		if tp.Steps[len(tp.Steps)-1].Package == nil {
+			t.Errorf("%s: last step does not install the package", tp.Name)
+		}
+	}
+}
+
+func TestBuildManagerPriority(t *testing.T) {
+	const tools = `
+tools:
+  - name: git
+    version: latest
+    windows:
+      package_names: {winget: Git.Git, choco: git}
+`
+	for _, tt := range []struct {
+		settings string
+		want     string
+	}{
+		{"", "winget install -e --id Git.Git --accept-package-agreements --accept-source-agreements"},
+		{"settings:\n  manager_priority: [choco]\n", "choco install git -y"},
+	} {
+		plan, err := Build(load(t, tt.settings+tools), mustTarget(t, "windows", ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(steps(plan.Tools[0]), "\n"); got != tt.want {
+			t.Errorf("%q: steps = %s, want %s", tt.settings, got, tt.want)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	cfg := load(t, `
+secrets:
+  token: {env: TOKEN}
+tools:
+  - name: probe
+    version: latest
+    when: command_exists("docker")
+    linux: {}
+  - name: image
+    version: latest
+    linux:
+      installer: https://example.com/image.dmg
+      type: dmg
+  - name: private
+    version: latest
+    linux:
+      installer: https://example.com/private.sh
+      headers: {Authorization: "Bearer ${secret:token}"}
+  - name: npmrc
+    version: latest
+    custom_install:
+      - command: npm
+        args: [install, -g, private]
+        env: {NPM_TOKEN: "${secret:token}"}
+  - name: other
+    version: latest
+    linux:
+      package_names: {dnf: other}
+  - name: docker
+    version: latest
+    linux:
+      repositories:
+        - {name: docker, url: "https://download.docker.com/linux/ubuntu"}
+  - name: fine
+    version: latest
+    linux: {}
+`)
+
+	_, err := Build(cfg, mustTarget(t, "linux", "ubuntu"))
+	if err == nil {
+		t.Fatal("Build() succeeded, want errors")
+	}
+	for _, want := range []string{
+		`tool "probe": when "command_exists(\"docker\")" depends on the machine it runs on`,
+		`tool "image": a dmg installer cannot run on linux`,
+		`tool "private": header Authorization uses a secret`,
+		`tool "npmrc": custom_install: env NPM_TOKEN uses a secret`,
+		`tool "other": no apt package and no installer for linux`,
+		`tool "docker": repositories cannot be exported`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Build() error is missing %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), `"fine"`) {
+		t.Errorf("Build() reported a tool without problems:\n%v", err)
+	}
+}
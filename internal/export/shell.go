@@ -0,0 +1,265 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+// downloadDir is where exported scripts save downloaded installers
+const downloadDir = "/tmp/stackup"
+
+// statement is one shell command line
+type statement struct {
+	text     string
+	compound bool // uses ||, so it is grouped when chained with &&
+}
+
+// shell writes plan steps as POSIX shell
+type shell struct {
+	sudo   bool // keep sudo; containers build as root and drop it
+	darwin bool
+}
+
+// tool returns the statements that install one tool
+func (sh shell) tool(tp ToolPlan) []statement {
+	var out []statement
+	for _, step := range tp.Steps {
+		if step.Download != nil {
+			out = append(out, sh.download(step.Download)...)
+			continue
+		}
+
+		s := sh.command(step)
+		switch {
+		case step.Verify:
+			s = statement{text: fmt.Sprintf("%s || echo %s >&2", s.text, quote("warning: "+tp.Name+" verification failed")), compound: true}
+		case step.IgnoreError:
+			s = statement{text: s.text + " || true", compound: true}
+		}
+		out = append(out, s)
+
+		if step.WaitFor > 0 {
+			out = append(out, statement{text: fmt.Sprintf("sleep %d", step.WaitFor)})
+		}
+	}
+	return out
+}
+
+// command renders a step's command with its environment and sudo
+func (sh shell) command(step Step) statement {
+	var words []string
+	sudo := step.Sudo && sh.sudo
+	if sudo {
+		words = append(words, "sudo")
+		if len(step.Env) > 0 {
+			words = append(words, "env")
+		}
+	}
+	for _, key := range sortedKeys(step.Env) {
+		words = append(words, key+"="+quote(step.Env[key]))
+	}
+	for _, arg := range step.Args {
+		words = append(words, quote(arg))
+	}
+	return statement{text: strings.Join(words, " ")}
+}
+
+// download fetches an installer, checks it and runs it
+func (sh shell) download(d *Download) []statement {
+	file := downloadDir + "/" + d.File
+	curl := []string{"curl", "-fsSL"}
+	for _, key := range sortedKeys(d.Headers) {
+		curl = append(curl, "-H", quote(key+": "+d.Headers[key]))
+	}
+	curl = append(curl, "-o", quote(file), quote(d.URL))
+
+	out := []statement{{text: "mkdir -p " + downloadDir}, {text: strings.Join(curl, " ")}}
+	if d.SHA256 != "" {
+		check := "sha256sum -c -"
+		if sh.darwin {
+			check = "shasum -a 256 -c -"
+		}
+		out = append(out, statement{text: fmt.Sprintf("echo %s | %s", quote(strings.ToLower(d.SHA256)+"  "+file), check)})
+	}
+	for _, step := range d.InstallSteps(file) {
+		out = append(out, sh.command(step))
+	}
+	return out
+}
+
+// header returns comment lines describing where a plan came from
+func header(plan *Plan) []string {
+	lines := []string{fmt.Sprintf("Generated by stackup export for %s", plan.Target)}
+	if plan.Profile != "" {
+		lines[0] += ", profile " + plan.Profile
+	}
+	return lines
+}
+
+// toolComment returns the comment introducing a tool
+func toolComment(tp ToolPlan) string {
+	switch {
+	case tp.Skipped != "":
+		return fmt.Sprintf("%s: skipped, %s", tp.DisplayName, tp.Skipped)
+	case tp.RequiresReboot:
+		return fmt.Sprintf("%s (requires a reboot)", tp.DisplayName)
+	}
+	return tp.DisplayName
+}
+
+func writeShell(w io.Writer, plan *Plan) error {
+	sh := shell{sudo: true, darwin: plan.Target.OS == "darwin"}
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	for _, line := range header(plan) {
+		fmt.Fprintf(&b, "# %s\n", line)
+	}
+	b.WriteString("set -eu\n")
+
+	for _, tp := range plan.Tools {
+		fmt.Fprintf(&b, "\n# %s\n", toolComment(tp))
+		for _, s := range sh.tool(tp) {
+			fmt.Fprintln(&b, s.text)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// containerSetup returns the statements that prepare a base image: package
+// indexes are empty in official images, and some lack curl
+func containerSetup(plan *Plan) []statement {
+	curl := false
+	for _, tp := range plan.Tools {
+		for _, step := range tp.Steps {
+			curl = curl || step.Download != nil || len(step.Args) > 0 && step.Args[0] == "curl"
+		}
+	}
+
+	switch plan.Target.PackageManager {
+	case domain.PackageManagerAPT:
+		out := []statement{{text: "apt-get update"}}
+		if curl {
+			out = append(out, statement{text: "apt-get install -y curl ca-certificates"})
+		}
+		return out
+	case domain.PackageManagerAPK:
+		out := []statement{{text: "apk update"}}
+		if curl {
+			out = append(out, statement{text: "apk add curl"})
+		}
+		return out
+	case domain.PackageManagerPacman:
+		return []statement{{text: "pacman -Sy --noconfirm"}}
+	}
+	return nil
+}
+
+// chain joins statements into one command line
+func chain(statements []statement, sep string) string {
+	texts := make([]string, len(statements))
+	for i, s := range statements {
+		texts[i] = s.text
+		if s.compound && len(statements) > 1 {
+			texts[i] = "{ " + s.text + "; }"
+		}
+	}
+	return strings.Join(texts, sep)
+}
+
+func writeDockerfile(w io.Writer, plan *Plan) error {
+	sh := shell{}
+
+	var b strings.Builder
+	for _, line := range header(plan) {
+		fmt.Fprintf(&b, "# %s\n", line)
+	}
+	fmt.Fprintf(&b, "FROM --platform=linux/%s %s\n", plan.Target.Arch, plan.Target.Image)
+	if plan.Target.PackageManager == domain.PackageManagerAPT {
+		b.WriteString("ARG DEBIAN_FRONTEND=noninteractive\n")
+	}
+	if setup := containerSetup(plan); len(setup) > 0 {
+		fmt.Fprintf(&b, "RUN %s\n", chain(setup, " && "))
+	}
+
+	for _, tp := range plan.Tools {
+		fmt.Fprintf(&b, "\n# %s\n", toolComment(tp))
+		if statements := sh.tool(tp); len(statements) > 0 {
+			fmt.Fprintf(&b, "RUN %s\n", chain(statements, " && \\\n    "))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// devcontainer is the part of devcontainer.json an export sets
+type devcontainer struct {
+	Name            string   `json:"name"`
+	Image           string   `json:"image"`
+	RunArgs         []string `json:"runArgs,omitempty"`
+	OnCreateCommand string   `json:"onCreateCommand"`
+}
+
+func writeDevcontainer(w io.Writer, plan *Plan) error {
+	sh := shell{}
+
+	lines := []string{"set -eu"}
+	if plan.Target.PackageManager == domain.PackageManagerAPT {
+		lines = append(lines, "export DEBIAN_FRONTEND=noninteractive")
+	}
+	for _, s := range containerSetup(plan) {
+		lines = append(lines, s.text)
+	}
+	for _, tp := range plan.Tools {
+		lines = append(lines, "# "+toolComment(tp))
+		for _, s := range sh.tool(tp) {
+			lines = append(lines, s.text)
+		}
+	}
+
+	dc := devcontainer{
+		Name:            plan.Profile,
+		Image:           plan.Target.Image,
+		OnCreateCommand: strings.Join(lines, "\n"),
+	}
+	if dc.Name == "" {
+		dc.Name = "stackup"
+	}
+	if plan.Target.Arch != "amd64" {
+		dc.RunArgs = []string{"--platform=linux/" + plan.Target.Arch}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(dc)
+}
+
+// plainWord matches words the shell reads literally
+var plainWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// quote quotes a word for POSIX shells when it needs it
+func quote(s string) string {
+	if plainWord.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,157 @@
+package export
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+// Target is the platform an export is generated for, chosen on the command
+// line rather than detected from the host
+type Target struct {
+	OS             string // linux, darwin or windows
+	Arch           string
+	Distro         string
+	DistroVersion  string
+	DistroLike     []string
+	Libc           string
+	PackageManager string
+	Image          string // container base image, for linux targets
+}
+
+// distroInfo describes a Linux distribution an export can target
+type distroInfo struct {
+	like    []string
+	manager string
+	image   string // official container image, if any
+	musl    bool
+}
+
+// distros are the Linux distributions with a known package manager
+var distros = map[string]distroInfo{
+	"ubuntu":              {like: []string{"debian"}, manager: domain.PackageManagerAPT, image: "ubuntu"},
+	"debian":              {manager: domain.PackageManagerAPT, image: "debian"},
+	"linuxmint":           {like: []string{"ubuntu", "debian"}, manager: domain.PackageManagerAPT},
+	"pop":                 {like: []string{"ubuntu", "debian"}, manager: domain.PackageManagerAPT},
+	"fedora":              {manager: domain.PackageManagerDNF, image: "fedora"},
+	"rhel":                {like: []string{"fedora"}, manager: domain.PackageManagerDNF, image: "redhat/ubi9"},
+	"rocky":               {like: []string{"rhel", "centos", "fedora"}, manager: domain.PackageManagerDNF, image: "rockylinux"},
+	"almalinux":           {like: []string{"rhel", "centos", "fedora"}, manager: domain.PackageManagerDNF, image: "almalinux"},
+	"arch":                {manager: domain.PackageManagerPacman, image: "archlinux"},
+	"manjaro":             {like: []string{"arch"}, manager: domain.PackageManagerPacman},
+	"endeavouros":         {like: []string{"arch"}, manager: domain.PackageManagerPacman},
+	"centos":              {like: []string{"rhel", "fedora"}, manager: domain.PackageManagerYum, image: "centos"},
+	"opensuse-leap":       {like: []string{"suse", "opensuse"}, manager: domain.PackageManagerZypper, image: "opensuse/leap"},
+	"opensuse-tumbleweed": {like: []string{"opensuse", "suse"}, manager: domain.PackageManagerZypper, image: "opensuse/tumbleweed"},
+	"alpine":              {manager: domain.PackageManagerAPK, image: "alpine", musl: true},
+	"void":                {manager: domain.PackageManagerXBPS},
+	"gentoo":              {manager: domain.PackageManagerEmerge, image: "gentoo/stage3"},
+	"nixos":               {manager: domain.PackageManagerNix, image: "nixos/nix"},
+}
+
+// NewTarget builds a target from an OS (linux, macos or windows), a Linux
+// distribution written as id or id:version, and an architecture. Linux
+// defaults to ubuntu.
+func NewTarget(osName, distro, arch string) (Target, error) {
+	t := Target{Arch: arch}
+	if t.Arch == "" {
+		t.Arch = "amd64"
+	}
+
+	switch strings.ToLower(osName) {
+	case "linux", "":
+		t.OS = "linux"
+	case "macos", "darwin", "mac":
+		t.OS = "darwin"
+		t.PackageManager = domain.PackageManagerBrew
+	case "windows":
+		t.OS = "windows"
+		t.PackageManager = domain.PackageManagerWinget
+	default:
+		return t, fmt.Errorf("unknown os %q: expected linux, macos or windows", osName)
+	}
+
+	if t.OS != "linux" {
+		if distro != "" {
+			return t, fmt.Errorf("--distro only applies to linux targets")
+		}
+		return t, nil
+	}
+
+	if distro == "" {
+		distro = "ubuntu"
+	}
+	t.Distro, t.DistroVersion, _ = strings.Cut(strings.ToLower(distro), ":")
+
+	info, ok := distros[t.Distro]
+	if !ok {
+		return t, fmt.Errorf("unknown distro %q: expected one of %s", t.Distro, strings.Join(distroNames(), ", "))
+	}
+	t.DistroLike = info.like
+	t.PackageManager = info.manager
+	t.Libc = domain.LibcGlibc
+	if info.musl {
+		t.Libc = domain.LibcMusl
+	}
+
+	if info.image != "" {
+		tag := t.DistroVersion
+		if tag == "" {
+			tag = "latest"
+		}
+		t.Image = info.image + ":" + tag
+	}
+	return t, nil
+}
+
+// sideManagers are the package managers a target may have besides its
+// default one
+var sideManagers = map[string][]string{
+	"windows": {domain.PackageManagerWinget, domain.PackageManagerChoco},
+}
+
+// System returns the target as a system, for evaluating when conditions
+// and choosing platform sections. Its package managers are the default one
+// followed by those the target may have besides it.
+func (t Target) System() *domain.System {
+	managers := []string{t.PackageManager}
+	for _, manager := range sideManagers[t.OS] {
+		if !slices.Contains(managers, manager) {
+			managers = append(managers, manager)
+		}
+	}
+	return &domain.System{
+		OS:              t.OS,
+		Arch:            t.Arch,
+		PackageManager:  t.PackageManager,
+		PackageManagers: managers,
+		Distro:          t.Distro,
+		DistroVersion:   t.DistroVersion,
+		DistroLike:      t.DistroLike,
+		Libc:            t.Libc,
+	}
+}
+
+// String describes the target, e.g. linux/ubuntu:22.04 (amd64)
+func (t Target) String() string {
+	s := t.OS
+	if t.Distro != "" {
+		s += "/" + t.Distro
+		if t.DistroVersion != "" {
+			s += ":" + t.DistroVersion
+		}
+	}
+	return fmt.Sprintf("%s (%s)", s, t.Arch)
+}
+
+func distroNames() []string {
+	names := make([]string, 0, len(distros))
+	for name := range distros {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
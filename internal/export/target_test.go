@@ -0,0 +1,85 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewTarget(t *testing.T) {
+	tests := []struct {
+		os, distro, arch string
+		want             Target
+		str              string
+	}{
+		{"", "", "", Target{OS: "linux", Arch: "amd64", Distro: "ubuntu", DistroLike: []string{"debian"}, PackageManager: "apt", Image: "ubuntu:latest"}, "linux/ubuntu (amd64)"},
+		{"linux", "fedora:40", "arm64", Target{OS: "linux", Arch: "arm64", Distro: "fedora", DistroVersion: "40", PackageManager: "dnf", Image: "fedora:40"}, "linux/fedora:40 (arm64)"},
+		{"linux", "Manjaro", "", Target{OS: "linux", Arch: "amd64", Distro: "manjaro", DistroLike: []string{"arch"}, PackageManager: "pacman"}, "linux/manjaro (amd64)"},
+		{"linux", "centos:7", "", Target{OS: "linux", Arch: "amd64", Distro: "centos", DistroVersion: "7", DistroLike: []string{"rhel", "fedora"}, PackageManager: "yum", Image: "centos:7"}, "linux/centos:7 (amd64)"},
+		{"linux", "alpine:3.20", "", Target{OS: "linux", Arch: "amd64", Distro: "alpine", DistroVersion: "3.20", PackageManager: "apk", Image: "alpine:3.20"}, "linux/alpine:3.20 (amd64)"},
+		{"macos", "", "arm64", Target{OS: "darwin", Arch: "arm64", PackageManager: "brew"}, "darwin (arm64)"},
+		{"windows", "", "", Target{OS: "windows", Arch: "amd64", PackageManager: "winget"}, "windows (amd64)"},
+	}
+
+	for _, tt := range tests {
+		got, err := NewTarget(tt.os, tt.distro, tt.arch)
+		if err != nil {
+			t.Errorf("NewTarget(%q, %q, %q) error: %v", tt.os, tt.distro, tt.arch, err)
+			continue
+		}
+		if got.String() != tt.str || got.OS != tt.want.OS || got.Arch != tt.want.Arch || got.Distro != tt.want.Distro ||
+			got.DistroVersion != tt.want.DistroVersion || got.PackageManager != tt.want.PackageManager || got.Image != tt.want.Image ||
+			strings.Join(got.DistroLike, ",") != strings.Join(tt.want.DistroLike, ",") {
+			t.Errorf("NewTarget(%q, %q, %q) = %+v, want %+v", tt.os, tt.distro, tt.arch, got, tt.want)
+		}
+	}
+}
+
+func TestNewTargetErrors(t *testing.T) {
+	tests := []struct {
+		os, distro string
+		want       string
+	}{
+		{"beos", "", `unknown os "beos"`},
+		{"linux", "slackware", `unknown distro "slackware"`},
+		{"windows", "ubuntu", "--distro only applies to linux targets"},
+	}
+
+	for _, tt := range tests {
+		_, err := NewTarget(tt.os, tt.distro, "")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("NewTarget(%q, %q) error = %v, want %q", tt.os, tt.distro, err, tt.want)
+		}
+	}
+}
+
+func TestTargetSystem(t *testing.T) {
+	target, err := NewTarget("linux", "rocky:9", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sys := target.System()
+	if sys.Libc != "glibc" || sys.PackageManager != "dnf" {
+		t.Errorf("System() = %+v, want glibc and dnf", sys)
+	}
+	if ids := strings.Join(sys.DistroIDs(), ","); ids != "rocky,rhel,centos,fedora" {
+		t.Errorf("DistroIDs() = %s, want rocky,rhel,centos,fedora", ids)
+	}
+}
+
+func TestTargetLibc(t *testing.T) {
+	for distro, want := range map[string]string{"alpine": "musl", "debian": "glibc"} {
+		target, err := NewTarget("linux", distro, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if libc := target.System().Libc; libc != want {
+			t.Errorf("%s libc = %q, want %q", distro, libc, want)
+		}
+	}
+
+	target, _ := NewTarget("macos", "", "")
+	if libc := target.System().Libc; libc != "" {
+		t.Errorf("macos libc = %q, want none", libc)
+	}
+}
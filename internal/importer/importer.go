@@ -0,0 +1,414 @@
+// Package importer converts the package manifests of other tools, such as
+// Brewfiles and winget exports, into StackUp configs
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/araldhafeeri/stackup/internal/catalog"
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/domain"
+	"github.com/araldhafeeri/stackup/internal/schema"
+)
+
+// Result is an imported config
+type Result struct {
+	doc     *yaml.Node
+	sources []string
+
+	Tools   int // tools in the config
+	Recipes int // tools that use a catalog recipe
+	TODO    int // tools and packages that need editing
+}
+
+// entry is a tool of the imported config: either a catalog recipe or a
+// tool built from the package alone
+type entry struct {
+	recipe  string
+	version string
+	manager string // manager of a recipe installed as a runtime
+	tool    *config.Tool
+	todo    string
+}
+
+// importer maps packages onto catalog recipes
+type importer struct {
+	byPackage map[string]string // manager:name to recipe
+	byName    map[string]string // recipe and package names to recipe, for runtimes
+	recipes   map[string]bool
+
+	entries []*entry
+	tools   map[string]*entry // built tools by name
+	notes   []string
+	result  *Result
+}
+
+// Import builds a config from the packages of one or more manifests. Known
+// packages use the catalog recipe, so the config covers every platform the
+// recipe does. Other packages become tools for their own platform only,
+// marked TODO. Runtimes are installed with mise at their version, and taps
+// become repositories of the formulae installed from them.
+func Import(c *catalog.Catalog, profile string, sources []string, pkgs []Package, notes []string) (*Result, error) {
+	imp := &importer{
+		byPackage: make(map[string]string),
+		byName:    make(map[string]string),
+		recipes:   make(map[string]bool),
+		tools:     make(map[string]*entry),
+		notes:     notes,
+		result:    &Result{sources: sources},
+	}
+	if err := imp.index(c); err != nil {
+		return nil, err
+	}
+
+	// Runtimes go after packages so they can match tools built from them,
+	// and taps last so they can find the formulae from them
+	for _, pkg := range pkgs {
+		if pkg.Manager != "" && pkg.Manager != tapManager {
+			imp.add(pkg)
+		}
+	}
+	for _, pkg := range pkgs {
+		if pkg.Manager == "" {
+			imp.addRuntime(pkg)
+		}
+	}
+	for _, pkg := range pkgs {
+		if pkg.Manager == tapManager {
+			imp.addTap(pkg)
+		}
+	}
+
+	doc, err := imp.document(profile)
+	if err != nil {
+		return nil, err
+	}
+	imp.result.doc = doc
+	imp.result.Tools = len(imp.entries)
+	return imp.result, nil
+}
+
+// index records the package names of every recipe. Recipes are visited in
+// name order, so the first recipe claims a package used by several.
+func (imp *importer) index(c *catalog.Catalog) error {
+	entries, err := c.List()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		tool, err := c.Tool(e.Name)
+		if err != nil {
+			return err
+		}
+
+		imp.byName[tool.Name] = tool.Name
+		imp.recipes[tool.Name] = true
+		claim := func(manager, name string) {
+			if name == "" {
+				return
+			}
+			if key := packageKey(manager, name); imp.byPackage[key] == "" {
+				imp.byPackage[key] = tool.Name
+			}
+			if lower := strings.ToLower(name); imp.byName[lower] == "" {
+				imp.byName[lower] = tool.Name
+			}
+		}
+
+		if tool.MacOS != nil {
+			claim("brew", tool.MacOS.Brew)
+		}
+		for _, platform := range []*config.PlatformConfig{tool.Linux, tool.Windows} {
+			if platform == nil {
+				continue
+			}
+			managers := make([]string, 0, len(platform.PackageNames))
+			for manager := range platform.PackageNames {
+				managers = append(managers, manager)
+			}
+			sort.Strings(managers)
+			for _, manager := range managers {
+				claim(manager, platform.PackageNames[manager])
+			}
+		}
+	}
+	return nil
+}
+
+// packageKey identifies a package of a manager. winget and choco IDs are
+// case-insensitive.
+func packageKey(manager, name string) string {
+	return manager + ":" + strings.ToLower(name)
+}
+
+// add maps one package onto a recipe or a TODO tool
+func (imp *importer) add(pkg Package) {
+	if recipe := imp.byPackage[packageKey(pkg.Manager, pkg.Name)]; recipe != "" {
+		imp.addRecipe(recipe, pkg.Version)
+		return
+	}
+	imp.addPackage(pkg)
+}
+
+// addRuntime installs a runtime of a .tool-versions file with mise, which
+// reads the same file. A runtime that another manifest already installs
+// as a package keeps that package, and its version is dropped with a TODO.
+func (imp *importer) addRuntime(pkg Package) {
+	name := strings.ToLower(pkg.Name)
+	recipe := imp.byName[name]
+	if recipe != "" {
+		name = recipe
+	}
+
+	if imp.tools[name] != nil || imp.usesRecipe(name) {
+		note := fmt.Sprintf("%s: %s", pkg.Source, pkg.Name)
+		if pkg.Version != "" {
+			note += " " + pkg.Version
+		}
+		imp.notes = append(imp.notes, fmt.Sprintf("%s is already installed as the package %s; its version was dropped", note, name))
+		imp.result.TODO++
+		return
+	}
+
+	version := pkg.Version
+	if version == "" {
+		version = "latest"
+	}
+	if recipe != "" {
+		imp.entries = append(imp.entries, &entry{recipe: recipe, version: version, manager: domain.PackageManagerMise})
+		imp.result.Recipes++
+		return
+	}
+
+	e := &entry{tool: &config.Tool{Name: name, Version: version, Manager: domain.PackageManagerMise}}
+	imp.tools[name] = e
+	imp.entries = append(imp.entries, e)
+}
+
+// usesRecipe reports whether the config already uses a recipe
+func (imp *importer) usesRecipe(recipe string) bool {
+	for _, e := range imp.entries {
+		if e.recipe == recipe {
+			return true
+		}
+	}
+	return false
+}
+
+// addTap adds a Brewfile tap as a repository of the tools whose formula
+// comes from it. Formulae named without their tap cannot be told apart,
+// so a tap no formula names is left as a TODO.
+func (imp *importer) addTap(pkg Package) {
+	prefix := strings.ToLower(pkg.Name) + "/"
+	found := false
+	for _, e := range imp.entries {
+		if e.tool == nil || e.tool.MacOS == nil || !strings.HasPrefix(strings.ToLower(e.tool.MacOS.Brew), prefix) {
+			continue
+		}
+		e.tool.MacOS.Repositories = append(e.tool.MacOS.Repositories, config.Repository{Name: pkg.Name, URL: pkg.URL})
+		found = true
+	}
+
+	if !found {
+		imp.notes = append(imp.notes, fmt.Sprintf("%s: no formula names tap %s; add it to the repositories of the tools installed from it", pkg.Source, pkg.Name))
+		imp.result.TODO++
+	}
+}
+
+func (imp *importer) addRecipe(recipe, version string) {
+	for _, e := range imp.entries {
+		if e.recipe == recipe {
+			if e.version == "" {
+				e.version = version
+			}
+			return
+		}
+	}
+	imp.entries = append(imp.entries, &entry{recipe: recipe, version: version})
+	imp.result.Recipes++
+}
+
+// addPackage adds a package without a recipe to the tool of the same name,
+// so a package found in several manifests becomes one tool
+func (imp *importer) addPackage(pkg Package) {
+	name := toolName(pkg)
+	e := imp.tools[name]
+	if imp.recipes[name] || e != nil && packageOf(e.tool, pkg.Manager) != "" {
+		// A recipe or another package of this manager has the short name
+		name = strings.ToLower(strings.NewReplacer("/", "-", ".", "-").Replace(pkg.Name))
+		e = imp.tools[name]
+	}
+
+	if e == nil {
+		version := pkg.Version
+		if version == "" {
+			version = "latest"
+		}
+		e = &entry{tool: &config.Tool{Name: name, Version: version}}
+		imp.tools[name] = e
+		imp.entries = append(imp.entries, e)
+		imp.result.TODO++
+	}
+
+	tool := e.tool
+	setVersion(tool, pkg.Version)
+	switch pkg.Manager {
+	case "brew":
+		if tool.MacOS == nil {
+			tool.MacOS = &config.PlatformConfig{}
+		}
+		tool.MacOS.Brew = pkg.Name
+	case "winget", "choco":
+		if tool.Windows == nil {
+			tool.Windows = &config.PlatformConfig{PackageNames: map[string]string{}}
+		}
+		tool.Windows.PackageNames[pkg.Manager] = pkg.Name
+	default:
+		if tool.Linux == nil {
+			tool.Linux = &config.PlatformConfig{PackageNames: map[string]string{}}
+		}
+		tool.Linux.PackageNames[pkg.Manager] = pkg.Name
+	}
+
+	var platforms []string
+	for _, section := range []struct {
+		name string
+		cfg  *config.PlatformConfig
+	}{{"linux", tool.Linux}, {"macos", tool.MacOS}, {"windows", tool.Windows}} {
+		if section.cfg == nil {
+			platforms = append(platforms, section.name)
+		}
+	}
+	e.todo = fmt.Sprintf("TODO: no catalog recipe for %s; add %s or remove it", name, strings.Join(platforms, " and "))
+	if len(platforms) == 0 {
+		e.todo = fmt.Sprintf("TODO: no catalog recipe for %s; check the package names", name)
+	}
+}
+
+// setVersion pins a tool's version unless it already is
+func setVersion(tool *config.Tool, version string) {
+	if version != "" && tool.Version == "latest" {
+		tool.Version = version
+	}
+}
+
+// packageOf returns the package name a tool has for a manager
+func packageOf(tool *config.Tool, manager string) string {
+	switch {
+	case manager == "brew":
+		if tool.MacOS != nil {
+			return tool.MacOS.Brew
+		}
+	case manager == "winget" || manager == "choco":
+		if tool.Windows != nil {
+			return tool.Windows.PackageNames[manager]
+		}
+	case tool.Linux != nil:
+		return tool.Linux.PackageNames[manager]
+	}
+	return ""
+}
+
+// toolName derives a short tool name from a package: the formula of a
+// tapped brew formula, the last part of a winget ID
+func toolName(pkg Package) string {
+	name := pkg.Name
+	switch pkg.Manager {
+	case "brew":
+		name = name[strings.LastIndex(name, "/")+1:]
+	case "winget":
+		name = name[strings.LastIndex(name, ".")+1:]
+	case "choco":
+		name = strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(name), ".install"), ".portable")
+	}
+	return strings.ToLower(name)
+}
+
+// document builds the config with TODO comments
+func (imp *importer) document(profile string) (*yaml.Node, error) {
+	tools := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, e := range imp.entries {
+		var node yaml.Node
+		if e.tool != nil {
+			if err := node.Encode(e.tool); err != nil {
+				return nil, err
+			}
+			node.HeadComment = e.todo
+		} else {
+			node = *mapping("use", e.recipe)
+			if e.version != "" {
+				node.Content = append(node.Content, mapping("version", e.version).Content...)
+			}
+			if e.manager != "" {
+				node.Content = append(node.Content, mapping("manager", e.manager).Content...)
+			}
+		}
+		tools.Content = append(tools.Content, &node)
+	}
+
+	settings := mapping("auto_update_path", "true", "verify_installations", "true")
+	for i := 1; i < len(settings.Content); i += 2 {
+		settings.Content[i].Tag = "!!bool"
+	}
+
+	doc := mapping("profile", profile)
+	doc.Content = append(doc.Content, scalar("settings"), settings, scalar("tools"), tools)
+
+	var head []string
+	for _, note := range imp.notes {
+		head = append(head, "TODO: "+note)
+	}
+	doc.Content[0].HeadComment = strings.Join(head, "\n")
+	return doc, nil
+}
+
+// Write renders the imported config as YAML with a schema comment for
+// editors, and checks that it loads back to a valid config
+func (r *Result) Write(w io.Writer, c *catalog.Catalog) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# yaml-language-server: $schema=%s\n", schema.ID)
+	fmt.Fprintf(&buf, "# Imported by stackup import from %s\n\n", strings.Join(r.sources, ", "))
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(r.doc); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	// A config of TODO comments alone has no tools to validate
+	if r.Tools > 0 {
+		cfg, err := config.Load(config.LoadOptions{Stdin: bytes.NewReader(buf.Bytes()), Recipes: c}, "-")
+		if err != nil {
+			return fmt.Errorf("imported config does not load: %w", err)
+		}
+		if err := config.Validate(cfg); err != nil {
+			return fmt.Errorf("imported config is invalid: %w", err)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// mapping builds a mapping of string keys and values
+func mapping(pairs ...string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		node.Content = append(node.Content, scalar(pairs[i]), scalar(pairs[i+1]))
+	}
+	return node
+}
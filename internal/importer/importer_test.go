@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/catalog"
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+func TestImport(t *testing.T) {
+	pkgs := []Package{
+		{Manager: "brew", Name: "git", Source: "Brewfile:1"},
+		{Manager: "brew", Name: "hashicorp/tap/terraform", Source: "Brewfile:2"},
+		{Manager: "brew", Name: "visual-studio-code", Source: "Brewfile:3"},
+		{Manager: "winget", Name: "git.git", Source: "winget.json"},
+		{Manager: "winget", Name: "Hashicorp.Terraform", Version: "1.5.0", Source: "winget.json"},
+		{Manager: "winget", Name: "Contoso.Git", Source: "winget.json"},
+		{Name: "nodejs", Version: "20.11.0", Source: ".tool-versions:1"},
+		{Name: "golang", Version: "1.22.0", Source: ".tool-versions:2"},
+		{Name: "zig", Version: "0.11.0", Source: ".tool-versions:3"},
+		{Name: "git", Version: "2.45.0", Source: ".tool-versions:4"},
+		{Manager: "tap", Name: "hashicorp/tap", Source: "Brewfile:4"},
+		{Manager: "tap", Name: "homebrew/cask-fonts", Source: "Brewfile:5"},
+	}
+
+	c := catalog.New()
+	result, err := Import(c, "laptop", []string{"Brewfile", "winget.json", ".tool-versions"}, pkgs, []string{`Brewfile:6: mas entries are not supported: mas "Xcode", id: 497799835`})
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if result.Tools != 7 || result.Recipes != 4 || result.TODO != 4 {
+		t.Errorf("Tools, Recipes, TODO = %d, %d, %d, want 7, 4, 4", result.Tools, result.Recipes, result.TODO)
+	}
+
+	var buf bytes.Buffer
+	if err := result.Write(&buf, c); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# Imported by stackup import from Brewfile, winget.json, .tool-versions\n",
+		"# TODO: .tool-versions:4: git 2.45.0 is already installed as the package git; its version was dropped\n",
+		"# TODO: Brewfile:5: no formula names tap homebrew/cask-fonts; add it to the repositories of the tools installed from it\n",
+		"  # TODO: no catalog recipe for terraform; add linux or remove it\n",
+		"  - use: node\n    version: 20.11.0\n    manager: mise\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output is missing %q:\n%s", want, out)
+		}
+	}
+
+	cfg, err := config.Load(config.LoadOptions{Stdin: strings.NewReader(out), Recipes: c}, "-")
+	if err != nil {
+		t.Fatalf("Imported config does not load: %v", err)
+	}
+
+	tools := make(map[string]config.Tool)
+	var names []string
+	for _, tool := range cfg.Tools {
+		tools[tool.Name] = tool
+		names = append(names, tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "git,terraform,vscode,contoso-git,node,go,zig" {
+		t.Errorf("Tools = %s", got)
+	}
+
+	terraform := tools["terraform"]
+	if terraform.Version != "1.5.0" || terraform.MacOS.Brew != "hashicorp/tap/terraform" || terraform.Windows.PackageNames["winget"] != "Hashicorp.Terraform" || terraform.Linux != nil {
+		t.Errorf("terraform = %+v, want one tool merged from both manifests", terraform)
+	}
+	if git := tools["git"]; git.Linux == nil || git.VerifyCommand == "" {
+		t.Errorf("git = %+v, want the catalog recipe", git)
+	}
+	if repos := terraform.MacOS.Repositories; len(repos) != 1 || repos[0].Name != "hashicorp/tap" {
+		t.Errorf("terraform repositories = %+v, want the hashicorp/tap tap", repos)
+	}
+	if tools["go"].Version != "1.22.0" || tools["go"].Manager != "mise" {
+		t.Errorf("go = %s %s, want 1.22.0 with mise", tools["go"].Version, tools["go"].Manager)
+	}
+	if zig := tools["zig"]; zig.Version != "0.11.0" || zig.Manager != "mise" {
+		t.Errorf("zig = %s %s, want 0.11.0 with mise", zig.Version, zig.Manager)
+	}
+}
+
+func TestImportOnlyTODO(t *testing.T) {
+	c := catalog.New()
+	result, err := Import(c, "dev", []string{"Brewfile"}, []Package{{Manager: "tap", Name: "homebrew/cask-fonts", Source: "Brewfile:1"}}, nil)
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := result.Write(&buf, c); err != nil {
+		t.Fatalf("Write() should not validate a config without tools: %v", err)
+	}
+	if result.Tools != 0 || result.TODO != 1 {
+		t.Errorf("Tools, TODO = %d, %d, want 0, 1", result.Tools, result.TODO)
+	}
+}
@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Kind is a manifest format that can be imported
+type Kind string
+
+// Supported manifest formats
+const (
+	KindBrewfile     Kind = "brewfile"      // Homebrew bundle Brewfile
+	KindWinget       Kind = "winget"        // JSON written by winget export
+	KindChoco        Kind = "choco"         // Chocolatey packages.config
+	KindApt          Kind = "apt"           // apt-mark showmanual output
+	KindToolVersions Kind = "tool-versions" // asdf and mise .tool-versions
+)
+
+// Kinds lists every supported manifest format
+var Kinds = []Kind{KindBrewfile, KindWinget, KindChoco, KindApt, KindToolVersions}
+
+// ParseKind checks a manifest format name
+func ParseKind(name string) (Kind, error) {
+	for _, kind := range Kinds {
+		if string(kind) == name {
+			return kind, nil
+		}
+	}
+	names := make([]string, len(Kinds))
+	for i, kind := range Kinds {
+		names[i] = string(kind)
+	}
+	return "", fmt.Errorf("unknown import format %q: expected %s", name, strings.Join(names, ", "))
+}
+
+// DetectKind picks a manifest format from a file name. apt package lists
+// have no conventional name and must be named explicitly.
+func DetectKind(path string) (Kind, error) {
+	base := strings.ToLower(filepath.Base(path))
+	switch {
+	case base == "brewfile" || strings.HasSuffix(base, ".brewfile"):
+		return KindBrewfile, nil
+	case base == ".tool-versions":
+		return KindToolVersions, nil
+	case base == "packages.config" || filepath.Ext(base) == ".config":
+		return KindChoco, nil
+	case filepath.Ext(base) == ".json":
+		return KindWinget, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of %s; pass --from", path)
+}
+
+// Package is an entry read from a manifest
+type Package struct {
+	Manager string // brew, winget, choco or apt; tap for brew taps, empty for runtimes
+	Name    string
+	Version string
+	URL     string // remote of a tap
+	Source  string // file and line, for notes
+}
+
+// tapManager is the manager of Brewfile taps, which are repositories
+// rather than packages
+const tapManager = "tap"
+
+// Parse reads the packages of a manifest. Lines that cannot be imported
+// are returned as notes.
+func Parse(kind Kind, data []byte, file string) (pkgs []Package, notes []string, err error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	switch kind {
+	case KindBrewfile:
+		pkgs, notes = parseBrewfile(data, file)
+	case KindWinget:
+		pkgs, err = parseWinget(data, file)
+	case KindChoco:
+		pkgs, err = parseChoco(data, file)
+	case KindApt:
+		pkgs = parseApt(data, file)
+	case KindToolVersions:
+		pkgs = parseToolVersions(data, file)
+	default:
+		return nil, nil, fmt.Errorf("unknown import format %q", kind)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return pkgs, notes, nil
+}
+
+// brewEntry matches a Brewfile entry such as brew "git", args: ["HEAD"],
+// and the remote of a tap such as tap "user/repo", "https://..."
+var brewEntry = regexp.MustCompile(`^(\w+)\s+["']([^"']+)["'](?:\s*,\s*["']([^"']+)["'])?`)
+
+func parseBrewfile(data []byte, file string) ([]Package, []string) {
+	var pkgs []Package
+	var notes []string
+
+	eachLine(data, func(line string, n int) {
+		source := fmt.Sprintf("%s:%d", file, n)
+		match := brewEntry.FindStringSubmatch(line)
+		if match == nil {
+			notes = append(notes, fmt.Sprintf("%s: not imported: %s", source, line))
+			return
+		}
+
+		switch match[1] {
+		case "brew", "cask":
+			pkgs = append(pkgs, Package{Manager: "brew", Name: match[2], Source: source})
+		case "tap":
+			pkgs = append(pkgs, Package{Manager: tapManager, Name: match[2], URL: match[3], Source: source})
+		default:
+			notes = append(notes, fmt.Sprintf("%s: %s entries are not supported: %s", source, match[1], line))
+		}
+	})
+	return pkgs, notes
+}
+
+// wingetExport is the part of a winget export file that lists packages
+type wingetExport struct {
+	Sources []struct {
+		Packages []struct {
+			PackageIdentifier string `json:"PackageIdentifier"`
+			Version           string `json:"Version"`
+		} `json:"Packages"`
+	} `json:"Sources"`
+}
+
+func parseWinget(data []byte, file string) ([]Package, error) {
+	var export wingetExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("not a winget export: %w", err)
+	}
+
+	var pkgs []Package
+	for _, source := range export.Sources {
+		for _, pkg := range source.Packages {
+			if pkg.PackageIdentifier == "" {
+				continue
+			}
+			pkgs = append(pkgs, Package{Manager: "winget", Name: pkg.PackageIdentifier, Version: pkg.Version, Source: file})
+		}
+	}
+	return pkgs, nil
+}
+
+// chocoPackages is a Chocolatey packages.config
+type chocoPackages struct {
+	Packages []struct {
+		ID      string `xml:"id,attr"`
+		Version string `xml:"version,attr"`
+	} `xml:"package"`
+}
+
+func parseChoco(data []byte, file string) ([]Package, error) {
+	var config chocoPackages
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("not a packages.config: %w", err)
+	}
+
+	var pkgs []Package
+	for _, pkg := range config.Packages {
+		if pkg.ID == "" {
+			continue
+		}
+		pkgs = append(pkgs, Package{Manager: "choco", Name: pkg.ID, Version: pkg.Version, Source: file})
+	}
+	return pkgs, nil
+}
+
+func parseApt(data []byte, file string) []Package {
+	var pkgs []Package
+	eachLine(data, func(line string, n int) {
+		// Multiarch packages are listed as name:arch
+		name, _, _ := strings.Cut(strings.Fields(line)[0], ":")
+		pkgs = append(pkgs, Package{Manager: "apt", Name: name, Source: fmt.Sprintf("%s:%d", file, n)})
+	})
+	return pkgs
+}
+
+func parseToolVersions(data []byte, file string) []Package {
+	var pkgs []Package
+	eachLine(data, func(line string, n int) {
+		// Several versions may be listed; the first is the default
+		fields := strings.Fields(line)
+		pkg := Package{Name: fields[0], Source: fmt.Sprintf("%s:%d", file, n)}
+		if len(fields) > 1 {
+			pkg.Version = fields[1]
+		}
+		pkgs = append(pkgs, pkg)
+	})
+	return pkgs
+}
+
+// eachLine calls fn for every line that is neither blank nor a comment,
+// with trailing comments removed
+func eachLine(data []byte, fn func(line string, n int)) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			fn(line, n)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      Kind
+		content   string
+		want      []Package
+		wantNotes []string
+	}{
+		{
+			name:    "brewfile",
+			kind:    KindBrewfile,
+			content: "# dev tools\ntap \"hashicorp/tap\"\nbrew \"git\"\nbrew 'hashicorp/tap/terraform', args: [\"HEAD\"]\ncask \"iterm2\" # terminal\nmas \"Xcode\", id: 497799835\ntap \"acme/tools\", \"https://git.example.com/acme/homebrew-tools\"\n",
+			want: []Package{
+				{Manager: "tap", Name: "hashicorp/tap", Source: "manifest:2"},
+				{Manager: "brew", Name: "git", Source: "manifest:3"},
+				{Manager: "brew", Name: "hashicorp/tap/terraform", Source: "manifest:4"},
+				{Manager: "brew", Name: "iterm2", Source: "manifest:5"},
+				{Manager: "tap", Name: "acme/tools", URL: "https://git.example.com/acme/homebrew-tools", Source: "manifest:7"},
+			},
+			wantNotes: []string{
+				`manifest:6: mas entries are not supported: mas "Xcode", id: 497799835`,
+			},
+		},
+		{
+			name:    "winget export",
+			kind:    KindWinget,
+			content: "\xef\xbb\xbf" + `{"Sources": [{"Packages": [{"PackageIdentifier": "Git.Git"}, {"PackageIdentifier": "Hashicorp.Terraform", "Version": "1.5.0"}]}]}`,
+			want: []Package{
+				{Manager: "winget", Name: "Git.Git", Source: "manifest"},
+				{Manager: "winget", Name: "Hashicorp.Terraform", Version: "1.5.0", Source: "manifest"},
+			},
+		},
+		{
+			name:    "choco packages.config",
+			kind:    KindChoco,
+			content: `<?xml version="1.0" encoding="utf-8"?>` + "\n<packages>\n  <package id=\"git\" version=\"2.45.0\" />\n  <package id=\"7zip.install\" />\n</packages>\n",
+			want: []Package{
+				{Manager: "choco", Name: "git", Version: "2.45.0", Source: "manifest"},
+				{Manager: "choco", Name: "7zip.install", Source: "manifest"},
+			},
+		},
+		{
+			name:    "apt-mark showmanual",
+			kind:    KindApt,
+			content: "git\n\nlibssl3:amd64\n",
+			want: []Package{
+				{Manager: "apt", Name: "git", Source: "manifest:1"},
+				{Manager: "apt", Name: "libssl3", Source: "manifest:3"},
+			},
+		},
+		{
+			name:    "tool-versions",
+			kind:    KindToolVersions,
+			content: "nodejs 20.11.0 18.19.0 # default first\nterraform\n",
+			want: []Package{
+				{Name: "nodejs", Version: "20.11.0", Source: "manifest:1"},
+				{Name: "terraform", Source: "manifest:2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkgs, notes, err := Parse(tt.kind, []byte(tt.content), "manifest")
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+			if !reflect.DeepEqual(pkgs, tt.want) {
+				t.Errorf("Parse() packages = %+v, want %+v", pkgs, tt.want)
+			}
+			if !reflect.DeepEqual(notes, tt.wantNotes) {
+				t.Errorf("Parse() notes = %q, want %q", notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, kind := range []Kind{KindWinget, KindChoco} {
+		if _, _, err := Parse(kind, []byte("git\n"), "list"); err == nil || !strings.HasPrefix(err.Error(), "list: ") {
+			t.Errorf("Parse(%s) error = %v, want an error naming the file", kind, err)
+		}
+	}
+}
+
+func TestDetectKind(t *testing.T) {
+	tests := map[string]Kind{
+		"Brewfile":               KindBrewfile,
+		"dotfiles/work.Brewfile": KindBrewfile,
+		"winget-export.json":     KindWinget,
+		"packages.config":        KindChoco,
+		"home/.tool-versions":    KindToolVersions,
+	}
+	for path, want := range tests {
+		if got, err := DetectKind(path); err != nil || got != want {
+			t.Errorf("DetectKind(%q) = %q, %v, want %q", path, got, err, want)
+		}
+	}
+
+	if _, err := DetectKind("packages.txt"); err == nil {
+		t.Error("DetectKind() should not guess apt lists")
+	}
+}
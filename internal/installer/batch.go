@@ -0,0 +1,63 @@
+package installer
+
+import (
+	"fmt"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+// batch is a run of tools installed in one package manager transaction
+type batch struct {
+	manager string
+	tools   []*config.Tool
+	configs []*config.PlatformConfig
+}
+
+// batch collects the tools at the start of tools that only need a package
+// from the same manager, which can install them all at once. Tools with
+// their own commands run them in between, and installed tools are skipped,
+// so both end a batch.
+func (i *Installer) batch(tools []*config.Tool) batch {
+	var b batch
+	for _, tool := range tools {
+		if i.installedTools[tool.Name] || i.failedTools[tool.Name] != nil || !i.conditionMet(tool) ||
+			len(tool.PreInstall) > 0 || len(tool.CustomInstall) > 0 || len(tool.PostInstall) > 0 {
+			break
+		}
+		platformConfig := i.platformConfig(tool)
+		if platformConfig == nil || len(platformConfig.CustomCommands) > 0 {
+			break
+		}
+		manager := i.executor.BatchManager(tool, platformConfig)
+		if manager == "" || b.manager != "" && manager != b.manager {
+			break
+		}
+		// Installed tools are skipped on their own
+		if i.packageInstalled(tool) {
+			break
+		}
+
+		b.manager = manager
+		b.tools = append(b.tools, tool)
+		b.configs = append(b.configs, platformConfig)
+	}
+	return b
+}
+
+// installBatch installs a batch and returns the result of each tool. When
+// the transaction fails, the tools are installed one at a time to find
+// which failed, falling back to their installers like single tools do.
+func (i *Installer) installBatch(b batch) []error {
+	errs := make([]error, len(b.tools))
+	err := i.executor.InstallBatchViaPackageManager(b.tools, b.configs)
+	if err == nil {
+		return errs
+	}
+
+	i.console.PrintWarning("", fmt.Sprintf("Installing %d packages with %s failed (%v), installing them one at a time", len(b.tools), b.manager, err))
+	for idx, tool := range b.tools {
+		i.console.PrintInfo(fmt.Sprintf("Installing %s...", tool.GetDisplayName()))
+		errs[idx] = i.installTool(tool)
+	}
+	return errs
+}
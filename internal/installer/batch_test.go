@@ -0,0 +1,131 @@
+package installer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/backend"
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/domain"
+	"github.com/araldhafeeri/stackup/internal/ui"
+)
+
+func TestBatch(t *testing.T) {
+	linux := &config.PlatformConfig{}
+	tools := []*config.Tool{
+		{Name: "git", Linux: linux},
+		{Name: "curl", Linux: linux},
+		{Name: "docker", Linux: &config.PlatformConfig{PackageNames: map[string]string{"apt": "docker.io"}}},
+		{Name: "hook", Linux: linux, PostInstall: []config.Command{{Command: "echo"}}},
+		{Name: "jq", Linux: linux},
+		{Name: "ripgrep", Manager: "cargo"},
+		{Name: "make", Linux: linux},
+		{Name: "wget", Linux: linux},
+		{Name: "arm-only", When: `arch == "arm64"`, Linux: linux},
+		{Name: "vim", Linux: linux},
+		{Name: "zip", Linux: linux},
+	}
+
+	installer := New(&config.Config{}, &domain.System{OS: "linux", Arch: "amd64", PackageManager: "apt"}, ui.NewConsole())
+	installer.installedTools["zip"] = true
+	installer.executor.SetRunner(&fakeRunner{outputs: map[string]string{
+		"dpkg-query -W -f=${Status}\t${Version} wget": "install ok installed\t1.21.4-1",
+	}})
+
+	var batches []string
+	for idx := 0; idx < len(tools); {
+		b := installer.batch(tools[idx:])
+		if len(b.tools) == 0 {
+			batches = append(batches, tools[idx].Name)
+			idx++
+			continue
+		}
+		var names []string
+		for _, tool := range b.tools {
+			names = append(names, tool.Name)
+		}
+		batches = append(batches, b.manager+":"+strings.Join(names, "+"))
+		idx += len(b.tools)
+	}
+
+	// wget is installed already, so it is skipped on its own
+	want := "apt:git+curl+docker, hook, apt:jq, ripgrep, apt:make, wget, arm-only, apt:vim, zip"
+	if got := strings.Join(batches, ", "); got != want {
+		t.Errorf("batches = %s\nwant %s", got, want)
+	}
+	if !installer.installedTools["wget"] {
+		t.Error("wget should be marked installed")
+	}
+}
+
+// fakeRunner records package manager commands without the sudo prefix,
+// failing those that mention a broken package. Queries are recorded apart
+// and print the configured output, or find nothing installed.
+type fakeRunner struct {
+	broken  string
+	outputs map[string]string
+	calls   []string
+	queries []string
+}
+
+func (r *fakeRunner) Run(name string, args ...string) error {
+	line := strings.TrimPrefix(strings.Join(append([]string{name}, args...), " "), "sudo ")
+	r.calls = append(r.calls, line)
+	if r.broken != "" && strings.Contains(line, r.broken) {
+		return &backend.ExitError{Command: name, Code: 100}
+	}
+	return nil
+}
+
+func (r *fakeRunner) Output(name string, args ...string) ([]byte, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	r.queries = append(r.queries, line)
+	if out, ok := r.outputs[line]; ok {
+		return []byte(out), nil
+	}
+	return nil, &backend.ExitError{Command: name, Code: 1}
+}
+
+func (r *fakeRunner) LookPath(string) bool { return true }
+
+func TestInstallBatchFallback(t *testing.T) {
+	linux := &config.PlatformConfig{}
+	tools := []*config.Tool{
+		{Name: "git", Linux: linux},
+		{Name: "curl", Linux: linux},
+		{Name: "jq", Linux: linux},
+	}
+
+	installer := New(&config.Config{}, &domain.System{OS: "linux", PackageManager: "apt"}, ui.NewConsole())
+	runner := &fakeRunner{broken: "curl"}
+	installer.executor.SetRunner(runner)
+
+	b := installer.batch(tools)
+	if len(b.tools) != 3 {
+		t.Fatalf("batch = %v, want all three tools", b.tools)
+	}
+	errs := installer.installBatch(b)
+
+	// The transaction fails, so each package is installed on its own
+	want := "apt-get install git curl jq; apt-get install git; apt-get install curl; apt-get install jq"
+	if got := strings.Join(runner.calls, "; "); got != want {
+		t.Errorf("ran %q\nwant %q", got, want)
+	}
+
+	// Each tool is checked once, though batching and installing both ask
+	wantQueries := "dpkg-query -W -f=${Status}\t${Version} git; dpkg-query -W -f=${Status}\t${Version} curl; dpkg-query -W -f=${Status}\t${Version} jq"
+	if got := strings.Join(runner.queries, "; "); got != wantQueries {
+		t.Errorf("queried %q\nwant %q", got, wantQueries)
+	}
+
+	if len(errs) != 3 {
+		t.Fatalf("installBatch() returned %d results, want 3", len(errs))
+	}
+	if errs[0] != nil || errs[2] != nil {
+		t.Errorf("git and jq errors = %v, %v; want nil", errs[0], errs[2])
+	}
+	if !errors.Is(errs[1], domain.ErrNoInstallMethod) || !strings.Contains(errs[1].Error(), "exited with status 100") {
+		t.Errorf("curl error = %v, want its apt-get failure", errs[1])
+	}
+}
@@ -2,6 +2,8 @@ package installer
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/araldhafeeri/stackup/internal/config"
 	"github.com/araldhafeeri/stackup/internal/domain"
@@ -9,18 +11,33 @@ import (
 
 // resolveDependencies creates an ordered list of tools respecting dependencies
 func (i *Installer) resolveDependencies() ([]*config.Tool, error) {
+	return Order(i.config.Tools, i.system)
+}
+
+// Order lists tools in install order: each tool after its dependencies,
+// otherwise in config order. Tools installed by a language package manager
+// on sys also come after the tool providing its runtime, when the config
+// has one.
+func Order(tools []config.Tool, sys *domain.System) ([]*config.Tool, error) {
 	var result []*config.Tool
 	visited := make(map[string]bool)
+	var path []string // tools being resolved, outermost first
 
 	var resolve func(*config.Tool) error
 	resolve = func(tool *config.Tool) error {
 		if visited[tool.Name] {
 			return nil
 		}
+		if idx := slices.Index(path, tool.Name); idx >= 0 {
+			return fmt.Errorf("%w: %s", domain.ErrDependencyCycle,
+				strings.Join(append(slices.Clone(path[idx:]), tool.Name), " -> "))
+		}
+		path = append(path, tool.Name)
+		defer func() { path = path[:len(path)-1] }()
 
 		// Install dependencies first
 		for _, depName := range tool.Dependencies {
-			dep := i.findTool(depName)
+			dep := findTool(tools, depName)
 			if dep == nil {
 				return fmt.Errorf("%w: '%s' for tool '%s'",
 					domain.ErrDependencyNotFound, depName, tool.Name)
@@ -29,14 +46,21 @@ func (i *Installer) resolveDependencies() ([]*config.Tool, error) {
 				return err
 			}
 		}
+		for _, runtime := range runtimes(tool, sys) {
+			if dep := findTool(tools, runtime); dep != nil {
+				if err := resolve(dep); err != nil {
+					return err
+				}
+			}
+		}
 
 		visited[tool.Name] = true
 		result = append(result, tool)
 		return nil
 	}
 
-	for idx := range i.config.Tools {
-		if err := resolve(&i.config.Tools[idx]); err != nil {
+	for idx := range tools {
+		if err := resolve(&tools[idx]); err != nil {
 			return nil, err
 		}
 	}
@@ -46,10 +70,36 @@ func (i *Installer) resolveDependencies() ([]*config.Tool, error) {
 
 // findTool locates a tool by name
 func (i *Installer) findTool(name string) *config.Tool {
-	for idx := range i.config.Tools {
-		if i.config.Tools[idx].Name == name {
-			return &i.config.Tools[idx]
+	return findTool(i.config.Tools, name)
+}
+
+func findTool(tools []config.Tool, name string) *config.Tool {
+	for idx := range tools {
+		if tools[idx].Name == name {
+			return &tools[idx]
 		}
 	}
 	return nil
 }
+
+// runtimes returns the tools providing the language package managers a tool
+// may be installed with on sys: its manager, or any in the package names of
+// the section used there
+func runtimes(tool *config.Tool, sys *domain.System) []string {
+	var packageNames map[string]string
+	if platform := tool.PlatformConfigFor(sys.OS, sys.DistroIDs()...); platform != nil {
+		packageNames = platform.PackageNames
+	}
+
+	var names []string
+	for _, manager := range domain.PackageManagers {
+		runtime, ok := domain.LanguageRuntimes[manager]
+		if !ok || runtime == tool.Name {
+			continue
+		}
+		if _, mapped := packageNames[manager]; tool.Manager == manager || mapped {
+			names = append(names, runtime)
+		}
+	}
+	return names
+}
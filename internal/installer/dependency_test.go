@@ -1,6 +1,8 @@
 package installer
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/araldhafeeri/stackup/internal/config"
@@ -135,6 +137,69 @@ func TestResolveDependencies(t *testing.T) {
 	}
 }
 
+func TestRuntimeDependencies(t *testing.T) {
+	tools := []config.Tool{
+		{Name: "ripgrep", Version: "latest", Manager: "cargo"},
+		{Name: "black", Version: "latest", Linux: &config.PlatformConfig{
+			Distros: map[string]*config.PlatformConfig{"arch": {PackageNames: map[string]string{"pipx": "black"}}},
+		}},
+		{Name: "prettier", Version: "latest", Manager: "npm"},
+		{Name: "rust", Version: "stable"},
+		{Name: "pipx", Version: "latest"},
+		// Only the section used on this system counts
+		{Name: "rubocop", Version: "latest", MacOS: &config.PlatformConfig{PackageNames: map[string]string{"gem": "rubocop"}}},
+		{Name: "ruby", Version: "latest"},
+	}
+
+	result, err := Order(tools, &domain.System{OS: "linux", PackageManager: "pacman", Distro: "arch"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var order []string
+	for _, tool := range result {
+		order = append(order, tool.Name)
+	}
+	// node is not in the config, so prettier has no runtime to wait for
+	if got, want := strings.Join(order, ","), "rust,ripgrep,pipx,black,prettier,rubocop,ruby"; got != want {
+		t.Errorf("order = %s, want %s", got, want)
+	}
+}
+
+func TestDependencyCycles(t *testing.T) {
+	tests := []struct {
+		name  string
+		tools []config.Tool
+		want  string
+	}{
+		{
+			name: "Dependencies",
+			tools: []config.Tool{
+				{Name: "tool-a", Version: "1.0", Dependencies: []string{"tool-b"}},
+				{Name: "tool-b", Version: "1.0", Dependencies: []string{"tool-a"}},
+			},
+			want: "dependency cycle: tool-a -> tool-b -> tool-a",
+		},
+		{
+			name: "Runtimes",
+			tools: []config.Tool{
+				{Name: "mise", Version: "latest", Linux: &config.PlatformConfig{PackageNames: map[string]string{"cargo": "mise"}}},
+				{Name: "rust", Version: "stable", Linux: &config.PlatformConfig{PackageNames: map[string]string{"mise": "rust"}}},
+			},
+			want: "dependency cycle: mise -> rust -> mise",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Order(tt.tools, &domain.System{OS: "linux", PackageManager: "apt"})
+			if !errors.Is(err, domain.ErrDependencyCycle) || err.Error() != tt.want {
+				t.Errorf("Order() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
 func TestFindTool(t *testing.T) {
 	cfg := &config.Config{
 		Tools: []config.Tool{
@@ -4,9 +4,11 @@ package installer
 import (
 	"fmt"
 
+	"github.com/araldhafeeri/stackup/internal/condition"
 	"github.com/araldhafeeri/stackup/internal/config"
 	"github.com/araldhafeeri/stackup/internal/domain"
 	"github.com/araldhafeeri/stackup/internal/executor"
+	"github.com/araldhafeeri/stackup/internal/secret"
 	"github.com/araldhafeeri/stackup/internal/ui"
 	"github.com/araldhafeeri/stackup/pkg/version"
 )
@@ -17,23 +19,45 @@ type Installer struct {
 	system         *domain.System
 	console        *ui.Console
 	executor       *executor.Executor
+	secrets        *secret.Store
+	facts          *condition.Facts
 	installedTools map[string]bool
+	failedTools    map[string]error // tools that failed before installing, with why
+	missingTools   map[string]bool  // tools whose package manager found them not installed
 }
 
-// New creates a new Installer instance
+// New creates a new Installer instance. The system's package managers are
+// reordered by the config's manager_priority setting.
 func New(cfg *config.Config, sys *domain.System, console *ui.Console) *Installer {
+	sys.PreferPackageManagers(cfg.Settings.ManagerPriority)
+	secrets := secret.NewStore(cfg.Secrets, secret.NewRedactor())
 	return &Installer{
 		config:         cfg,
 		system:         sys,
 		console:        console,
-		executor:       executor.New(sys),
+		executor:       executor.New(sys, secrets, !cfg.Settings.NonInteractive),
+		secrets:        secrets,
+		facts:          condition.NewFacts(sys),
 		installedTools: make(map[string]bool),
+		failedTools:    make(map[string]error),
+		missingTools:   make(map[string]bool),
 	}
 }
 
+// Redactor returns the redactor that masks the config's secret values once
+// they are resolved
+func (i *Installer) Redactor() *secret.Redactor {
+	return i.secrets.Redactor()
+}
+
 // Run executes the installation process
 func (i *Installer) Run() error {
-	i.console.PrintHeader(version.Version, i.system, i.config.Profile)
+	i.console.PrintHeader(version.Version, i.system, i.config.Profile, i.config.Origins())
+
+	// Resolve secrets first, so a missing one fails before anything changes
+	if err := i.secrets.ResolveAll(i.config.SecretReferences()); err != nil {
+		return fmt.Errorf("failed to resolve secrets: %w", err)
+	}
 
 	// Pre-flight checks
 	if err := i.runPreflightChecks(); err != nil {
@@ -46,33 +70,37 @@ func (i *Installer) Run() error {
 		return fmt.Errorf("failed to resolve dependencies: %w", err)
 	}
 
+	// Add third-party repositories, so refreshed indexes include them
+	i.addRepositories(toolsToInstall)
+
+	if i.config.Settings.ShouldRefreshIndexes() {
+		i.refreshIndexes(toolsToInstall)
+	}
+
 	needsReboot := false
 
 	// Install each tool
-	for idx, tool := range toolsToInstall {
-		i.console.PrintToolHeader(idx+1, len(toolsToInstall), tool)
-
-		if err := i.installTool(tool); err != nil {
-			i.console.PrintError(tool.GetDisplayName(), err)
+	for idx := 0; idx < len(toolsToInstall); {
+		// Tools installed by one manager are installed together
+		if batch := i.batch(toolsToInstall[idx:]); len(batch.tools) > 1 {
+			i.console.PrintBatchHeader(idx+1, len(toolsToInstall), batch.tools, batch.manager)
+			for k, err := range i.installBatch(batch) {
+				needsReboot = i.finish(batch.tools[k], err) || needsReboot
+			}
+			idx += len(batch.tools)
 			continue
 		}
 
-		i.installedTools[tool.Name] = true
+		tool := toolsToInstall[idx]
+		idx++
+		i.console.PrintToolHeader(idx, len(toolsToInstall), tool)
 
-		// Verify if enabled
-		if i.config.Settings.VerifyInstallations {
-			if err := i.verifyTool(tool); err != nil {
-				i.console.PrintWarning(tool.GetDisplayName(), "installed but verification failed")
-			} else {
-				i.console.PrintSuccess(tool.GetDisplayName(), "installed successfully")
-			}
-		} else {
-			i.console.PrintSuccess(tool.GetDisplayName(), "installed")
+		if !i.conditionMet(tool) {
+			i.console.PrintSkipped(tool.GetDisplayName(), "condition false")
+			continue
 		}
 
-		if tool.RequiresReboot {
-			needsReboot = true
-		}
+		needsReboot = i.finish(tool, i.installTool(tool)) || needsReboot
 	}
 
 	i.console.PrintComplete(needsReboot)
@@ -80,12 +108,39 @@ func (i *Installer) Run() error {
 	return nil
 }
 
+// finish reports the result of installing a tool, verifying it when
+// enabled, and returns whether it needs a reboot
+func (i *Installer) finish(tool *config.Tool, err error) bool {
+	if err != nil {
+		i.console.PrintError(tool.GetDisplayName(), err)
+		return false
+	}
+
+	i.installedTools[tool.Name] = true
+
+	// Verify if enabled
+	if i.config.Settings.VerifyInstallations {
+		if err := i.verifyTool(tool); err != nil {
+			i.console.PrintWarning(tool.GetDisplayName(), "installed but verification failed")
+		} else {
+			i.console.PrintSuccess(tool.GetDisplayName(), "installed successfully")
+		}
+	} else {
+		i.console.PrintSuccess(tool.GetDisplayName(), "installed")
+	}
+
+	return tool.RequiresReboot
+}
+
 // installTool installs a single tool
 func (i *Installer) installTool(tool *config.Tool) error {
-	if i.installedTools[tool.Name] {
+	if i.installedTools[tool.Name] || i.packageInstalled(tool) {
 		i.console.PrintInfo("Already installed, skipping...")
 		return nil
 	}
+	if err := i.failedTools[tool.Name]; err != nil {
+		return err
+	}
 
 	// Pre-install commands
 	if err := i.executor.RunCommands(tool.PreInstall, "Pre-install"); err != nil {
@@ -101,7 +156,7 @@ func (i *Installer) installTool(tool *config.Tool) error {
 	}
 
 	// Platform-specific installation
-	platformConfig := tool.GetPlatformConfig(i.system.OS)
+	platformConfig := i.platformConfig(tool)
 	if platformConfig == nil {
 		return fmt.Errorf("%w: %s on %s", domain.ErrNoPlatformConfig, tool.Name, i.system.OS)
 	}
@@ -115,7 +170,8 @@ func (i *Installer) installTool(tool *config.Tool) error {
 	}
 
 	// Try package manager
-	if err := i.executor.InstallViaPackageManager(tool, platformConfig); err == nil {
+	err := i.executor.InstallViaPackageManager(tool, platformConfig)
+	if err == nil {
 		return i.executor.RunCommands(tool.PostInstall, "Post-install")
 	}
 
@@ -128,5 +184,76 @@ func (i *Installer) installTool(tool *config.Tool) error {
 		return i.executor.RunCommands(tool.PostInstall, "Post-install")
 	}
 
-	return domain.ErrNoInstallMethod
+	return fmt.Errorf("%w: %v", domain.ErrNoInstallMethod, err)
+}
+
+// platformConfig returns the tool's configuration for the current platform,
+// with any matching distro override applied, or nil when there is none or its when condition does not hold
+func (i *Installer) platformConfig(tool *config.Tool) *config.PlatformConfig {
+	platformConfig := tool.PlatformConfigFor(i.system.OS, i.system.DistroIDs()...)
+	if platformConfig == nil || !i.evaluate(platformConfig.When) {
+		return nil
+	}
+	return platformConfig
+}
+
+// packageInstalled reports whether a tool installed with a package manager
+// is installed already, and marks it installed if so. Its manager is only
+// asked once, though batching and installing both need the answer.
+func (i *Installer) packageInstalled(tool *config.Tool) bool {
+	if i.missingTools[tool.Name] {
+		return false
+	}
+	platformConfig := i.packageConfig(tool)
+	if platformConfig == nil {
+		return false
+	}
+	installed, err := i.executor.IsInstalled(tool, platformConfig)
+	if err != nil || !installed {
+		i.missingTools[tool.Name] = true
+		return false
+	}
+	i.installedTools[tool.Name] = true
+	return true
+}
+
+// packageConfig returns the platform config of a tool that is installed
+// with a package manager, or nil when it does not apply here or installs
+// with commands
+func (i *Installer) packageConfig(tool *config.Tool) *config.PlatformConfig {
+	if !i.conditionMet(tool) || len(tool.CustomInstall) > 0 {
+		return nil
+	}
+	platformConfig := i.platformConfig(tool)
+	if platformConfig == nil || len(platformConfig.CustomCommands) > 0 {
+		return nil
+	}
+	return platformConfig
+}
+
+// conditionMet reports whether a tool applies to this system. A tool is
+// skipped when its own when condition is false, or when it relies on a
+// platform section whose condition is false.
+func (i *Installer) conditionMet(tool *config.Tool) bool {
+	if !i.evaluate(tool.When) {
+		return false
+	}
+
+	if len(tool.CustomInstall) > 0 {
+		return true
+	}
+
+	platformConfig := tool.PlatformConfigFor(i.system.OS, i.system.DistroIDs()...)
+	return platformConfig == nil || i.evaluate(platformConfig.When)
+}
+
+// evaluate evaluates a when expression; empty expressions are always true.
+// Expressions are checked by config.Validate, so parse errors count as false.
+func (i *Installer) evaluate(expr string) bool {
+	if expr == "" {
+		return true
+	}
+
+	ok, err := condition.Evaluate(expr, i.facts)
+	return err == nil && ok
 }
@@ -0,0 +1,110 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/domain"
+	"github.com/araldhafeeri/stackup/internal/ui"
+)
+
+func TestConditionMet(t *testing.T) {
+	sys := &domain.System{OS: "linux", Arch: "arm64", Distro: "ubuntu", DistroVersion: "22.04"}
+	installer := New(&config.Config{}, sys, ui.NewConsole())
+
+	tests := []struct {
+		name     string
+		tool     *config.Tool
+		expected bool
+	}{
+		{
+			name:     "No Condition",
+			tool:     &config.Tool{Name: "git", Linux: &config.PlatformConfig{}},
+			expected: true,
+		},
+		{
+			name:     "Tool Condition True",
+			tool:     &config.Tool{Name: "tool", When: `arch == "arm64"`, Linux: &config.PlatformConfig{}},
+			expected: true,
+		},
+		{
+			name:     "Tool Condition False",
+			tool:     &config.Tool{Name: "tool", When: `arch == "amd64"`, Linux: &config.PlatformConfig{}},
+			expected: false,
+		},
+		{
+			name: "Platform Condition False",
+			tool: &config.Tool{
+				Name:  "tool",
+				Linux: &config.PlatformConfig{When: `distro_version >= "24.04"`},
+			},
+			expected: false,
+		},
+		{
+			name: "Other Platform Condition Ignored",
+			tool: &config.Tool{
+				Name:    "tool",
+				Linux:   &config.PlatformConfig{},
+				Windows: &config.PlatformConfig{When: `arch == "amd64"`},
+			},
+			expected: true,
+		},
+		{
+			name: "Custom Install Ignores Platform Condition",
+			tool: &config.Tool{
+				Name:          "tool",
+				Linux:         &config.PlatformConfig{When: `distro == "fedora"`},
+				CustomInstall: []config.Command{{Command: "echo"}},
+			},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := installer.conditionMet(tt.tool); result != tt.expected {
+				t.Errorf("conditionMet() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlatformConfigCondition(t *testing.T) {
+	sys := &domain.System{OS: "linux", Distro: "fedora"}
+	installer := New(&config.Config{}, sys, ui.NewConsole())
+
+	tool := &config.Tool{
+		Name:  "tool",
+		Linux: &config.PlatformConfig{When: `distro == "ubuntu"`},
+	}
+
+	if installer.platformConfig(tool) != nil {
+		t.Error("platformConfig should be nil when its condition is false")
+	}
+
+	tool.Linux.When = `distro == "fedora"`
+	if installer.platformConfig(tool) != tool.Linux {
+		t.Error("platformConfig should return the linux section when its condition holds")
+	}
+}
+
+func TestPlatformConfigDistroOverride(t *testing.T) {
+	sys := &domain.System{OS: "linux", Distro: "pop", DistroLike: []string{"ubuntu", "debian"}}
+	installer := New(&config.Config{}, sys, ui.NewConsole())
+
+	tool := &config.Tool{
+		Name: "tool",
+		Linux: &config.PlatformConfig{
+			PackageNames: map[string]string{"apt": "tool"},
+			Distros: map[string]*config.PlatformConfig{
+				"ubuntu": {PackageNames: map[string]string{"apt": "tool-ubuntu"}},
+				"debian": {PackageNames: map[string]string{"apt": "tool-debian"}},
+			},
+		},
+	}
+
+	cfg := installer.platformConfig(tool)
+	if cfg == nil || cfg.PackageNames["apt"] != "tool-ubuntu" {
+		t.Errorf("platformConfig() should prefer the closest ID_LIKE override, got %+v", cfg)
+	}
+}
@@ -0,0 +1,51 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+// managers returns the system package managers that install tools, in the
+// order they are first used. Language managers fetch what they install
+// directly, so they have no index to refresh.
+func (i *Installer) managers(tools []*config.Tool) []string {
+	var managers []string
+	seen := make(map[string]bool)
+	for _, tool := range tools {
+		platformConfig := i.packageConfig(tool)
+		if platformConfig == nil {
+			continue
+		}
+		manager := i.executor.PackageManager(tool, platformConfig)
+		if manager == "" || seen[manager] || domain.IsLanguagePackageManager(manager) {
+			continue
+		}
+		seen[manager] = true
+		managers = append(managers, manager)
+	}
+	return managers
+}
+
+// refreshIndexes refreshes the package index of each manager the tools use
+// once. Managers that report when they were last refreshed are not
+// refreshed again within settings.index_max_age. A failed refresh is only a
+// warning, since installing from the current index may still succeed.
+func (i *Installer) refreshIndexes(tools []*config.Tool) {
+	var refreshed []string
+	for _, manager := range i.managers(tools) {
+		ok, err := i.executor.RefreshIndex(manager, i.config.Settings.MaxIndexAge())
+		if err != nil {
+			i.console.PrintWarning("", err.Error())
+			continue
+		}
+		if ok {
+			refreshed = append(refreshed, manager)
+		}
+	}
+	if len(refreshed) > 0 {
+		i.console.PrintInfo(fmt.Sprintf("Refreshed package indexes: %s", strings.Join(refreshed, ", ")))
+	}
+}
@@ -0,0 +1,29 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/domain"
+	"github.com/araldhafeeri/stackup/internal/ui"
+)
+
+func TestManagers(t *testing.T) {
+	linux := &config.PlatformConfig{}
+	tools := []*config.Tool{
+		{Name: "git", Linux: linux},
+		{Name: "slack", Manager: "flatpak", Linux: linux},
+		{Name: "ripgrep", Manager: "cargo"},
+		{Name: "script", CustomInstall: []config.Command{{Command: "echo"}}},
+		{Name: "custom", Linux: &config.PlatformConfig{CustomCommands: []config.Command{{Command: "echo"}}}},
+		{Name: "arm-only", Manager: "snap", When: `arch == "arm64"`, Linux: linux},
+		{Name: "mac-only", Manager: "brew", MacOS: linux},
+		{Name: "curl", Linux: linux},
+	}
+
+	installer := New(&config.Config{}, &domain.System{OS: "linux", Arch: "amd64", PackageManager: "apt"}, ui.NewConsole())
+	if got, want := strings.Join(installer.managers(tools), ","), "apt,flatpak"; got != want {
+		t.Errorf("managers() = %s, want %s", got, want)
+	}
+}
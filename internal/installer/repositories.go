@@ -0,0 +1,23 @@
+package installer
+
+import (
+	"fmt"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+// addRepositories adds the third-party repositories of the tools installed
+// with a package manager. A tool whose repository cannot be added fails
+// without being installed, so a package of the same name from the
+// distribution's own repositories is never installed in its place.
+func (i *Installer) addRepositories(tools []*config.Tool) {
+	for _, tool := range tools {
+		platformConfig := i.packageConfig(tool)
+		if platformConfig == nil || len(platformConfig.Repositories) == 0 {
+			continue
+		}
+		if err := i.executor.AddRepositories(tool, platformConfig); err != nil {
+			i.failedTools[tool.Name] = fmt.Errorf("failed to add repositories: %w", err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/domain"
+	"github.com/araldhafeeri/stackup/internal/ui"
+)
+
+func TestAddRepositoriesFailure(t *testing.T) {
+	linux := &config.PlatformConfig{}
+	tools := []*config.Tool{
+		{Name: "git", Linux: linux},
+		// No manager named portage exists, so its repository cannot be added
+		{Name: "vendor", Linux: &config.PlatformConfig{Repositories: []config.Repository{{Name: "vendor", Manager: "portage"}}}},
+		{Name: "curl", Linux: linux},
+	}
+
+	installer := New(&config.Config{}, &domain.System{OS: "linux", PackageManager: "apt"}, ui.NewConsole())
+	installer.executor.SetRunner(&fakeRunner{})
+	installer.addRepositories(tools)
+
+	if b := installer.batch(tools); len(b.tools) != 1 || b.tools[0].Name != "git" {
+		t.Errorf("batch = %v, want git alone", b.tools)
+	}
+	if b := installer.batch(tools[1:]); len(b.tools) != 0 {
+		t.Errorf("batch starting at vendor = %v, want none", b.tools)
+	}
+
+	err := installer.installTool(tools[1])
+	if err == nil || !strings.Contains(err.Error(), "failed to add repositories") {
+		t.Errorf("installTool(vendor) error = %v, want the repository error", err)
+	}
+}
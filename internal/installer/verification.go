@@ -1,7 +1,9 @@
 package installer
 
 import (
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/araldhafeeri/stackup/internal/config"
@@ -9,17 +11,25 @@ import (
 
 // verifyTool checks if a tool was installed correctly
 func (i *Installer) verifyTool(tool *config.Tool) error {
-	// Use custom verify command if specified
+	// Default: try --version, unless a custom verify command is specified
+	args := []string{tool.Name, "--version"}
 	if tool.VerifyCommand != "" {
-		parts := strings.Fields(tool.VerifyCommand)
-		cmd := exec.Command(parts[0], parts[1:]...)
-		if err := cmd.Run(); err != nil {
-			return err
+		args = strings.Fields(tool.VerifyCommand)
+	}
+
+	// Runtimes installed by a version manager run through its shims, which
+	// are not in PATH until the user's shell is restarted
+	var env []string
+	if platformConfig := i.platformConfig(tool); platformConfig != nil {
+		if dir := i.executor.ShimDir(tool, platformConfig); dir != "" {
+			if shim, err := exec.LookPath(filepath.Join(dir, args[0])); err == nil {
+				args[0] = shim
+			}
+			env = append(os.Environ(), "PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"))
 		}
-		return nil
 	}
 
-	// Default: try --version
-	cmd := exec.Command(tool.Name, "--version")
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = env
 	return cmd.Run()
 }
@@ -1,7 +1,10 @@
 package installer
 
 import (
+	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/araldhafeeri/stackup/internal/config"
@@ -69,3 +72,27 @@ func TestVerifyTool(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyToolThroughShims(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shims are shell scripts")
+	}
+
+	dataDir := t.TempDir()
+	t.Setenv("MISE_DATA_DIR", dataDir)
+	if err := os.MkdirAll(filepath.Join(dataDir, "shims"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	shim := filepath.Join(dataDir, "shims", "stackup-test-runtime")
+	if err := os.WriteFile(shim, []byte("#!/bin/sh\nexit 0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	installer := New(&config.Config{}, &domain.System{OS: "linux"}, ui.NewConsole())
+	tool := &config.Tool{Name: "stackup-test-runtime", Version: "1", Manager: "mise"}
+	assert.NoError(t, installer.verifyTool(tool), "should run the runtime through the mise shim")
+
+	tool.Manager = ""
+	tool.Linux = &config.PlatformConfig{}
+	assert.Error(t, installer.verifyTool(tool), "should not find the runtime without a version manager")
+}
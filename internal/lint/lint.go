@@ -0,0 +1,159 @@
+// Package lint checks configurations for risky or ineffective settings that
+// are valid YAML but unlikely to do what the author intended
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+// Severity ranks findings; the values match SARIF result levels
+type Severity string
+
+// Severities
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// Rule is a single lint check
+type Rule struct {
+	ID          string
+	Name        string
+	Severity    Severity
+	Description string
+	check       func(cfg *config.Config, report reportFunc)
+}
+
+// Finding is a problem reported by a rule
+type Finding struct {
+	Rule    *Rule
+	Tool    string // empty for findings outside a tool
+	Pos     config.Position
+	Message string
+}
+
+// String formats the finding as `file:line:col: severity: tool "x": message [ID name]`
+func (f Finding) String() string {
+	var b strings.Builder
+	if pos := f.Pos.String(); pos != "" {
+		b.WriteString(pos)
+		b.WriteString(": ")
+	}
+	b.WriteString(string(f.Rule.Severity))
+	b.WriteString(": ")
+	if f.Tool != "" {
+		fmt.Fprintf(&b, "tool %q: ", f.Tool)
+	}
+	fmt.Fprintf(&b, "%s [%s %s]", f.Message, f.Rule.ID, f.Rule.Name)
+	return b.String()
+}
+
+// reportFunc is how rules report findings
+type reportFunc func(tool string, pos config.Position, format string, args ...interface{})
+
+// ConfigRule reports load and validation errors so they share the output
+// format of lint findings
+var ConfigRule = &Rule{
+	ID:          "STK000",
+	Name:        "invalid-config",
+	Severity:    SeverityError,
+	Description: "The configuration could not be loaded or failed validation",
+}
+
+// SchemaRule reports violations of the JSON Schema, found before any
+// semantic checks run
+var SchemaRule = &Rule{
+	ID:          "STK009",
+	Name:        "schema-violation",
+	Severity:    SeverityError,
+	Description: "The configuration does not match the StackUp JSON Schema",
+}
+
+// Rules returns every lint rule, ordered by ID
+func Rules() []*Rule {
+	return rules
+}
+
+// Run applies every rule to the configuration, dropping findings that a
+// tool suppresses through lint_ignore
+func Run(cfg *config.Config) []Finding {
+	ignored := make(map[string]map[string]bool)
+	for _, tool := range cfg.Tools {
+		for _, id := range tool.LintIgnore {
+			if ignored[tool.Name] == nil {
+				ignored[tool.Name] = make(map[string]bool)
+			}
+			ignored[tool.Name][strings.ToLower(id)] = true
+		}
+	}
+
+	var findings []Finding
+	for _, rule := range rules {
+		rule.check(cfg, func(tool string, pos config.Position, format string, args ...interface{}) {
+			if ignored[tool][strings.ToLower(rule.ID)] || ignored[tool][strings.ToLower(rule.Name)] {
+				return
+			}
+			findings = append(findings, Finding{
+				Rule:    rule,
+				Tool:    tool,
+				Pos:     pos,
+				Message: fmt.Sprintf(format, args...),
+			})
+		})
+	}
+
+	Sort(findings)
+	return findings
+}
+
+// FromError converts a load, schema or validation error into findings of
+// the given rule, one per positioned config error
+func FromError(rule *Rule, err error) []Finding {
+	var errs config.Errors
+	if !errors.As(err, &errs) {
+		return []Finding{{Rule: rule, Message: err.Error()}}
+	}
+
+	findings := make([]Finding, 0, len(errs))
+	for _, e := range errs {
+		msg := e.Msg
+		if e.Context != "" {
+			msg = e.Context + ": " + msg
+		}
+		findings = append(findings, Finding{Rule: rule, Pos: e.Pos, Message: msg})
+	}
+	return findings
+}
+
+// Sort orders findings by file, line, column and rule
+func Sort(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Pos.File != b.Pos.File {
+			return a.Pos.File < b.Pos.File
+		}
+		if a.Pos.Line != b.Pos.Line {
+			return a.Pos.Line < b.Pos.Line
+		}
+		if a.Pos.Column != b.Pos.Column {
+			return a.Pos.Column < b.Pos.Column
+		}
+		return a.Rule.ID < b.Rule.ID
+	})
+}
+
+// HasErrors reports whether any finding has error severity
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Rule.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,340 @@
+package lint
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+func load(t *testing.T, content string) *config.Config {
+	t.Helper()
+	cfg, err := config.LoadFromBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	return cfg
+}
+
+// ruleIDs returns the rule IDs of findings in order
+func ruleIDs(findings []Finding) []string {
+	ids := make([]string, len(findings))
+	for i, f := range findings {
+		ids[i] = f.Rule.ID
+	}
+	return ids
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected []string
+		message  string
+	}{
+		{
+			name: "Clean Config",
+			config: `tools:
+  - name: git
+    version: latest
+    linux:
+      package_names:
+        apt: git
+    windows:
+      installer: https://example.com/git.exe
+      sha256: 0123abcd
+      type: exe
+`,
+			expected: nil,
+		},
+		{
+			name: "Insecure URL",
+			config: `tools:
+  - name: git
+    version: latest
+    windows:
+      installer: http://example.com/git.exe
+      sha256: 0123abcd
+`,
+			expected: []string{"STK001"},
+			message:  "windows.installer uses plain http",
+		},
+		{
+			name: "Missing Checksum",
+			config: `tools:
+  - name: git
+    version: latest
+    linux:
+      ubuntu:
+        installer: https://example.com/git.deb
+        type: deb
+`,
+			expected: []string{"STK002"},
+			message:  "linux.ubuntu.installer is downloaded without a sha256 checksum",
+		},
+		{
+			name: "Sudo In User Tool",
+			config: `tools:
+  - name: ruff
+    version: latest
+    linux:
+      package_names:
+        pipx: ruff
+    post_install:
+      - command: ruff
+        args: [--version]
+        sudo: true
+`,
+			expected: []string{"STK003"},
+			message:  `post_install[0] runs "ruff" with sudo`,
+		},
+		{
+			name: "Sudo In System Tool",
+			config: `tools:
+  - name: docker
+    version: latest
+    linux:
+      package_names:
+        apt: docker.io
+    post_install:
+      - command: usermod
+        args: [-aG, docker, me]
+        sudo: true
+`,
+			expected: nil,
+		},
+		{
+			name: "Ignored Install Error",
+			config: `tools:
+  - name: rust
+    version: latest
+    linux:
+      custom_commands:
+        - command: sh
+          args: [rustup.sh]
+          ignore_error: true
+    post_install:
+      - command: rustc
+        ignore_error: true
+`,
+			expected: []string{"STK004"},
+			message:  "linux.custom_commands[0] sets ignore_error",
+		},
+		{
+			name: "Unknown Preset Tool",
+			config: `tools:
+  - name: git
+    version: latest
+    linux:
+      package_names:
+        apt: git
+presets:
+  core:
+    tools: [git, hg]
+`,
+			expected: []string{"STK005"},
+			message:  `preset "core" references unknown tool "hg"`,
+		},
+		{
+			name: "Empty Package Names",
+			config: `tools:
+  - name: git
+    version: latest
+    linux:
+      package_names: {}
+    macos:
+      package_names:
+        brew: ""
+`,
+			expected: []string{"STK006", "STK006"},
+			message:  "macos.package_names.brew is empty",
+		},
+		{
+			name: "Shadowed By Custom Install",
+			config: `tools:
+  - name: nvm
+    version: latest
+    linux:
+      package_names:
+        apt: nvm
+    custom_install:
+      - command: bash
+        args: [install.sh]
+`,
+			expected: []string{"STK007"},
+			message:  "linux is never used because custom_install takes precedence",
+		},
+		{
+			name: "Shadowed By Custom Commands",
+			config: `tools:
+  - name: nvm
+    version: latest
+    linux:
+      package_names:
+        apt: nvm
+      installer: https://example.com/nvm.sh
+      sha256: abcd
+      type: sh
+      custom_commands:
+        - command: bash
+          args: [install.sh]
+`,
+			expected: []string{"STK007"},
+			message:  "linux: package_names and installer are never used because custom_commands take precedence",
+		},
+		{
+			name: "Platform Excluded By When",
+			config: `tools:
+  - name: wsl-tools
+    version: latest
+    when: os == "linux"
+    linux:
+      package_names:
+        apt: wslu
+    windows:
+      package_names:
+        winget: wslu
+`,
+			expected: []string{"STK007"},
+			message:  `windows can never be used: when "os == \"linux\"" is false on windows`,
+		},
+		{
+			name: "Dynamic When Is Reachable",
+			config: `tools:
+  - name: docker
+    version: latest
+    when: os == "linux" && !container
+    windows:
+      package_names:
+        winget: Docker.DockerDesktop
+`,
+			expected: nil,
+		},
+		{
+			name: "Distro Override Outside Linux",
+			config: `tools:
+  - name: git
+    version: latest
+    macos:
+      brew: git
+      ubuntu:
+        brew: git
+`,
+			expected: []string{"STK007"},
+			message:  "macos.ubuntu: distro overrides only apply on linux",
+		},
+		{
+			name: "Unsupported Type",
+			config: `tools:
+  - name: tool
+    version: latest
+    linux:
+      installer: https://example.com/tool.zip
+      sha256: abcd
+      type: zip
+`,
+			expected: []string{"STK008"},
+			message:  `linux.type "zip" is not supported`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := Run(load(t, tt.config))
+
+			got := ruleIDs(findings)
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Fatalf("Rules = %v, want %v\n%v", got, tt.expected, findings)
+			}
+
+			if tt.message == "" {
+				return
+			}
+			for _, f := range findings {
+				if strings.Contains(f.Message, tt.message) {
+					return
+				}
+			}
+			t.Errorf("No finding contains %q: %v", tt.message, findings)
+		})
+	}
+}
+
+func TestSuppression(t *testing.T) {
+	cfg := load(t, `tools:
+  - name: legacy
+    version: latest
+    lint_ignore: [STK001, missing-checksum]
+    windows:
+      installer: http://example.com/legacy.exe
+      type: zip
+  - name: other
+    version: latest
+    windows:
+      installer: http://example.com/other.exe
+      sha256: abcd
+`)
+
+	got := ruleIDs(Run(cfg))
+	expected := []string{"STK008", "STK001"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Rules = %v, want %v", got, expected)
+	}
+}
+
+func TestFindingPositions(t *testing.T) {
+	cfg := load(t, `tools:
+  - name: git
+    version: latest
+    windows:
+      installer: http://example.com/git.exe
+      sha256: abcd
+`)
+
+	findings := Run(cfg)
+	if len(findings) != 1 {
+		t.Fatalf("Expected one finding, got %v", findings)
+	}
+
+	expected := `<input>:4:5: error: tool "git": windows.installer uses plain http: http://example.com/git.exe [STK001 insecure-url]`
+	if got := findings[0].String(); got != expected {
+		t.Errorf("String() = %q, want %q", got, expected)
+	}
+}
+
+func TestFromError(t *testing.T) {
+	_, err := config.LoadFromBytes([]byte(`tools:
+  - name: git
+    verison: latest
+`))
+	if err == nil {
+		t.Fatal("Expected load error")
+	}
+
+	findings := FromError(ConfigRule, err)
+	if len(findings) != 1 || findings[0].Rule != ConfigRule || findings[0].Pos.Line != 3 {
+		t.Errorf("FromError() = %v, want one positioned config finding", findings)
+	}
+
+	findings = FromError(ConfigRule, errors.New("failed to read file"))
+	if len(findings) != 1 || findings[0].Message != "failed to read file" {
+		t.Errorf("FromError() = %v, want the plain error message", findings)
+	}
+	if !HasErrors(findings) {
+		t.Error("Config errors should count as errors")
+	}
+}
+
+func TestRuleIDsUnique(t *testing.T) {
+	seen := map[string]bool{
+		ConfigRule.ID: true, ConfigRule.Name: true,
+		SchemaRule.ID: true, SchemaRule.Name: true,
+	}
+	for _, rule := range Rules() {
+		if seen[rule.ID] || seen[rule.Name] {
+			t.Errorf("Duplicate rule ID or name: %s %s", rule.ID, rule.Name)
+		}
+		seen[rule.ID], seen[rule.Name] = true, true
+	}
+}
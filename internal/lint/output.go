@@ -0,0 +1,151 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/araldhafeeri/stackup/pkg/version"
+)
+
+// WriteText prints one finding per line followed by a summary
+func WriteText(w io.Writer, findings []Finding) error {
+	counts := make(map[Severity]int)
+	for _, f := range findings {
+		counts[f.Rule.Severity]++
+		if _, err := fmt.Fprintln(w, f.String()); err != nil {
+			return err
+		}
+	}
+
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "No problems found")
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d error(s), %d warning(s), %d note(s)\n",
+		counts[SeverityError], counts[SeverityWarning], counts[SeverityNote])
+	return err
+}
+
+// SARIF 2.1.0 structures, limited to the properties stackup fills in
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes findings as a SARIF 2.1.0 log for code scanning tools
+func WriteSARIF(w io.Writer, findings []Finding) error {
+	all := append([]*Rule{ConfigRule, SchemaRule}, Rules()...)
+
+	driver := sarifDriver{
+		Name:           "stackup",
+		Version:        version.Version,
+		InformationURI: "https://github.com/araldhafeeri/stackup",
+		Rules:          make([]sarifRule, len(all)),
+	}
+	index := make(map[string]int, len(all))
+	for i, rule := range all {
+		index[rule.ID] = i
+		driver.Rules[i] = sarifRule{
+			ID:                   rule.ID,
+			Name:                 rule.Name,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: string(rule.Severity)},
+		}
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, f := range findings {
+		msg := f.Message
+		if f.Tool != "" {
+			msg = fmt.Sprintf("tool %q: %s", f.Tool, msg)
+		}
+
+		result := sarifResult{
+			RuleID:    f.Rule.ID,
+			RuleIndex: index[f.Rule.ID],
+			Level:     string(f.Rule.Severity),
+			Message:   sarifMessage{Text: msg},
+		}
+		if f.Pos.File != "" {
+			location := sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(f.Pos.File)},
+			}
+			if f.Pos.Line > 0 {
+				location.Region = &sarifRegion{StartLine: f.Pos.Line, StartColumn: f.Pos.Column}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: location}}
+		}
+		results = append(results, result)
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
@@ -0,0 +1,79 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+func testFindings() []Finding {
+	return []Finding{
+		{Rule: rules[0], Tool: "git", Pos: config.Position{File: "dev.yaml", Line: 4, Column: 5}, Message: "windows.installer uses plain http"},
+		{Rule: ConfigRule, Message: "no tools defined in configuration"},
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteText(&buf, testFindings()); err != nil {
+		t.Fatalf("WriteText() error: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		`dev.yaml:4:5: error: tool "git": windows.installer uses plain http [STK001 insecure-url]`,
+		"error: no tools defined in configuration [STK000 invalid-config]",
+		"2 error(s), 0 warning(s), 0 note(s)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output missing %q:\n%s", want, output)
+		}
+	}
+
+	buf.Reset()
+	if err := WriteText(&buf, nil); err != nil {
+		t.Fatalf("WriteText() error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No problems found") {
+		t.Errorf("Expected no problems message, got %q", buf.String())
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, testFindings()); err != nil {
+		t.Fatalf("WriteSARIF() error: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF envelope: %+v", log)
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(Rules())+2 {
+		t.Errorf("Driver lists %d rules, want %d", len(run.Tool.Driver.Rules), len(Rules())+2)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("Got %d results, want 2", len(run.Results))
+	}
+
+	first := run.Results[0]
+	if first.RuleID != "STK001" || run.Tool.Driver.Rules[first.RuleIndex].ID != "STK001" || first.Level != "error" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if len(first.Locations) != 1 || first.Locations[0].PhysicalLocation.ArtifactLocation.URI != "dev.yaml" ||
+		first.Locations[0].PhysicalLocation.Region.StartLine != 4 {
+		t.Errorf("Unexpected first location: %+v", first.Locations)
+	}
+	if len(run.Results[1].Locations) != 0 {
+		t.Errorf("Results without a file should have no location: %+v", run.Results[1])
+	}
+}
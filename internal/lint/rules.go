@@ -0,0 +1,380 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/condition"
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+// isUserManager reports whether a manager installs into the user's home
+// directory and must not run as root: Homebrew and the language managers
+func isUserManager(manager string) bool {
+	return manager == domain.PackageManagerBrew || domain.IsLanguagePackageManager(manager)
+}
+
+var rules = []*Rule{
+	{
+		ID:          "STK001",
+		Name:        "insecure-url",
+		Severity:    SeverityError,
+		Description: "Installer URLs must use https",
+		check:       checkInsecureURL,
+	},
+	{
+		ID:          "STK002",
+		Name:        "missing-checksum",
+		Severity:    SeverityWarning,
+		Description: "Downloaded installers should be pinned with a sha256 checksum",
+		check:       checkMissingChecksum,
+	},
+	{
+		ID:          "STK003",
+		Name:        "sudo-in-user-tool",
+		Severity:    SeverityWarning,
+		Description: "Tools installed by a user-level manager should not run commands with sudo",
+		check:       checkSudoInUserTool,
+	},
+	{
+		ID:          "STK004",
+		Name:        "ignored-install-error",
+		Severity:    SeverityWarning,
+		Description: "Install steps should not set ignore_error, or failures go unnoticed",
+		check:       checkIgnoredInstallError,
+	},
+	{
+		ID:          "STK005",
+		Name:        "unknown-preset-tool",
+		Severity:    SeverityError,
+		Description: "Presets must only reference defined tools",
+		check:       checkUnknownPresetTool,
+	},
+	{
+		ID:          "STK006",
+		Name:        "empty-package-names",
+		Severity:    SeverityWarning,
+		Description: "package_names should not be empty or map a manager to an empty name",
+		check:       checkEmptyPackageNames,
+	},
+	{
+		ID:          "STK007",
+		Name:        "unreachable-platform",
+		Severity:    SeverityWarning,
+		Description: "Platform settings that can never be used by the installer",
+		check:       checkUnreachablePlatform,
+	},
+	{
+		ID:          "STK008",
+		Name:        "unsupported-installer-type",
+		Severity:    SeverityError,
+		Description: "Installer types must be one the downloader knows how to run",
+		check:       checkUnsupportedType,
+	},
+}
+
+// section is a platform section or distro override of a tool
+type section struct {
+	path   string // e.g. "linux" or "linux.ubuntu"
+	field  string // top-level tool field, for positions
+	os     string // value of the os fact this section applies to
+	distro string
+	cfg    *config.PlatformConfig
+}
+
+// sections returns a tool's platform sections followed by their distro
+// overrides, in a stable order
+func sections(tool *config.Tool) []section {
+	var result []section
+	for _, platform := range []struct {
+		name string
+		os   string
+		cfg  *config.PlatformConfig
+	}{
+		{"windows", "windows", tool.Windows},
+		{"linux", "linux", tool.Linux},
+		{"macos", "darwin", tool.MacOS},
+	} {
+		if platform.cfg == nil {
+			continue
+		}
+		result = append(result, section{path: platform.name, field: platform.name, os: platform.os, cfg: platform.cfg})
+
+		distros := make([]string, 0, len(platform.cfg.Distros))
+		for distro := range platform.cfg.Distros {
+			distros = append(distros, distro)
+		}
+		sort.Strings(distros)
+
+		for _, distro := range distros {
+			if override := platform.cfg.Distros[distro]; override != nil {
+				result = append(result, section{
+					path:   platform.name + "." + distro,
+					field:  platform.name,
+					os:     platform.os,
+					distro: distro,
+					cfg:    override,
+				})
+			}
+		}
+	}
+	return result
+}
+
+// commandList is a named list of commands within a tool
+type commandList struct {
+	path     string
+	field    string
+	install  bool // part of installing the tool itself
+	commands []config.Command
+}
+
+// commandLists returns every command list of a tool
+func commandLists(tool *config.Tool) []commandList {
+	lists := []commandList{
+		{"pre_install", "pre_install", false, tool.PreInstall},
+		{"custom_install", "custom_install", true, tool.CustomInstall},
+		{"post_install", "post_install", false, tool.PostInstall},
+	}
+	for _, s := range sections(tool) {
+		lists = append(lists, commandList{s.path + ".custom_commands", s.field, true, s.cfg.CustomCommands})
+	}
+	return lists
+}
+
+func checkInsecureURL(cfg *config.Config, report reportFunc) {
+	for _, tool := range cfg.Tools {
+		for _, s := range sections(&tool) {
+			if strings.HasPrefix(strings.ToLower(s.cfg.Installer), "http://") {
+				report(tool.Name, cfg.FieldPosition(tool.Name, s.field),
+					"%s.installer uses plain http: %s", s.path, s.cfg.Installer)
+			}
+		}
+	}
+}
+
+func checkMissingChecksum(cfg *config.Config, report reportFunc) {
+	for _, tool := range cfg.Tools {
+		for _, s := range sections(&tool) {
+			if s.cfg.Installer != "" && s.cfg.SHA256 == "" {
+				report(tool.Name, cfg.FieldPosition(tool.Name, s.field),
+					"%s.installer is downloaded without a sha256 checksum", s.path)
+			}
+		}
+	}
+}
+
+func checkSudoInUserTool(cfg *config.Config, report reportFunc) {
+	for _, tool := range cfg.Tools {
+		manager := userLevelManager(&tool)
+		if manager == "" {
+			continue
+		}
+
+		for _, list := range commandLists(&tool) {
+			for idx, cmd := range list.commands {
+				if cmd.Sudo || cmd.Command == "sudo" {
+					report(tool.Name, cfg.FieldPosition(tool.Name, list.field),
+						"%s[%d] runs %q with sudo, but the tool is installed by %s as the current user",
+						list.path, idx, cmd.Command, manager)
+				}
+			}
+		}
+	}
+}
+
+// userLevelManager returns the user-level manager a tool is installed with:
+// its explicit manager, or the only kind of manager its package names map
+func userLevelManager(tool *config.Tool) string {
+	if tool.Manager != "" {
+		if isUserManager(tool.Manager) {
+			return tool.Manager
+		}
+		return ""
+	}
+
+	found := ""
+	for _, s := range sections(tool) {
+		for manager := range s.cfg.PackageNames {
+			if !isUserManager(manager) {
+				return ""
+			}
+			if found == "" || manager < found {
+				found = manager
+			}
+		}
+	}
+	return found
+}
+
+func checkIgnoredInstallError(cfg *config.Config, report reportFunc) {
+	for _, tool := range cfg.Tools {
+		for _, list := range commandLists(&tool) {
+			if !list.install {
+				continue
+			}
+			for idx, cmd := range list.commands {
+				if cmd.IgnoreError {
+					report(tool.Name, cfg.FieldPosition(tool.Name, list.field),
+						"%s[%d] sets ignore_error, so a failed install is reported as success", list.path, idx)
+				}
+			}
+		}
+	}
+}
+
+func checkUnknownPresetTool(cfg *config.Config, report reportFunc) {
+	defined := make(map[string]bool, len(cfg.Tools))
+	for _, tool := range cfg.Tools {
+		defined[tool.Name] = true
+	}
+
+	names := make([]string, 0, len(cfg.Presets))
+	for name := range cfg.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, tool := range cfg.Presets[name].Tools {
+			if !defined[tool] {
+				report("", cfg.PresetPosition(name), "preset %q references unknown tool %q", name, tool)
+			}
+		}
+	}
+}
+
+func checkEmptyPackageNames(cfg *config.Config, report reportFunc) {
+	for _, tool := range cfg.Tools {
+		for _, s := range sections(&tool) {
+			if s.cfg.PackageNames != nil && len(s.cfg.PackageNames) == 0 {
+				report(tool.Name, cfg.FieldPosition(tool.Name, s.field), "%s.package_names is empty", s.path)
+				continue
+			}
+
+			managers := make([]string, 0, len(s.cfg.PackageNames))
+			for manager, name := range s.cfg.PackageNames {
+				if strings.TrimSpace(name) == "" {
+					managers = append(managers, manager)
+				}
+			}
+			sort.Strings(managers)
+			for _, manager := range managers {
+				report(tool.Name, cfg.FieldPosition(tool.Name, s.field),
+					"%s.package_names.%s is empty", s.path, manager)
+			}
+		}
+	}
+}
+
+func checkUnreachablePlatform(cfg *config.Config, report reportFunc) {
+	for _, tool := range cfg.Tools {
+		for _, s := range sections(&tool) {
+			pos := cfg.FieldPosition(tool.Name, s.field)
+
+			if s.distro != "" && s.os != "linux" {
+				report(tool.Name, pos, "%s: distro overrides only apply on linux", s.path)
+				continue
+			}
+
+			if len(tool.CustomInstall) > 0 && hasInstallMethod(s.cfg) {
+				report(tool.Name, pos, "%s is never used because custom_install takes precedence", s.path)
+				continue
+			}
+
+			if len(s.cfg.CustomCommands) > 0 {
+				if unused := packageFields(s.cfg); len(unused) > 0 {
+					report(tool.Name, pos, "%s: %s never used because custom_commands take precedence",
+						s.path, describeFields(unused))
+				}
+			}
+
+			if s.distro == "" {
+				if where, expr := neverHolds(s.os, tool.When, s.cfg.When); where != "" {
+					report(tool.Name, pos, "%s can never be used: %s %q is false on %s", s.path, where, expr, s.os)
+				}
+			}
+		}
+	}
+}
+
+// hasInstallMethod reports whether a section configures any way to install
+func hasInstallMethod(p *config.PlatformConfig) bool {
+	return len(packageFields(p)) > 0 || len(p.CustomCommands) > 0
+}
+
+// packageFields lists the package and download fields set in a section
+func packageFields(p *config.PlatformConfig) []string {
+	var fields []string
+	if len(p.PackageNames) > 0 {
+		fields = append(fields, "package_names")
+	}
+	if p.Brew != "" {
+		fields = append(fields, "brew")
+	}
+	if p.Installer != "" {
+		fields = append(fields, "installer")
+	}
+	return fields
+}
+
+func describeFields(fields []string) string {
+	if len(fields) == 1 {
+		return fields[0] + " is"
+	}
+	return strings.Join(fields[:len(fields)-1], ", ") + " and " + fields[len(fields)-1] + " are"
+}
+
+// neverHolds reports which of the tool and section when expressions is
+// statically false on the given OS. Expressions that depend on anything but
+// the os fact are assumed reachable.
+func neverHolds(osName, toolWhen, sectionWhen string) (string, string) {
+	facts := &condition.Facts{Values: map[string]string{"os": osName}}
+
+	for _, candidate := range []struct {
+		where string
+		expr  string
+	}{
+		{"when", toolWhen},
+		{fmt.Sprintf("%s.when", sectionName(osName)), sectionWhen},
+	} {
+		if candidate.expr == "" {
+			continue
+		}
+		expr, err := condition.Parse(candidate.expr)
+		if err != nil || !expr.DependsOnlyOn("os") {
+			continue
+		}
+		if !expr.Eval(facts) {
+			return candidate.where, candidate.expr
+		}
+	}
+	return "", ""
+}
+
+// sectionName maps an os fact value to its platform section key
+func sectionName(osName string) string {
+	if osName == "darwin" {
+		return "macos"
+	}
+	return osName
+}
+
+func checkUnsupportedType(cfg *config.Config, report reportFunc) {
+	supported := make(map[string]bool, len(config.InstallerTypes))
+	for _, t := range config.InstallerTypes {
+		supported[t] = true
+	}
+
+	for _, tool := range cfg.Tools {
+		for _, s := range sections(&tool) {
+			if s.cfg.Type != "" && !supported[s.cfg.Type] {
+				report(tool.Name, cfg.FieldPosition(tool.Name, s.field),
+					"%s.type %q is not supported (expected one of %s)",
+					s.path, s.cfg.Type, strings.Join(config.InstallerTypes, ", "))
+			}
+		}
+	}
+}
@@ -2,9 +2,9 @@
 package platform
 
 import (
-	"os/exec"
 	"runtime"
 
+	"github.com/araldhafeeri/stackup/internal/backend"
 	"github.com/araldhafeeri/stackup/internal/domain"
 )
 
@@ -15,41 +15,16 @@ func Detect() *domain.System {
 		Arch: runtime.GOARCH,
 	}
 
-	sys.PackageManager = detectPackageManager(sys.OS)
-
-	return sys
-}
-
-// detectPackageManager finds the available package manager
-func detectPackageManager(osName string) string {
-	switch osName {
-	case "linux":
-		if commandExists("apt-get") {
-			return domain.PackageManagerAPT
-		} else if commandExists("dnf") {
-			return domain.PackageManagerDNF
-		} else if commandExists("pacman") {
-			return domain.PackageManagerPacman
-		}
-	case "darwin":
-		if commandExists("brew") {
-			return domain.PackageManagerBrew
-		}
-	case "windows":
-		if commandExists("winget") {
-			return domain.PackageManagerWinget
-		} else if commandExists("choco") {
-			return domain.PackageManagerChoco
-		}
+	sys.PackageManagers = backend.Detect(sys.OS, backend.ExecRunner{})
+	if len(sys.PackageManagers) > 0 {
+		sys.PackageManager = sys.PackageManagers[0]
 	}
 
-	return ""
-}
+	if sys.IsLinux() {
+		detectLinux(sys, "/")
+	}
 
-// commandExists checks if a command is available in PATH
-func commandExists(cmd string) bool {
-	_, err := exec.LookPath(cmd)
-	return err == nil
+	return sys
 }
 
 // IsElevated checks if the process is running with elevated privileges
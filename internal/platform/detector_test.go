@@ -36,25 +36,6 @@ func TestDetect(t *testing.T) {
 		sys.OS, sys.Arch, sys.PackageManager)
 }
 
-func TestCommandExists(t *testing.T) {
-	tests := []struct {
-		name    string
-		command string
-		// We can't predict exact results across systems, so we just test it doesn't panic
-	}{
-		{"Go Command", "go"},
-		{"NonExistent Command", "this-command-does-not-exist-12345"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Should not panic
-			result := commandExists(tt.command)
-			t.Logf("%s exists: %v", tt.command, result)
-		})
-	}
-}
-
 func TestSystemMethods(t *testing.T) {
 	tests := []struct {
 		name      string
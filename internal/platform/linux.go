@@ -0,0 +1,126 @@
+package platform
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+// ParseOSRelease parses an os-release(5) file into its key/value pairs
+func ParseOSRelease(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		if unquoted, err := strconv.Unquote(val); err == nil {
+			val = unquoted
+		} else {
+			val = strings.Trim(val, `"'`)
+		}
+
+		values[strings.TrimSpace(key)] = val
+	}
+
+	return values, scanner.Err()
+}
+
+// detectLinux fills in distribution, kernel, libc, WSL and container facts.
+// All paths are resolved relative to root so tests can use fixture trees.
+func detectLinux(sys *domain.System, root string) {
+	readOSRelease(sys, root)
+
+	if data, err := os.ReadFile(filepath.Join(root, "proc/sys/kernel/osrelease")); err == nil {
+		sys.KernelVersion = strings.TrimSpace(string(data))
+	}
+
+	sys.Libc = detectLibc(root)
+	sys.IsWSL = detectWSL(sys.KernelVersion, root)
+	sys.IsContainer = detectContainer(root)
+}
+
+// readOSRelease reads /etc/os-release, falling back to /usr/lib/os-release
+func readOSRelease(sys *domain.System, root string) {
+	for _, path := range []string{"etc/os-release", "usr/lib/os-release"} {
+		f, err := os.Open(filepath.Join(root, path))
+		if err != nil {
+			continue
+		}
+
+		values, err := ParseOSRelease(f)
+		f.Close()
+		if err != nil {
+			continue
+		}
+
+		sys.Distro = strings.ToLower(values["ID"])
+		sys.DistroVersion = values["VERSION_ID"]
+		sys.DistroCodename = values["VERSION_CODENAME"]
+		if sys.DistroCodename == "" {
+			sys.DistroCodename = values["UBUNTU_CODENAME"]
+		}
+		if like := strings.Fields(strings.ToLower(values["ID_LIKE"])); len(like) > 0 {
+			sys.DistroLike = like
+		}
+		return
+	}
+}
+
+// detectLibc reports musl when the musl dynamic loader is present
+func detectLibc(root string) string {
+	for _, pattern := range []string{"lib/ld-musl-*.so.1", "usr/lib/ld-musl-*.so.1"} {
+		if matches, _ := filepath.Glob(filepath.Join(root, pattern)); len(matches) > 0 {
+			return domain.LibcMusl
+		}
+	}
+	return domain.LibcGlibc
+}
+
+// detectWSL checks the kernel release and WSL interop markers
+func detectWSL(kernelVersion, root string) bool {
+	if strings.Contains(strings.ToLower(kernelVersion), "microsoft") {
+		return true
+	}
+	return fileExists(filepath.Join(root, "proc/sys/fs/binfmt_misc/WSLInterop"))
+}
+
+// detectContainer checks the marker files and cgroups used by common runtimes
+func detectContainer(root string) bool {
+	if fileExists(filepath.Join(root, ".dockerenv")) || fileExists(filepath.Join(root, "run/.containerenv")) {
+		return true
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "proc/1/cgroup"))
+	if err != nil {
+		return false
+	}
+
+	cgroup := string(data)
+	for _, marker := range []string{"docker", "kubepods", "containerd", "lxc", "podman"} {
+		if strings.Contains(cgroup, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fileExists checks if a path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
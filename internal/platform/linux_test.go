@@ -0,0 +1,158 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		id         string
+		versionID  string
+		idLike     string
+		prettyName string
+	}{
+		{"ubuntu-22.04", "ubuntu", "22.04", "debian", "Ubuntu 22.04.4 LTS"},
+		{"fedora-40", "fedora", "40", "", "Fedora Linux 40 (Workstation Edition)"},
+		{"arch", "arch", "", "", "Arch Linux"},
+		{"alpine-3.19", "alpine", "3.19.1", "", "Alpine Linux v3.19"},
+		{"rocky-9", "rocky", "9.3", "rhel centos fedora", "Rocky Linux 9.3 (Blue Onyx)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "os-release", tt.fixture))
+			if err != nil {
+				t.Fatalf("Failed to open fixture: %v", err)
+			}
+			defer f.Close()
+
+			values, err := ParseOSRelease(f)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if values["ID"] != tt.id {
+				t.Errorf("ID = %q, want %q", values["ID"], tt.id)
+			}
+			if values["VERSION_ID"] != tt.versionID {
+				t.Errorf("VERSION_ID = %q, want %q", values["VERSION_ID"], tt.versionID)
+			}
+			if values["ID_LIKE"] != tt.idLike {
+				t.Errorf("ID_LIKE = %q, want %q", values["ID_LIKE"], tt.idLike)
+			}
+			if values["PRETTY_NAME"] != tt.prettyName {
+				t.Errorf("PRETTY_NAME = %q, want %q", values["PRETTY_NAME"], tt.prettyName)
+			}
+		})
+	}
+}
+
+// fixtureRoot builds a fake filesystem root from a map of relative paths to contents
+func fixtureRoot(t *testing.T, osRelease string, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+
+	if osRelease != "" {
+		data, err := os.ReadFile(filepath.Join("testdata", "os-release", osRelease))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		files["etc/os-release"] = string(data)
+	}
+
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestDetectLinux(t *testing.T) {
+	tests := []struct {
+		name      string
+		osRelease string
+		files     map[string]string
+		expected  domain.System
+	}{
+		{
+			name:      "Ubuntu on WSL",
+			osRelease: "ubuntu-22.04",
+			files: map[string]string{
+				"proc/sys/kernel/osrelease": "5.15.146.1-microsoft-standard-WSL2\n",
+			},
+			expected: domain.System{
+				Distro:         "ubuntu",
+				DistroVersion:  "22.04",
+				DistroCodename: "jammy",
+				DistroLike:     []string{"debian"},
+				KernelVersion:  "5.15.146.1-microsoft-standard-WSL2",
+				Libc:           domain.LibcGlibc,
+				IsWSL:          true,
+			},
+		},
+		{
+			name:      "Alpine Container",
+			osRelease: "alpine-3.19",
+			files: map[string]string{
+				"lib/ld-musl-x86_64.so.1": "",
+				".dockerenv":              "",
+			},
+			expected: domain.System{
+				Distro:        "alpine",
+				DistroVersion: "3.19.1",
+				Libc:          domain.LibcMusl,
+				IsContainer:   true,
+			},
+		},
+		{
+			name:      "Rocky in Kubernetes",
+			osRelease: "rocky-9",
+			files: map[string]string{
+				"proc/1/cgroup": "0::/kubepods/besteffort/pod1234\n",
+			},
+			expected: domain.System{
+				Distro:        "rocky",
+				DistroVersion: "9.3",
+				DistroLike:    []string{"rhel", "centos", "fedora"},
+				Libc:          domain.LibcGlibc,
+				IsContainer:   true,
+			},
+		},
+		{
+			name:      "Fallback to usr/lib",
+			osRelease: "",
+			files: map[string]string{
+				"usr/lib/os-release": "ID=arch\n",
+			},
+			expected: domain.System{
+				Distro: "arch",
+				Libc:   domain.LibcGlibc,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := fixtureRoot(t, tt.osRelease, tt.files)
+
+			sys := &domain.System{}
+			detectLinux(sys, root)
+
+			if !reflect.DeepEqual(*sys, tt.expected) {
+				t.Errorf("detectLinux() = %+v, want %+v", *sys, tt.expected)
+			}
+		})
+	}
+}
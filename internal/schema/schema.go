@@ -0,0 +1,253 @@
+// Package schema generates and checks against the JSON Schema of the
+// StackUp configuration format, for editor completion and validation
+package schema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/domain"
+)
+
+// ID is the URL the committed schema is published at
+const ID = "https://raw.githubusercontent.com/araldhafeeri/stackup/main/internal/schema/stackup.schema.json"
+
+// committed is the schema checked into the repository. A test fails when it
+// no longer matches Generate.
+//
+//go:embed stackup.schema.json
+var committed []byte
+
+// JSON returns the committed schema document
+func JSON() []byte {
+	return committed
+}
+
+// descriptions document fields for editor tooltips, keyed by Go type and
+// yaml field name
+var descriptions = map[string]string{
+	"Config.include":   "Config files to merge under this one, relative to it. Globs are allowed.",
+	"Config.profile":   "Name of this configuration profile.",
+	"Config.settings":  "Global installation settings.",
+	"Config.tools":     "Tools to install, in order.",
+	"Config.presets":   "Named collections of tools.",
+	"Config.templates": "Reusable tool definitions that tools can extend.",
+	"Config.secrets":   "Values read at install time from env, a file or a command, referenced as ${secret:name} in headers and env.",
+	"Config.vars":      "Values substituted for ${name} in tool strings. ${env:NAME} reads the environment.",
+
+	"Settings.auto_update_path":     "Refresh PATH after installing tools.",
+	"Settings.verify_installations": "Run each tool's verify_command after installing it.",
+	"Settings.non_interactive":      "Answer yes to package manager prompts, for unattended runs. Also set by --yes, and when stdin is not a terminal.",
+	"Settings.manager_priority":     "System package managers in order of preference. Tools use the first available manager their package_names list.",
+	"Settings.refresh_indexes":      "Refresh the package index of each package manager used before installing, unless it was refreshed within index_max_age. Defaults to true.",
+	"Settings.index_max_age":        "Minutes a refreshed package index counts as fresh. Defaults to 60.",
+
+	"Preset.description": "What the preset is for.",
+	"Preset.tools":       "Names of the tools in the preset.",
+
+	"Tool.name":            "Unique tool name, also the default package name.",
+	"Tool.display_name":    "Name shown in output.",
+	"Tool.version":         "Version to install, or latest.",
+	"Tool.description":     "What the tool is for.",
+	"Tool.manager":         "Package manager to install with, overriding detection.",
+	"Tool.windows":         "How to install on Windows.",
+	"Tool.linux":           "How to install on Linux. Keys named after a distribution ID override this section on that distribution.",
+	"Tool.macos":           "How to install on macOS.",
+	"Tool.pre_install":     "Commands run before installing.",
+	"Tool.custom_install":  "Commands that install the tool on every platform, instead of the platform sections.",
+	"Tool.post_install":    "Commands run after installing.",
+	"Tool.verify_command":  "Command that succeeds when the tool is installed.",
+	"Tool.requires_reboot": "Ask for a reboot after installing.",
+	"Tool.dependencies":    "Tools that must be installed first.",
+	"Tool.when":            "Condition that must hold for the tool to be installed, e.g. os == \"linux\".",
+	"Tool.lint_ignore":     "Lint rule IDs or names to suppress for this tool.",
+	"Tool.use":             "Catalog recipe to merge under this tool. The name defaults to the recipe name.",
+	"Tool.extends":         "Template to merge under this tool.",
+	"Tool.params":          "Values for the template's {{ placeholders }}.",
+
+	"PlatformConfig.installer":       "URL of an installer to download when no package manager can install the tool.",
+	"PlatformConfig.sha256":          "Expected SHA-256 checksum of the downloaded installer.",
+	"PlatformConfig.type":            "How to run the downloaded installer.",
+	"PlatformConfig.silent_flags":    "Flags for an unattended Windows install.",
+	"PlatformConfig.headers":         "HTTP headers sent when downloading the installer. Values may reference ${secret:name}.",
+	"PlatformConfig.package_names":   "Package name for each package manager.",
+	"PlatformConfig.brew":            "Homebrew formula or cask name.",
+	"PlatformConfig.snap":            "How the snap in package_names is installed.",
+	"PlatformConfig.flatpak":         "How the flatpak in package_names is installed.",
+	"PlatformConfig.repositories":    "Third-party repositories added before the package is installed. They are left in place, never removed.",
+	"PlatformConfig.custom_commands": "Commands that install the tool on this platform.",
+	"PlatformConfig.when":            "Condition that must hold for this section to be used.",
+
+	"SnapOptions.classic": "Install the snap without confinement, as --classic.",
+	"SnapOptions.channel": "Channel to track, e.g. latest/edge.",
+
+	"FlatpakOptions.remote": "Remote to install from. Flathub is used, and added when missing, by default.",
+	"FlatpakOptions.scope":  "Install for the current user or system-wide, the default.",
+
+	"Repository.name":        "File name of the repository, or user/repo for a brew tap.",
+	"Repository.manager":     "Package manager the repository is for. Defaults to the one installing the tool.",
+	"Repository.url":         "Base URL of the repository, URL of a .repo file, PPA such as ppa:user/name, or tap remote.",
+	"Repository.suites":      "Suites of an apt repository. Defaults to the distribution's codename.",
+	"Repository.components":  "Components of an apt repository. Defaults to main.",
+	"Repository.key":         "URL of the key the repository is signed with.",
+	"Repository.fingerprint": "Fingerprint the signing key must have.",
+
+	"Command.command":      "Program to run.",
+	"Command.args":         "Arguments passed to the program.",
+	"Command.description":  "Shown while the command runs.",
+	"Command.sudo":         "Run with sudo on Linux and macOS.",
+	"Command.wait_for":     "Seconds to wait after the command.",
+	"Command.ignore_error": "Continue when the command fails.",
+	"Command.when":         "Condition that must hold for the command to run.",
+	"Command.env":          "Environment variables for the command. Values may reference ${secret:name}.",
+
+	"Secret.env":     "Environment variable holding the secret.",
+	"Secret.file":    "File holding the secret; a leading ~ is the home directory.",
+	"Secret.command": "Command printing the secret, e.g. a password manager CLI.",
+	"Secret.args":    "Arguments passed to the command.",
+}
+
+// enums restrict string fields to known values
+var enums = map[string][]string{
+	"Tool.manager":              domain.PackageManagers,
+	"PlatformConfig.type":       config.InstallerTypes,
+	"FlatpakOptions.scope":      config.FlatpakScopes,
+	"Repository.manager":        config.RepositoryManagers,
+	"Settings.manager_priority": config.SystemPackageManagers(),
+}
+
+// keyEnums restrict the keys of map fields to known values
+var keyEnums = map[string][]string{
+	"PlatformConfig.package_names": domain.PackageManagers,
+}
+
+// required lists fields that must be present
+var required = map[string][]string{
+	"Command":    {"command"},
+	"Repository": {"name"},
+}
+
+// overrides replace the generated schema of fields that accept more than
+// their Go type suggests
+var overrides = map[string]map[string]interface{}{
+	"Config.include": {
+		"anyOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+	},
+}
+
+// Generate builds the schema from the config structs
+func Generate() ([]byte, error) {
+	g := &generator{defs: make(map[string]interface{})}
+
+	root := g.object(reflect.TypeOf(config.Config{}))
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = ID
+	root["title"] = "StackUp configuration"
+	root["$defs"] = g.defs
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// generator collects struct definitions while walking the config types
+type generator struct {
+	defs map[string]interface{}
+}
+
+// typeSchema returns the schema for a Go type; structs become references
+func (g *generator) typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, ok := g.defs[t.Name()]; !ok {
+			g.defs[t.Name()] = nil // placeholder for recursive types
+			g.defs[t.Name()] = g.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+// object builds the schema of a struct from its yaml tags
+func (g *generator) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var additional interface{} = false
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("yaml")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			// Inline maps hold the keys not matched by other fields
+			if field.Type.Kind() == reflect.Map {
+				additional = g.typeSchema(field.Type.Elem())
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		key := t.Name() + "." + name
+		prop := g.typeSchema(field.Type)
+		if override, ok := overrides[key]; ok {
+			prop = make(map[string]interface{}, len(override))
+			for k, v := range override {
+				prop[k] = v
+			}
+		}
+		if values, ok := enums[key]; ok {
+			// The values of lists are restricted item by item
+			if items, ok := prop["items"].(map[string]interface{}); ok {
+				items["enum"] = values
+			} else {
+				prop["enum"] = values
+			}
+		}
+		if values, ok := keyEnums[key]; ok {
+			prop["propertyNames"] = map[string]interface{}{"enum": values}
+		}
+		if desc, ok := descriptions[key]; ok {
+			prop["description"] = desc
+		}
+		properties[name] = prop
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": additional,
+	}
+	if fields, ok := required[t.Name()]; ok {
+		sorted := append([]string{}, fields...)
+		sort.Strings(sorted)
+		schema["required"] = sorted
+	}
+	return schema
+}
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite stackup.schema.json from the config structs")
+
+// TestSchemaUpToDate fails when the config structs change without the
+// committed schema being regenerated with `go test ./internal/schema -update`
+func TestSchemaUpToDate(t *testing.T) {
+	generated, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	if *update {
+		if err := os.WriteFile("stackup.schema.json", generated, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	if !bytes.Equal(generated, JSON()) {
+		t.Error("stackup.schema.json is out of date; run `go test ./internal/schema -update`")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	generated, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	var doc struct {
+		Defs map[string]struct {
+			Properties           map[string]map[string]interface{} `json:"properties"`
+			AdditionalProperties interface{}                       `json:"additionalProperties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(generated, &doc); err != nil {
+		t.Fatalf("Generated schema is not valid JSON: %v", err)
+	}
+
+	for _, def := range []string{"Settings", "Preset", "Tool", "PlatformConfig", "Command"} {
+		if _, ok := doc.Defs[def]; !ok {
+			t.Errorf("Missing definition %s", def)
+		}
+	}
+
+	tool := doc.Defs["Tool"]
+	if _, ok := tool.Properties["manager"]["enum"]; !ok {
+		t.Error("Tool.manager should have an enum")
+	}
+	if tool.AdditionalProperties != false {
+		t.Error("Tool should not allow unknown fields")
+	}
+
+	platform := doc.Defs["PlatformConfig"]
+	if _, ok := platform.Properties["type"]["enum"]; !ok {
+		t.Error("PlatformConfig.type should have an enum")
+	}
+	if _, ok := platform.Properties["package_names"]["propertyNames"]; !ok {
+		t.Error("PlatformConfig.package_names should restrict its keys")
+	}
+	if ref, ok := platform.AdditionalProperties.(map[string]interface{}); !ok || ref["$ref"] != "#/$defs/PlatformConfig" {
+		t.Errorf("PlatformConfig should accept distro overrides, got %v", platform.AdditionalProperties)
+	}
+}
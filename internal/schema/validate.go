@@ -0,0 +1,299 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v3"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+var (
+	compileOnce sync.Once
+	compiled    *jsonschema.Schema
+	compileErr  error
+)
+
+// compiledSchema compiles the committed schema once
+func compiledSchema() (*jsonschema.Schema, error) {
+	compileOnce.Do(func() {
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(committed))
+		if err != nil {
+			compileErr = fmt.Errorf("invalid schema: %w", err)
+			return
+		}
+
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource(ID, doc); err != nil {
+			compileErr = fmt.Errorf("invalid schema: %w", err)
+			return
+		}
+		compiled, compileErr = compiler.Compile(ID)
+	})
+	return compiled, compileErr
+}
+
+// ValidateFile checks a single config file in the given format against the
+// schema
+func ValidateFile(path string, format config.Format) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	return Validate(data, path, format)
+}
+
+// Validate checks a document against the schema. Every violation is
+// reported as a config.Error positioned at the offending value. Values
+// holding a ${var} or {{ param }} are only known once the configuration is
+// loaded, so their violations are left to the config validator.
+func Validate(data []byte, file string, format config.Format) error {
+	sch, err := compiledSchema()
+	if err != nil {
+		return err
+	}
+
+	root, err := config.ParseDocument(data, format)
+	if err != nil {
+		return fmt.Errorf("%s: failed to parse %s: %w", file, strings.ToUpper(string(format)), err)
+	}
+	if root == nil {
+		return nil
+	}
+
+	instance, err := toJSON(root)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	err = sch.Validate(instance)
+	if err == nil {
+		return nil
+	}
+
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	printer := message.NewPrinter(language.English)
+
+	var errs config.Errors
+	for _, v := range violations(verr, nil, printer) {
+		node := locate(root, v.location)
+		if v.key != "" {
+			v.location, node = findKey(node, v.location, v.key)
+		} else if templated(node) {
+			continue
+		}
+		errs = append(errs, &config.Error{
+			Pos:     config.Position{File: file, Line: node.Line, Column: node.Column},
+			Context: pointer(v.location),
+			Msg:     v.msg,
+		})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Pos.Line != errs[j].Pos.Line {
+			return errs[i].Pos.Line < errs[j].Pos.Line
+		}
+		return errs[i].Pos.Column < errs[j].Pos.Column
+	})
+	return errs
+}
+
+// templated reports whether a node is a scalar with a variable or template
+// placeholder in it
+func templated(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode &&
+		(strings.Contains(node.Value, "${") || strings.Contains(node.Value, "{{"))
+}
+
+// violation is a single schema problem at an instance location
+type violation struct {
+	location []string
+	key      string // invalid property name, somewhere below location
+	msg      string
+}
+
+// violations returns the innermost causes of a validation error, which name
+// the actual problems rather than the schema branches that led to them.
+// Property name errors are validated detached from the document and only
+// know the nearest enclosing object, so the key is searched for below it.
+func violations(err *jsonschema.ValidationError, parent []string, printer *message.Printer) []violation {
+	if names, ok := err.ErrorKind.(*kind.PropertyNames); ok {
+		msg := fmt.Sprintf("invalid key %q", names.Property)
+		for _, cause := range violations(&jsonschema.ValidationError{Causes: err.Causes}, nil, printer) {
+			msg += ": " + cause.msg
+		}
+		return []violation{{location: parent, key: names.Property, msg: msg}}
+	}
+
+	if len(err.Causes) == 0 {
+		if err.ErrorKind == nil {
+			return nil
+		}
+		return []violation{{location: err.InstanceLocation, msg: err.ErrorKind.LocalizedString(printer)}}
+	}
+
+	var result []violation
+	for _, cause := range err.Causes {
+		result = append(result, violations(cause, err.InstanceLocation, printer)...)
+	}
+	return result
+}
+
+// pointer formats an instance location as a dotted path, e.g. tools[0].linux
+func pointer(location []string) string {
+	var b strings.Builder
+	for _, token := range location {
+		if _, err := strconv.Atoi(token); err == nil {
+			fmt.Fprintf(&b, "[%s]", token)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(token)
+	}
+	return b.String()
+}
+
+// locate follows an instance location through the YAML tree, stopping at
+// the deepest node found
+func locate(node *yaml.Node, location []string) *yaml.Node {
+	for _, token := range location {
+		node = resolveAlias(node)
+
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == token {
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if idx, err := strconv.Atoi(token); err == nil && idx < len(node.Content) {
+				next = node.Content[idx]
+			}
+		}
+
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+// findKey searches breadth-first below node for a mapping key, returning
+// its location and value. It falls back to node when the key is not found.
+func findKey(node *yaml.Node, location []string, key string) ([]string, *yaml.Node) {
+	type entry struct {
+		node     *yaml.Node
+		location []string
+	}
+
+	queue := []entry{{node, location}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		n := resolveAlias(current.node)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Kind == yaml.MappingNode && n.Content[i].Value == key {
+				return append(append([]string{}, current.location...), key), n.Content[i]
+			}
+		}
+
+		for i, child := range n.Content {
+			switch n.Kind {
+			case yaml.MappingNode:
+				if i%2 == 1 {
+					queue = append(queue, entry{child, append(append([]string{}, current.location...), n.Content[i-1].Value)})
+				}
+			case yaml.SequenceNode:
+				queue = append(queue, entry{child, append(append([]string{}, current.location...), strconv.Itoa(i))})
+			}
+		}
+	}
+
+	return location, node
+}
+
+// toJSON converts a YAML node into the value types the validator expects
+func toJSON(node *yaml.Node) (interface{}, error) {
+	node = resolveAlias(node)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		obj := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			val, err := toJSON(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			obj[node.Content[i].Value] = val
+		}
+		return obj, nil
+
+	case yaml.SequenceNode:
+		arr := make([]interface{}, len(node.Content))
+		for i, item := range node.Content {
+			val, err := toJSON(item)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = val
+		}
+		return arr, nil
+
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!str", "!!timestamp", "!!binary":
+			return node.Value, nil
+		case "!!null":
+			return nil, nil
+		}
+
+		var val interface{}
+		if err := node.Decode(&val); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		switch v := val.(type) {
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case uint64:
+			return float64(v), nil
+		}
+		return val, nil
+	}
+
+	return nil, nil
+}
+
+// resolveAlias follows alias nodes to the node they reference
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+func TestValidateExampleConfig(t *testing.T) {
+	if err := Validate([]byte(config.ExampleConfig), "example.yaml", config.FormatYAML); err != nil {
+		t.Errorf("Example config should match the schema:\n%v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	content := `include: base.yaml
+tools:
+  - name: git
+    version: latest
+    manager: aptt
+    linux:
+      package_names:
+        apt: git
+        snapp: git
+      type: zip
+      ubuntu:
+        package_names:
+          apt: git-all
+    post_install:
+      - description: missing command
+        wait_for: 5
+`
+	err := Validate([]byte(content), "dev.yaml", config.FormatYAML)
+
+	var errs config.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected config.Errors, got %T: %v", err, err)
+	}
+
+	expected := []struct {
+		line    int
+		context string
+	}{
+		{5, "tools[0].manager"},
+		{9, "tools[0].linux.package_names.snapp"},
+		{10, "tools[0].linux.type"},
+		{15, "tools[0].post_install[0]"},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Got %d errors, want %d:\n%v", len(errs), len(expected), err)
+	}
+	for i, want := range expected {
+		if errs[i].Pos.Line != want.line || errs[i].Context != want.context || errs[i].Pos.File != "dev.yaml" {
+			t.Errorf("errs[%d] = %v, want line %d in %s", i, errs[i], want.line, want.context)
+		}
+	}
+}
+
+func TestValidateTemplatedValues(t *testing.T) {
+	content := `vars:
+  mgr: apt
+tools:
+  - name: git
+    version: latest
+    manager: ${mgr}
+    linux:
+      type: "{{ kind }}"
+      flatpak:
+        scope: bogus
+`
+	err := Validate([]byte(content), "dev.yaml", config.FormatYAML)
+
+	var errs config.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected config.Errors, got %T: %v", err, err)
+	}
+	if len(errs) != 1 || errs[0].Context != "tools[0].linux.flatpak.scope" {
+		t.Errorf("Got %v, want only the error in tools[0].linux.flatpak.scope", err)
+	}
+}
+
+func TestValidateEmptyDocument(t *testing.T) {
+	if err := Validate([]byte("# nothing yet\n"), "empty.yaml", config.FormatYAML); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestPointer(t *testing.T) {
+	if got := pointer([]string{"tools", "0", "linux", "package_names"}); got != "tools[0].linux.package_names" {
+		t.Errorf("pointer() = %q", got)
+	}
+}
+
+func TestValidateTOML(t *testing.T) {
+	content := `[[tools]]
+name = "git"
+manager = "aptt"
+
+[tools.linux.package_names]
+apt = "git"
+`
+	err := Validate([]byte(content), "dev.toml", config.FormatTOML)
+
+	var errs config.Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("Expected one error, got %v", err)
+	}
+	if errs[0].Pos.Line != 3 || errs[0].Context != "tools[0].manager" {
+		t.Errorf("Got %v, want line 3 in tools[0].manager", errs[0])
+	}
+}
@@ -0,0 +1,183 @@
+// Package secret resolves config secrets and keeps their values out of all
+// output
+package secret
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces secret values in redacted output
+const Mask = "********"
+
+// Redactor replaces known secret values in text. Values are added as they
+// are resolved.
+type Redactor struct {
+	mu     sync.RWMutex
+	values []string // longest first, so overlapping secrets are fully masked
+}
+
+// NewRedactor creates an empty redactor
+func NewRedactor() *Redactor {
+	return &Redactor{}
+}
+
+// Add registers a secret value. Surrounding whitespace is ignored.
+func (r *Redactor) Add(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, v := range r.values {
+		if v == value {
+			return
+		}
+	}
+	r.values = append(r.values, value)
+	sort.SliceStable(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+}
+
+// Redact masks every secret value in s
+func (r *Redactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, Mask)
+	}
+	return s
+}
+
+// Err wraps err so that its message is redacted. Unwrapping still reaches
+// the original error.
+func (r *Redactor) Err(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &redactedError{err: err, redactor: r}
+}
+
+type redactedError struct {
+	err      error
+	redactor *Redactor
+}
+
+func (e *redactedError) Error() string {
+	return e.redactor.Redact(e.err.Error())
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// pending returns how many trailing bytes of data could be the start of a
+// secret and must be held back until more output arrives
+func (r *Redactor) pending(data []byte) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	held := 0
+	for _, v := range r.values {
+		for n := min(len(v)-1, len(data)); n > held; n-- {
+			if bytes.HasSuffix(data, []byte(v[:n])) {
+				held = n
+				break
+			}
+		}
+	}
+	return held
+}
+
+// Writer redacts everything written through it. Output is passed on as soon
+// as it cannot be part of a secret, so prompts without a trailing newline
+// still appear immediately.
+type Writer struct {
+	redactor *Redactor
+	out      io.Writer
+	mu       sync.Mutex
+	buf      []byte
+}
+
+// Writer returns a writer that redacts into out. Call Flush when done.
+func (r *Redactor) Writer(out io.Writer) *Writer {
+	return &Writer{redactor: r, out: out}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	redacted := []byte(w.redactor.Redact(string(w.buf)))
+	held := w.redactor.pending(redacted)
+
+	if _, err := w.out.Write(redacted[:len(redacted)-held]); err != nil {
+		return 0, err
+	}
+	w.buf = append(w.buf[:0], redacted[len(redacted)-held:]...)
+	return len(p), nil
+}
+
+// Flush writes any output held back
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.out.Write([]byte(w.redactor.Redact(string(w.buf))))
+	w.buf = w.buf[:0]
+	return err
+}
+
+// Capture routes os.Stdout and os.Stderr, including the output of child
+// processes, through the redactor until the returned function is called
+func (r *Redactor) Capture() (restore func(), err error) {
+	stdout, stderr := os.Stdout, os.Stderr
+
+	var wg sync.WaitGroup
+	pipe := func(out *os.File) (*os.File, error) {
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := r.Writer(out)
+			io.Copy(w, reader)
+			w.Flush()
+			reader.Close()
+		}()
+		return writer, nil
+	}
+
+	outWriter, err := pipe(stdout)
+	if err != nil {
+		return nil, err
+	}
+	errWriter, err := pipe(stderr)
+	if err != nil {
+		outWriter.Close()
+		wg.Wait()
+		return nil, err
+	}
+
+	os.Stdout, os.Stderr = outWriter, errWriter
+	return func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		outWriter.Close()
+		errWriter.Close()
+		wg.Wait()
+	}, nil
+}
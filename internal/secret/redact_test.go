@@ -0,0 +1,106 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	r := NewRedactor()
+	r.Add("hunter2")
+	r.Add("hunter2-admin")
+	r.Add("  ")
+
+	tests := map[string]string{
+		"password is hunter2":    "password is " + Mask,
+		"admin: hunter2-admin.":  "admin: " + Mask + ".",
+		"hunter2hunter2":         Mask + Mask,
+		"nothing secret here   ": "nothing secret here   ",
+	}
+	for in, want := range tests {
+		if got := r.Redact(in); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	var nilRedactor *Redactor
+	if got := nilRedactor.Redact("hunter2"); got != "hunter2" {
+		t.Errorf("nil Redactor should pass text through, got %q", got)
+	}
+}
+
+func TestRedactErr(t *testing.T) {
+	r := NewRedactor()
+	r.Add("hunter2")
+
+	base := errors.New("login failed")
+	err := r.Err(fmt.Errorf("token hunter2 rejected: %w", base))
+
+	if err.Error() != "token "+Mask+" rejected: login failed" {
+		t.Errorf("Err() = %q", err.Error())
+	}
+	if !errors.Is(err, base) {
+		t.Error("Err() should keep the error chain")
+	}
+	if r.Err(nil) != nil {
+		t.Error("Err(nil) should be nil")
+	}
+}
+
+func TestWriterSplitWrites(t *testing.T) {
+	r := NewRedactor()
+	r.Add("hunter2")
+
+	var out bytes.Buffer
+	w := r.Writer(&out)
+
+	for _, chunk := range []string{"token: hun", "ter2\n", "Continue? [y/N] "} {
+		w.Write([]byte(chunk))
+	}
+
+	// The prompt is passed on without waiting for a newline
+	if want := "token: " + Mask + "\nContinue? [y/N] "; out.String() != want {
+		t.Errorf("Output = %q, want %q", out.String(), want)
+	}
+
+	w.Write([]byte("last: hunt"))
+	if out.String() != "token: "+Mask+"\nContinue? [y/N] last: " {
+		t.Errorf("A possible secret prefix should be held back, got %q", out.String())
+	}
+	w.Flush()
+	if !bytes.HasSuffix(out.Bytes(), []byte("last: hunt")) {
+		t.Errorf("Flush() should write held back output, got %q", out.String())
+	}
+}
+
+func TestCapture(t *testing.T) {
+	r := NewRedactor()
+	r.Add("hunter2")
+
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = writer
+
+	restore, err := r.Capture()
+	if err != nil {
+		os.Stdout = stdout
+		t.Fatalf("Capture() error: %v", err)
+	}
+	fmt.Println("token=hunter2")
+	restore()
+
+	os.Stdout = stdout
+	writer.Close()
+	got, _ := io.ReadAll(reader)
+
+	if string(got) != "token="+Mask+"\n" {
+		t.Errorf("Captured output = %q", got)
+	}
+}
@@ -0,0 +1,148 @@
+package secret
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+// Store resolves the secrets defined in a config on first use and registers
+// each value with its redactor
+type Store struct {
+	defs     map[string]config.Secret
+	redactor *Redactor
+	run      func(name string, args ...string) ([]byte, error)
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewStore creates a store for the given secret definitions
+func NewStore(defs map[string]config.Secret, redactor *Redactor) *Store {
+	return &Store{
+		defs:     defs,
+		redactor: redactor,
+		run:      runCommand,
+		values:   make(map[string]string),
+	}
+}
+
+// Redactor returns the redactor secret values are registered with
+func (s *Store) Redactor() *Redactor {
+	if s == nil {
+		return nil
+	}
+	return s.redactor
+}
+
+// Resolve returns the value of a secret
+func (s *Store) Resolve(name string) (string, error) {
+	if s == nil {
+		return "", fmt.Errorf("undefined secret %q", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if val, ok := s.values[name]; ok {
+		return val, nil
+	}
+
+	def, ok := s.defs[name]
+	if !ok {
+		return "", fmt.Errorf("undefined secret %q", name)
+	}
+
+	val, err := s.read(def)
+	if err != nil {
+		return "", fmt.Errorf("secret %q: %w", name, s.redactor.Err(err))
+	}
+	if val == "" {
+		return "", fmt.Errorf("secret %q is empty", name)
+	}
+
+	s.redactor.Add(val)
+	s.values[name] = val
+	return val, nil
+}
+
+// ResolveAll resolves several secrets up front, so a missing one fails
+// before anything is installed
+func (s *Store) ResolveAll(names []string) error {
+	for _, name := range names {
+		if _, err := s.Resolve(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Expand replaces ${secret:name} references in value
+func (s *Store) Expand(value string) (string, error) {
+	return config.ExpandSecrets(value, s.Resolve)
+}
+
+// read fetches a secret from its source. Trailing newlines, as left by
+// files and command output, are dropped.
+func (s *Store) read(def config.Secret) (string, error) {
+	switch {
+	case def.Env != "":
+		val, ok := os.LookupEnv(def.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", def.Env)
+		}
+		return val, nil
+
+	case def.File != "":
+		path, err := expandHome(def.File)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+
+	case def.Command != "":
+		out, err := s.run(def.Command, def.Args...)
+		if err != nil {
+			return "", fmt.Errorf("command %q failed: %w", def.Command, err)
+		}
+		return strings.TrimRight(string(out), "\r\n"), nil
+
+	default:
+		return "", fmt.Errorf("no source configured")
+	}
+}
+
+// runCommand runs a secret command, passing its stderr through so password
+// managers can prompt
+func runCommand(name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	return out.Bytes(), err
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
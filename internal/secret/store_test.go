@@ -0,0 +1,80 @@
+package secret
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+func TestStoreResolve(t *testing.T) {
+	t.Setenv("STACKUP_TEST_TOKEN", "from-env")
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	redactor := NewRedactor()
+	store := NewStore(map[string]config.Secret{
+		"env":     {Env: "STACKUP_TEST_TOKEN"},
+		"file":    {File: file},
+		"command": {Command: "pass", Args: []string{"show", "artifactory"}},
+		"unset":   {Env: "STACKUP_TEST_UNSET"},
+		"failing": {Command: "false"},
+	}, redactor)
+
+	calls := 0
+	store.run = func(name string, args ...string) ([]byte, error) {
+		calls++
+		if name == "false" {
+			return nil, errors.New("exit status 1")
+		}
+		return []byte("from-" + name + "\n"), nil
+	}
+
+	for name, want := range map[string]string{"env": "from-env", "file": "from-file", "command": "from-pass"} {
+		got, err := store.Resolve(name)
+		if err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", name, got, err, want)
+		}
+		if redactor.Redact(want) != Mask {
+			t.Errorf("Resolved secret %q was not registered for redaction", name)
+		}
+	}
+
+	store.Resolve("command")
+	if calls != 1 {
+		t.Errorf("Command secrets should run once, ran %d times", calls)
+	}
+
+	for name, want := range map[string]string{
+		"unset":   "environment variable STACKUP_TEST_UNSET is not set",
+		"failing": `command "false" failed`,
+		"missing": `undefined secret "missing"`,
+	} {
+		if _, err := store.Resolve(name); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Resolve(%q) error = %v, want %q", name, err, want)
+		}
+	}
+}
+
+func TestStoreExpand(t *testing.T) {
+	t.Setenv("STACKUP_TEST_TOKEN", "abc123")
+	store := NewStore(map[string]config.Secret{"token": {Env: "STACKUP_TEST_TOKEN"}}, NewRedactor())
+
+	got, err := store.Expand("Bearer ${secret:token}")
+	if err != nil || got != "Bearer abc123" {
+		t.Errorf("Expand() = %q, %v", got, err)
+	}
+
+	var nilStore *Store
+	if _, err := nilStore.Expand("${secret:token}"); err == nil {
+		t.Error("A nil store should not resolve secrets")
+	}
+	if got, err := nilStore.Expand("no secrets"); err != nil || got != "no secrets" {
+		t.Errorf("A nil store should pass plain values through, got %q, %v", got, err)
+	}
+}
@@ -3,6 +3,7 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/araldhafeeri/stackup/internal/config"
 	"github.com/araldhafeeri/stackup/internal/domain"
@@ -31,13 +32,22 @@ func NewConsoleWithOptions(verbose, noColor bool) *Console {
 	}
 }
 
-// PrintHeader prints the application header with system info
-func (c *Console) PrintHeader(version string, sys *domain.System, profile string) {
+// PrintHeader prints the application header with system info and where the
+// config was read from
+func (c *Console) PrintHeader(version string, sys *domain.System, profile string, origins []string) {
 	fmt.Println("🚀 StackUp v" + version)
 	fmt.Println("============================")
 	fmt.Printf("OS: %s | Arch: %s | Package Manager: %s\n",
 		sys.OS, sys.Arch, c.getPackageManagerDisplay(sys.PackageManager))
 
+	if sys.Distro != "" {
+		fmt.Printf("Distro: %s\n", c.getDistroDisplay(sys))
+	}
+
+	if len(origins) > 0 {
+		fmt.Printf("Config: %s\n", strings.Join(origins, " + "))
+	}
+
 	if profile != "" {
 		fmt.Printf("Profile: %s\n", profile)
 	}
@@ -52,6 +62,16 @@ func (c *Console) PrintToolHeader(current, total int, tool *config.Tool) {
 	}
 }
 
+// PrintBatchHeader prints the header for tools installed together by one
+// package manager, numbered from first
+func (c *Console) PrintBatchHeader(first, total int, tools []*config.Tool, manager string) {
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.GetDisplayName()
+	}
+	fmt.Printf("[%d-%d/%d] Installing %s with %s...\n", first, first+len(tools)-1, total, strings.Join(names, ", "), manager)
+}
+
 // PrintSuccess prints a success message
 func (c *Console) PrintSuccess(name, message string) {
 	if name != "" {
@@ -75,6 +95,11 @@ func (c *Console) PrintWarning(name, message string) {
 	}
 }
 
+// PrintSkipped prints a message for a tool that was not installed
+func (c *Console) PrintSkipped(name, reason string) {
+	fmt.Printf("⏭️  %s skipped: %s\n\n", name, reason)
+}
+
 // PrintInfo prints an informational message
 func (c *Console) PrintInfo(message string) {
 	fmt.Printf("   %s\n", message)
@@ -104,6 +129,24 @@ func (c *Console) getPackageManagerDisplay(pm string) string {
 	return pm
 }
 
+// getDistroDisplay describes the Linux distribution and environment
+func (c *Console) getDistroDisplay(sys *domain.System) string {
+	display := sys.Distro
+	if sys.DistroVersion != "" {
+		display += " " + sys.DistroVersion
+	}
+	if sys.Libc != "" {
+		display += " (" + sys.Libc + ")"
+	}
+	if sys.IsWSL {
+		display += " [WSL]"
+	}
+	if sys.IsContainer {
+		display += " [container]"
+	}
+	return display
+}
+
 // Verbose prints a message only if verbose mode is enabled
 func (c *Console) Verbose(format string, args ...interface{}) {
 	if c.verbose {
@@ -54,7 +54,7 @@ func TestPrintHeader(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	console.PrintHeader("1.0.0", sys, "test-profile")
+	console.PrintHeader("1.0.0", sys, "test-profile", []string{"https://example.com/team.yaml (cached, unchanged)"})
 
 	w.Close()
 	os.Stdout = old
@@ -78,6 +78,10 @@ func TestPrintHeader(t *testing.T) {
 	if !strings.Contains(output, "test-profile") {
 		t.Error("Output should contain profile")
 	}
+
+	if !strings.Contains(output, "Config: https://example.com/team.yaml (cached, unchanged)") {
+		t.Error("Output should contain config origin")
+	}
 }
 
 func TestPrintToolHeader(t *testing.T) {
@@ -114,6 +118,27 @@ func TestPrintToolHeader(t *testing.T) {
 	}
 }
 
+func TestPrintSkipped(t *testing.T) {
+	console := NewConsole()
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	console.PrintSkipped("Docker", "condition false")
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	if !strings.Contains(output, "Docker skipped: condition false") {
+		t.Errorf("Output %q should contain skip reason", output)
+	}
+}
+
 func TestVerboseOutput(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -186,3 +211,20 @@ func TestGetPackageManagerDisplay(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDistroDisplay(t *testing.T) {
+	console := NewConsole()
+
+	sys := &domain.System{
+		OS:            "linux",
+		Distro:        "ubuntu",
+		DistroVersion: "22.04",
+		Libc:          domain.LibcGlibc,
+		IsWSL:         true,
+	}
+
+	expected := "ubuntu 22.04 (glibc) [WSL]"
+	if result := console.getDistroDisplay(sys); result != expected {
+		t.Errorf("getDistroDisplay() = %q, want %q", result, expected)
+	}
+}
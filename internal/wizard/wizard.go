@@ -0,0 +1,308 @@
+// Package wizard generates a new configuration from the built-in catalog,
+// either by asking questions or from options given up front
+package wizard
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/araldhafeeri/stackup/internal/catalog"
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/schema"
+)
+
+// Platforms are the platform sections a config can target
+var Platforms = []string{"linux", "macos", "windows"}
+
+// Options describe the config to generate. Nil fields have not been
+// decided yet and are asked for by Ask.
+type Options struct {
+	Profile    string
+	Tools      []string
+	Platforms  []string
+	Verify     *bool
+	UpdatePath *bool
+}
+
+// Defaults fills every undecided option with its default. Tools have no
+// default.
+func (o Options) Defaults() Options {
+	if o.Profile == "" {
+		o.Profile = "dev"
+	}
+	if len(o.Platforms) == 0 {
+		o.Platforms = Platforms
+	}
+	if o.Verify == nil {
+		o.Verify = boolPtr(true)
+	}
+	if o.UpdatePath == nil {
+		o.UpdatePath = boolPtr(true)
+	}
+	return o
+}
+
+// Ask prompts on out for every option not already set, reading answers from
+// in. Empty answers take the default shown in brackets.
+func Ask(in io.Reader, out io.Writer, opts Options) (Options, error) {
+	p := &prompter{in: bufio.NewScanner(in), out: out}
+
+	if len(opts.Tools) == 0 {
+		tools, err := catalog.Tools()
+		if err != nil {
+			return opts, err
+		}
+
+		fmt.Fprintln(out, "Available tools:")
+		for i, tool := range tools {
+			fmt.Fprintf(out, "  %2d) %-10s %s\n", i+1, tool.Name, tool.Description)
+		}
+
+		for len(opts.Tools) == 0 {
+			answer, err := p.ask("Tools to include (numbers or names, comma-separated)", "")
+			if err != nil {
+				return opts, err
+			}
+			opts.Tools, err = parseTools(answer, tools)
+			if err != nil {
+				fmt.Fprintf(out, "%v\n", err)
+			}
+		}
+	}
+
+	if len(opts.Platforms) == 0 {
+		for {
+			answer, err := p.ask("Platforms to target", strings.Join(Platforms, ","))
+			if err != nil {
+				return opts, err
+			}
+			if opts.Platforms, err = ParsePlatforms(answer); err == nil {
+				break
+			}
+			fmt.Fprintf(out, "%v\n", err)
+		}
+	}
+
+	if opts.Verify == nil {
+		answer, err := p.confirm("Verify tools after installing?", true)
+		if err != nil {
+			return opts, err
+		}
+		opts.Verify = &answer
+	}
+
+	if opts.UpdatePath == nil {
+		answer, err := p.confirm("Add installed tools to PATH?", true)
+		if err != nil {
+			return opts, err
+		}
+		opts.UpdatePath = &answer
+	}
+
+	if opts.Profile == "" {
+		answer, err := p.ask("Profile name", "dev")
+		if err != nil {
+			return opts, err
+		}
+		opts.Profile = answer
+	}
+
+	return opts, nil
+}
+
+// prompter reads one answer per line
+type prompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// ask prints a question and returns the trimmed answer, or def when the
+// answer is empty
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+
+	if !p.in.Scan() {
+		fmt.Fprintln(p.out)
+		if err := p.in.Err(); err != nil {
+			return "", err
+		}
+		if def == "" {
+			return "", fmt.Errorf("no answer to %q", question)
+		}
+		return def, nil
+	}
+
+	answer := strings.TrimSpace(p.in.Text())
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// confirm asks a yes/no question
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+
+	for {
+		answer, err := p.ask(question+" ["+hint+"]", "")
+		if err != nil {
+			// Input ended: take the default
+			return def, nil
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "Please answer y or n")
+	}
+}
+
+// parseTools reads a comma-separated list of catalog numbers or names
+func parseTools(answer string, tools []config.Tool) ([]string, error) {
+	var names []string
+	for _, item := range splitList(answer) {
+		if n, err := strconv.Atoi(item); err == nil {
+			if n < 1 || n > len(tools) {
+				return nil, fmt.Errorf("no tool numbered %d", n)
+			}
+			item = tools[n-1].Name
+		}
+		if _, ok := catalog.Lookup(item); !ok {
+			return nil, fmt.Errorf("unknown tool %q", item)
+		}
+		if !slices.Contains(names, item) {
+			names = append(names, item)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("select at least one tool")
+	}
+	return names, nil
+}
+
+// ParseTools checks a comma-separated list of catalog tool names
+func ParseTools(list string) ([]string, error) {
+	tools, err := catalog.Tools()
+	if err != nil {
+		return nil, err
+	}
+	return parseTools(list, tools)
+}
+
+// ParsePlatforms checks a comma-separated list of platforms
+func ParsePlatforms(list string) ([]string, error) {
+	var platforms []string
+	for _, item := range splitList(strings.ToLower(list)) {
+		if item == "mac" || item == "darwin" {
+			item = "macos"
+		}
+		if !slices.Contains(Platforms, item) {
+			return nil, fmt.Errorf("unknown platform %q: expected %s", item, strings.Join(Platforms, ", "))
+		}
+		if !slices.Contains(platforms, item) {
+			platforms = append(platforms, item)
+		}
+	}
+	if len(platforms) == 0 {
+		return nil, fmt.Errorf("select at least one platform")
+	}
+	return platforms, nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Build creates a validated config from fully decided options. Platform
+// sections that are not targeted are dropped; a tool with no recipe for any
+// targeted platform is an error.
+func Build(opts Options) (*config.Config, error) {
+	opts = opts.Defaults()
+	if len(opts.Tools) == 0 {
+		return nil, fmt.Errorf("no tools selected")
+	}
+
+	cfg := &config.Config{
+		Profile: opts.Profile,
+		Settings: config.Settings{
+			AutoUpdatePath:      *opts.UpdatePath,
+			VerifyInstallations: *opts.Verify,
+		},
+	}
+
+	for _, name := range opts.Tools {
+		tool, ok := catalog.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown tool %q", name)
+		}
+
+		if !slices.Contains(opts.Platforms, "linux") {
+			tool.Linux = nil
+		}
+		if !slices.Contains(opts.Platforms, "macos") {
+			tool.MacOS = nil
+		}
+		if !slices.Contains(opts.Platforms, "windows") {
+			tool.Windows = nil
+		}
+		if tool.Linux == nil && tool.MacOS == nil && tool.Windows == nil {
+			return nil, fmt.Errorf("tool %q is not available for %s", name, strings.Join(opts.Platforms, ", "))
+		}
+
+		cfg.Tools = append(cfg.Tools, tool)
+	}
+
+	if err := config.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("generated config is invalid: %w", err)
+	}
+	return cfg, nil
+}
+
+// Write renders a config as YAML with a schema comment for editors, and
+// checks that it loads back to a valid config
+func Write(w io.Writer, cfg *config.Config) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# yaml-language-server: $schema=%s\n", schema.ID)
+	fmt.Fprintln(&buf, "# Generated by stackup init")
+	fmt.Fprintln(&buf)
+	if err := config.Render(&buf, cfg); err != nil {
+		return err
+	}
+
+	loaded, err := config.LoadFromBytes(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated config does not load: %w", err)
+	}
+	if err := config.Validate(loaded); err != nil {
+		return fmt.Errorf("generated config is invalid: %w", err)
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
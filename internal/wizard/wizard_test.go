@@ -0,0 +1,123 @@
+package wizard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/araldhafeeri/stackup/internal/config"
+)
+
+func TestAsk(t *testing.T) {
+	var out bytes.Buffer
+	in := strings.NewReader("99\n3,jq,git\nlinux,mac\n\nn\nwork\n")
+
+	opts, err := Ask(in, &out, Options{})
+	if err != nil {
+		t.Fatalf("Ask() error: %v", err)
+	}
+
+	if got := strings.Join(opts.Tools, ","); got != "git,jq" {
+		t.Errorf("Tools = %s, want git,jq", got)
+	}
+	if got := strings.Join(opts.Platforms, ","); got != "linux,macos" {
+		t.Errorf("Platforms = %s, want linux,macos", got)
+	}
+	if !*opts.Verify || *opts.UpdatePath {
+		t.Errorf("Verify = %v, UpdatePath = %v, want true, false", *opts.Verify, *opts.UpdatePath)
+	}
+	if opts.Profile != "work" {
+		t.Errorf("Profile = %q, want work", opts.Profile)
+	}
+	if !strings.Contains(out.String(), "no tool numbered 99") {
+		t.Errorf("Expected the bad answer to be reported, got:\n%s", out.String())
+	}
+}
+
+func TestAskSkipsGivenOptions(t *testing.T) {
+	var out bytes.Buffer
+	verify := false
+	opts, err := Ask(strings.NewReader(""), &out, Options{Tools: []string{"git"}, Verify: &verify})
+	if err != nil {
+		t.Fatalf("Ask() error: %v", err)
+	}
+
+	if strings.Contains(out.String(), "Available tools") || strings.Contains(out.String(), "Verify") {
+		t.Errorf("Given options should not be asked for, got:\n%s", out.String())
+	}
+	if *opts.Verify || !*opts.UpdatePath || opts.Profile != "dev" || len(opts.Platforms) != 3 {
+		t.Errorf("Ended input should take the defaults, got %+v", opts)
+	}
+}
+
+func TestAskNoTools(t *testing.T) {
+	if _, err := Ask(strings.NewReader(""), &bytes.Buffer{}, Options{}); err == nil {
+		t.Error("Ask() without any tools should fail")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cfg, err := Build(Options{Tools: []string{"git", "docker"}, Platforms: []string{"linux"}})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	if cfg.Profile != "dev" || !cfg.Settings.VerifyInstallations || !cfg.Settings.AutoUpdatePath {
+		t.Errorf("Expected defaults, got %+v", cfg)
+	}
+	for _, tool := range cfg.Tools {
+		if tool.Linux == nil || tool.MacOS != nil || tool.Windows != nil {
+			t.Errorf("Tool %s should only target linux", tool.Name)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, cfg); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "# yaml-language-server: $schema=") {
+		t.Errorf("Missing schema comment:\n%s", buf.String())
+	}
+
+	loaded, err := config.LoadFromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Generated config does not load: %v", err)
+	}
+	if len(loaded.Tools) != 2 || loaded.Tools[1].Name != "docker" {
+		t.Errorf("Loaded tools = %+v", loaded.Tools)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{name: "no tools", opts: Options{}, want: "no tools selected"},
+		{name: "unknown tool", opts: Options{Tools: []string{"nope"}}, want: `unknown tool "nope"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Build(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Build() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePlatforms(t *testing.T) {
+	got, err := ParsePlatforms("Linux, darwin,linux")
+	if err != nil {
+		t.Fatalf("ParsePlatforms() error: %v", err)
+	}
+	if strings.Join(got, ",") != "linux,macos" {
+		t.Errorf("ParsePlatforms() = %v", got)
+	}
+
+	if _, err := ParsePlatforms("bsd"); err == nil {
+		t.Error("ParsePlatforms(bsd) should fail")
+	}
+}
@@ -2,12 +2,18 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
+	"github.com/araldhafeeri/stackup/internal/catalog"
 	"github.com/araldhafeeri/stackup/internal/config"
 	"github.com/araldhafeeri/stackup/internal/installer"
 	"github.com/araldhafeeri/stackup/internal/platform"
+	"github.com/araldhafeeri/stackup/internal/schema"
 	"github.com/araldhafeeri/stackup/internal/ui"
 	"github.com/araldhafeeri/stackup/pkg/version"
 )
@@ -27,26 +33,79 @@ func main() {
 	case "example":
 		fmt.Print(config.ExampleConfig)
 		os.Exit(0)
+	case "schema":
+		os.Stdout.Write(schema.JSON())
+		os.Exit(0)
 	case "install":
-		if err := runInstall(); err != nil {
+		if err := runInstall(os.Args[2:]); err != nil {
 			fmt.Fprintf(os.Stderr, "Installation failed: %v\n", err)
 			os.Exit(1)
 		}
+	case "validate":
+		if err := runValidate(os.Args[2:]); err != nil {
+			if !errors.Is(err, errValidationFailed) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			os.Exit(1)
+		}
+	case "init":
+		if err := runInit(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "import":
+		if err := runImport(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "export":
+		if err := runExport(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "catalog":
+		if err := runCatalog(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "config":
+		if err := runConfig(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		os.Exit(1)
 	}
 }
 
-func runInstall() error {
-	if len(os.Args) < 3 {
-		return fmt.Errorf("config file required\nUsage: stackup install <config.yaml>")
+func runInstall(args []string) error {
+	flags := flag.NewFlagSet("install", flag.ContinueOnError)
+	var files stringList
+	flags.Var(&files, "f", "config file to load; repeat to merge several files in order")
+	formatName := flags.String("format", "", "format of the config files: yaml, json or toml (default: by extension)")
+	pin := flags.String("sha256", "", "expected sha256 of the config file")
+	vars := varMap{}
+	flags.Var(vars, "var", "set a config variable as key=value; repeatable")
+	var yes bool
+	flags.BoolVar(&yes, "yes", false, "answer yes to package manager prompts, like settings.non_interactive")
+	flags.BoolVar(&yes, "y", false, "shorthand for --yes")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := config.ParseFormat(*formatName)
+	if err != nil {
+		return err
 	}
 
-	configPath := os.Args[2]
+	configPaths := append(files, flags.Args()...)
+	if len(configPaths) == 0 {
+		return fmt.Errorf("config file required\nUsage: stackup install <config.yaml> | - | <https://...> | -f <base.yaml> [-f <overlay.yaml>...]")
+	}
 
 	// Load configuration
-	cfg, err := config.LoadFromFile(configPath)
+	cfg, err := config.Load(remoteLoadOptions(format, *pin, vars), configPaths...)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
@@ -62,9 +121,28 @@ func runInstall() error {
 	// Create console UI
 	console := ui.NewConsole()
 
+	// Prompts cannot be answered without a terminal, so they would hang
+	if yes {
+		cfg.Settings.NonInteractive = true
+	} else if !cfg.Settings.NonInteractive && !stdinIsTerminal() {
+		cfg.Settings.NonInteractive = true
+		console.PrintWarning("", "stdin is not a terminal, so package managers run non-interactively as with --yes")
+	}
+
 	// Create and run installer
 	inst := installer.New(cfg, sys, console)
-	return inst.Run()
+
+	// Secret values are masked in everything printed from here on,
+	// including the output of installers and commands
+	if len(cfg.Secrets) > 0 {
+		restore, err := inst.Redactor().Capture()
+		if err != nil {
+			return fmt.Errorf("failed to capture output: %w", err)
+		}
+		defer restore()
+	}
+
+	return inst.Redactor().Err(inst.Run())
 }
 
 func printUsage() {
@@ -72,7 +150,77 @@ func printUsage() {
 	fmt.Println("Usage: stackup <command> [options]")
 	fmt.Println("\nCommands:")
 	fmt.Println("  install <config.yaml>    Install tools from config file")
+	fmt.Println("  install -f <a> -f <b>    Install from several config files merged in order")
+	fmt.Println("  install - | <url>        Install from stdin or an HTTP(S) URL (--sha256 to pin)")
+	fmt.Println("  install --yes <config>   Install without package manager prompts")
+	fmt.Println("  validate <config.yaml>   Check a config against the lint rules (--output text|sarif)")
+	fmt.Println("  init                     Create a config by picking tools from the catalog")
+	fmt.Println("  import <manifest>...     Convert a Brewfile, winget export, packages.config,")
+	fmt.Println("                           apt-mark list or .tool-versions into a config")
+	fmt.Println("  export --format <f> <c>  Write a config as a shell script, Dockerfile, Ansible")
+	fmt.Println("                           playbook or devcontainer.json for --os/--distro/--arch")
+	fmt.Println("  catalog list | show <n>  List the tool recipes configs can use, or print one")
+	fmt.Println("  config render -f <a>...  Print the fully merged configuration")
+	fmt.Println("  config convert --to <f>  Convert a config between YAML, JSON and TOML")
+	fmt.Println("  schema                   Print the JSON Schema of the config format")
 	fmt.Println("  version                  Show version")
 	fmt.Println("  example                  Show example config")
 	fmt.Println("\nFor more information, visit: https://github.com/araldhafeeri/stackup")
 }
+
+// stdinIsTerminal reports whether stdin can answer prompts. /dev/null is a
+// character device like a terminal, but never answers.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(info, null)
+}
+
+// remoteLoadOptions lets a command read configs from stdin ("-") and
+// HTTP(S) URLs, with tools using recipes from the catalog
+func remoteLoadOptions(format config.Format, sha256 string, vars map[string]string) config.LoadOptions {
+	return config.LoadOptions{
+		Format:  format,
+		Fetcher: config.NewFetcher(),
+		Stdin:   os.Stdin,
+		SHA256:  sha256,
+		Vars:    vars,
+		Recipes: catalog.Default(),
+	}
+}
+
+// stringList is a flag.Value that collects repeated flags
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// varMap is a flag.Value that collects repeated key=value flags
+type varMap map[string]string
+
+func (v varMap) String() string {
+	pairs := make([]string, 0, len(v))
+	for key, val := range v {
+		pairs = append(pairs, key+"="+val)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v varMap) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	v[key] = val
+	return nil
+}
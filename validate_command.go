@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/araldhafeeri/stackup/internal/catalog"
+	"github.com/araldhafeeri/stackup/internal/config"
+	"github.com/araldhafeeri/stackup/internal/lint"
+	"github.com/araldhafeeri/stackup/internal/schema"
+)
+
+// errValidationFailed is returned once findings have been printed, so main
+// exits non-zero without repeating them
+var errValidationFailed = errors.New("validation failed")
+
+// runValidate loads, validates and lints configuration files
+func runValidate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	var files stringList
+	flags.Var(&files, "f", "config file to load; repeat to merge several files in order")
+	formatName := flags.String("format", "", "format of the config files: yaml, json or toml (default: by extension)")
+	output := flags.String("output", "text", "report format: text or sarif")
+	vars := varMap{}
+	flags.Var(vars, "var", "set a config variable as key=value; repeatable")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := config.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	configPaths := append(files, flags.Args()...)
+	if len(configPaths) == 0 {
+		return fmt.Errorf("config file required\nUsage: stackup validate [--output text|sarif] <config.yaml> | -f <a> [-f <b>...]")
+	}
+
+	write := lint.WriteText
+	switch *output {
+	case "text":
+	case "sarif":
+		write = lint.WriteSARIF
+	default:
+		return fmt.Errorf("unknown output %q: expected text or sarif", *output)
+	}
+
+	findings := validateFiles(config.LoadOptions{Format: format, Vars: vars, Recipes: catalog.Default()}, configPaths)
+
+	if err := write(os.Stdout, findings); err != nil {
+		return err
+	}
+
+	if lint.HasErrors(findings) {
+		return errValidationFailed
+	}
+	return nil
+}
+
+// validateFiles loads the configuration and checks every file it was read
+// from against the schema. Semantic validation and lint rules only run on
+// configurations that match the schema. A forced format applies to the
+// given paths only; included files are read by extension.
+func validateFiles(opts config.LoadOptions, paths []string) []lint.Finding {
+	format := opts.Format
+	cfg, err := config.Load(opts, paths...)
+	if err != nil {
+		return lint.FromError(lint.ConfigRule, err)
+	}
+
+	forced := make(map[string]bool, len(paths))
+	if format != config.FormatAuto {
+		for _, path := range paths {
+			forced[path] = true
+		}
+	}
+
+	var findings []lint.Finding
+	for _, file := range cfg.Files() {
+		fileFormat := config.FormatFromPath(file)
+		if forced[file] {
+			fileFormat = format
+		}
+		if err := schema.ValidateFile(file, fileFormat); err != nil {
+			findings = append(findings, lint.FromError(lint.SchemaRule, err)...)
+		}
+	}
+	if len(findings) > 0 {
+		return findings
+	}
+
+	if err := config.Validate(cfg); err != nil {
+		findings = lint.FromError(lint.ConfigRule, err)
+	}
+	findings = append(findings, lint.Run(cfg)...)
+	lint.Sort(findings)
+	return findings
+}